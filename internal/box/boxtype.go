@@ -35,6 +35,14 @@ const (
 	SampleToChunkBox     BoxType = 0x73747363 //"stsc"
 	SampleSizeBox        BoxType = 0x7374737A //"stsz"
 	ChunkOffsetBox       BoxType = 0x7374636F //"stco"
+	ChunkLargeOffsetBox  BoxType = 0x636F3634 //"co64"
+	SampleDescriptionBox BoxType = 0x73747364 //"stsd"
+	TimeToSampleBox      BoxType = 0x73747473 //"stts"
+	MediaHeaderBox       BoxType = 0x6D646864 //"mdhd"
+	UserDataLocation     BoxType = 0xA978797A //"©xyz"
+	UserDataXMP          BoxType = 0x584D505F //"XMP_"
+	FreeSpaceBox         BoxType = 0x66726565 //"free"
+	SkipBox              BoxType = 0x736B6970 //"skip"
 )
 
 type UserType [16]byte
@@ -62,7 +70,7 @@ func (boxType BoxType) String() string {
 		s = strings.ReplaceAll(s, string([]byte{0xa9}), "(c)")
 		return s
 	}
-	return fmt.Sprintf("%#08X", boxType)
+	return fmt.Sprintf("%#08X", uint32(boxType))
 }
 
 func (boxType BoxType) getBoxDef() *BoxDef {
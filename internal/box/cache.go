@@ -0,0 +1,72 @@
+package box
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const DefaultCacheSize = 16
+
+// StructureCache caches file structures by path, entry is invalidated when the size or modification time of file changes.
+// Structures read in lazy mode are shared by callers, payloads decoded on demand are kept for the next caller
+type StructureCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*cacheEntry
+	// order paths in the order of reading, the oldest is evicted first
+	order []string
+}
+
+type cacheEntry struct {
+	size          int64
+	modTime       time.Time
+	opts          ReadOptions
+	fileStructure *FileStructure
+}
+
+func NewStructureCache(size int) *StructureCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &StructureCache{
+		size:    size,
+		entries: make(map[string]*cacheEntry, size),
+	}
+}
+
+// Read returns the cached structure of file or reads it with the options, structure with error is not cached
+func (c *StructureCache) Read(f *os.File, opts ReadOptions) (*FileStructure, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	key := f.Name()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) && entry.opts == opts {
+		return entry.fileStructure, nil
+	}
+
+	fileStructure, err := ReadFileStructureWithOptions(f, opts)
+	if err != nil {
+		return fileStructure, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		for len(c.order) >= c.size {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = &cacheEntry{
+		size:          info.Size(),
+		modTime:       info.ModTime(),
+		opts:          opts,
+		fileStructure: fileStructure,
+	}
+	return fileStructure, nil
+}
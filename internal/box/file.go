@@ -1,22 +1,90 @@
 package box
 
 import (
+	"errors"
+	"fmt"
 	"github.com/fukco/media-metadata/internal/manufacturer"
 	"io"
+	"sync"
+)
+
+// maxBoxDepth limits nesting of container boxes
+const maxBoxDepth = 32
+
+var (
+	ErrInvalidBoxSize = errors.New("invalid box size")
+	ErrBoxTooDeep     = errors.New("box nesting too deep")
 )
 
 type FileStructure struct {
 	BoxDetails []*BoxDetail
 	*Context
+	// Err error stopping the reading, boxes read before are kept, only set with ReadOptions.Recover
+	Err error
 }
 
 type BoxDetail struct {
 	*BoxInfo
 	Boxer
 	Children []*BoxDetail
+	// Err error of payload decoding, only kept with ReadOptions.KeepErrors
+	Err error
+	// lazy payload decoded on demand by Load, only set with ReadOptions.Lazy
+	lazy *lazyPayload
+	// Truncated top level box extends beyond the end of file or has an unfinalized size, only set with ReadOptions.Recover
+	Truncated bool
+}
+
+// ReadOptions options of reading file structure
+type ReadOptions struct {
+	// KeepUnsupported keeps boxes without definition, their Boxer is nil
+	KeepUnsupported bool
+	// KeepErrors keeps boxes whose payload can not be decoded and continues with the next box
+	KeepErrors bool
+	// Recover reads top level boxes of unfinalized files, a box with invalid size is treated as extending to the end of file
+	Recover bool
+	// Lazy decodes payloads of leaf boxes on demand by BoxDetail.Load instead of while reading,
+	// boxes like stsz of the video track of a long clip are never read. ftyp and keys are still decoded as they update the context
+	Lazy bool
+}
+
+type lazyPayload struct {
+	mu  sync.Mutex
+	ctx *Context
+}
+
+// Load returns the decoded payload, payload of box read in lazy mode is decoded on the first call
+func (d *BoxDetail) Load(r io.ReadSeeker) (Boxer, error) {
+	if d.lazy == nil {
+		return d.Boxer, nil
+	}
+	d.lazy.mu.Lock()
+	defer d.lazy.mu.Unlock()
+	if d.Boxer != nil {
+		return d.Boxer, nil
+	}
+	payload, err := ReadBoxPayload(r, d.BoxInfo, d.lazy.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("box %s at %d: %w", d.Type, d.Offset, err)
+	}
+	d.Boxer = payload
+	return payload, nil
+}
+
+func isContextBox(boxType BoxType) bool {
+	return boxType == FileTypeBox || boxType == MetadataItemKeysAtom
 }
 
 func ReadFileStructure(r io.ReadSeeker) (*FileStructure, error) {
+	fileStructure, err := ReadFileStructureWithOptions(r, ReadOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fileStructure, nil
+}
+
+// ReadFileStructureWithOptions reads box tree, boxes read before the error are returned with it
+func ReadFileStructureWithOptions(r io.ReadSeeker, opts ReadOptions) (*FileStructure, error) {
 	end, err := r.Seek(0, io.SeekEnd)
 	if err != nil {
 		return nil, err
@@ -27,15 +95,18 @@ func ReadFileStructure(r io.ReadSeeker) (*FileStructure, error) {
 	fileStructure := &FileStructure{
 		Context: &Context{},
 	}
-	details, err := readBoxDetails(r, fileStructure, uint64(end))
-	if err != nil {
-		return nil, err
-	}
+	details, err := readBoxDetails(r, fileStructure, uint64(end), &opts, 0)
 	fileStructure.BoxDetails = details
-	return fileStructure, nil
+	if opts.Recover {
+		fileStructure.Err = err
+	}
+	return fileStructure, err
 }
 
-func readBoxDetails(r io.ReadSeeker, fileStructure *FileStructure, end uint64) ([]*BoxDetail, error) {
+func readBoxDetails(r io.ReadSeeker, fileStructure *FileStructure, end uint64, opts *ReadOptions, depth int) ([]*BoxDetail, error) {
+	if depth > maxBoxDepth {
+		return nil, ErrBoxTooDeep
+	}
 	details := make([]*BoxDetail, 0, 8)
 
 	for {
@@ -48,13 +119,33 @@ func readBoxDetails(r io.ReadSeeker, fileStructure *FileStructure, end uint64) (
 			if err == io.EOF {
 				break
 			}
-			return nil, err
+			return details, err
 		}
+		truncated := false
+		if bi.Size < bi.HeaderSize {
+			if !opts.Recover || depth > 0 {
+				return details, fmt.Errorf("%w: box %s at %d size %d", ErrInvalidBoxSize, bi.Type, bi.Offset, bi.Size)
+			}
+			// like 64-bit size of mdat not written back when recording stopped unexpectedly
+			bi.Size = end - bi.Offset
+			truncated = true
+		}
+		if bi.Offset+bi.Size > end {
+			// top level box may extend beyond the end of a truncated file, child box must not exceed its parent
+			if depth > 0 {
+				return details, fmt.Errorf("%w: box %s at %d size %d exceeds parent end %d", ErrInvalidBoxSize, bi.Type, bi.Offset, bi.Size, end)
+			}
+			truncated = opts.Recover
+		}
+		boxDetail := &BoxDetail{BoxInfo: bi, Truncated: truncated}
 
 		if !bi.IsSupportedBox() {
+			if opts.KeepUnsupported {
+				details = append(details, boxDetail)
+			}
 			_, err = bi.SeekToEnd(r)
 			if err != nil {
-				return nil, err
+				return details, err
 			}
 			continue
 		}
@@ -63,27 +154,43 @@ func readBoxDetails(r io.ReadSeeker, fileStructure *FileStructure, end uint64) (
 			fileStructure.Mfr = bi.Type.GetManufacturer()
 		}
 
+		if opts.Lazy && !bi.IsContainerBox() && !isContextBox(bi.Type) {
+			// context of the box position, like the entry count of keys preceding ilst in the same meta
+			ctx := *fileStructure.Context
+			boxDetail.lazy = &lazyPayload{ctx: &ctx}
+			details = append(details, boxDetail)
+			if _, err = bi.SeekToEnd(r); err != nil {
+				return details, err
+			}
+			continue
+		}
+
 		payload, err := ReadBoxPayload(r, bi, fileStructure.Context)
 		if err != nil {
-			return nil, err
-		}
-		boxDetail := &BoxDetail{
-			BoxInfo: bi,
-			Boxer:   payload,
+			if !opts.KeepErrors {
+				return details, err
+			}
+			boxDetail.Err = err
+			details = append(details, boxDetail)
+			if _, err = bi.SeekToEnd(r); err != nil {
+				return details, err
+			}
+			continue
 		}
+		boxDetail.Boxer = payload
 
 		if bi.IsContainerBox() {
 			_, err = bi.SeekToPayload(r)
 			if err != nil {
-				return nil, err
-			}
-			children, err := readBoxDetails(r, fileStructure, bi.Offset+bi.Size)
-			if err != nil {
-				return nil, err
+				return details, err
 			}
+			children, err := readBoxDetails(r, fileStructure, bi.Offset+bi.Size, opts, depth+1)
 			if len(children) > 0 {
 				boxDetail.Children = children
 			}
+			if err != nil {
+				return append(details, boxDetail), err
+			}
 		}
 		details = append(details, boxDetail)
 
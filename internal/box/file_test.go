@@ -0,0 +1,51 @@
+package box
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testMeta(keys ...string) []byte {
+	entries := [][]byte{binary.BigEndian.AppendUint32(nil, uint32(len(keys)))}
+	items := make([][]byte, 0, len(keys))
+	for i, key := range keys {
+		entries = append(entries, testBox("mdta", []byte(key)))
+		index := string(binary.BigEndian.AppendUint32(nil, uint32(i+1)))
+		items = append(items, testBox(index, testBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(key))))
+	}
+	return testBox("meta",
+		testFullBox("hdlr", make([]byte, 4), []byte("mdta"), make([]byte, 13)),
+		testFullBox("keys", entries...),
+		testBox("ilst", items...))
+}
+
+// TestLazyIlstContext ilst is decoded with the keys of its own meta, not the last keys of file
+func TestLazyIlstContext(t *testing.T) {
+	data := testBox("moov", testMeta("a", "b", "c"), testBox("udta", testMeta("d")))
+	for _, opts := range []ReadOptions{{}, {Lazy: true}} {
+		r := bytes.NewReader(data)
+		fileStructure, err := ReadFileStructureWithOptions(r, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var counts []int
+		var walk func(details []*BoxDetail)
+		walk = func(details []*BoxDetail) {
+			for _, detail := range details {
+				if detail.Type == MetadataItemListAtom {
+					b, err := detail.Load(r)
+					if err != nil {
+						t.Fatal(err)
+					}
+					counts = append(counts, len(b.(*Ilst).Items))
+				}
+				walk(detail.Children)
+			}
+		}
+		walk(fileStructure.BoxDetails)
+		if len(counts) != 2 || counts[0] != 3 || counts[1] != 1 {
+			t.Fatalf("lazy %v: items of ilst %v, want [3 1]", opts.Lazy, counts)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package box
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testBox(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, boxType...), data...)
+}
+
+func testFullBox(boxType string, payload ...[]byte) []byte {
+	return testBox(boxType, append([][]byte{{0, 0, 0, 0}}, payload...)...)
+}
+
+func FuzzReadFileStructure(f *testing.F) {
+	ftyp := testBox("ftyp", []byte("XAVC\x00\x01\x00\x1fXAVCmp42"))
+	keys := testFullBox("keys", []byte{0, 0, 0, 1}, testBox("mdta", []byte("com.apple.quicktime.make")))
+	ilst := testBox("ilst", testBox("\x00\x00\x00\x01", testBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte("Apple"))))
+	meta := testBox("meta", testFullBox("hdlr", make([]byte, 4), []byte("mdta"), make([]byte, 13)), keys, ilst)
+	stbl := testBox("stbl",
+		testFullBox("stsc", []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1}),
+		testFullBox("stsz", []byte{0, 0, 0, 16, 0, 0, 0, 1}),
+		testFullBox("stco", []byte{0, 0, 0, 1, 0, 0, 0, 0}),
+		testFullBox("stts", []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1}))
+	trak := testBox("trak", testBox("mdia",
+		testFullBox("mdhd", make([]byte, 20)),
+		testFullBox("hdlr", make([]byte, 4), []byte("vide"), make([]byte, 13)),
+		testBox("minf", stbl)))
+	moov := testBox("moov", testFullBox("mvhd", make([]byte, 96)), trak, meta, testBox("udta", testBox("NCTG")))
+	f.Add(append(ftyp, moov...))
+	f.Add(append(append(ftyp, moov...), testBox("mdat", make([]byte, 16))...))
+	// truncated moov and mdat of 64-bit size not written back
+	f.Add(append(ftyp, moov[:len(moov)/2]...))
+	f.Add(append(ftyp, 0, 0, 0, 1, 'm', 'd', 'a', 't', 0, 0, 0, 0, 0, 0, 0, 0))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		options := []ReadOptions{
+			{},
+			{Lazy: true},
+			{KeepUnsupported: true, KeepErrors: true, Recover: true, Lazy: true},
+		}
+		for _, opts := range options {
+			r := bytes.NewReader(data)
+			fileStructure, _ := ReadFileStructureWithOptions(r, opts)
+			if fileStructure == nil {
+				continue
+			}
+			var load func(details []*BoxDetail)
+			load = func(details []*BoxDetail) {
+				for _, detail := range details {
+					_, _ = detail.Load(r)
+					load(detail.Children)
+				}
+			}
+			load(fileStructure.BoxDetails)
+		}
+	})
+}
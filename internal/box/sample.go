@@ -0,0 +1,137 @@
+package box
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrSampleTableNotFound = errors.New("sample table not found")
+
+// Sample location and decode time of a media sample
+type Sample struct {
+	Offset uint64
+	Size   uint32
+	// Time decode time in media timescale
+	Time uint64
+}
+
+// FindChild returns the first direct child with the specified type
+func (d *BoxDetail) FindChild(boxType BoxType) *BoxDetail {
+	for _, child := range d.Children {
+		if child.Type == boxType {
+			return child
+		}
+	}
+	return nil
+}
+
+// HandlerType returns handler type of mdia or meta box
+func (d *BoxDetail) HandlerType(r io.ReadSeeker) string {
+	if hdlrDetail := d.FindChild(HandlerReferenceBox); hdlrDetail != nil {
+		if b, _ := hdlrDetail.Load(r); b != nil {
+			if hdlr, ok := b.(*Hdlr); ok {
+				return string(hdlr.HandlerType[:])
+			}
+		}
+	}
+	return ""
+}
+
+// SampleTable returns stbl box of the mdia box
+func (d *BoxDetail) SampleTable() *BoxDetail {
+	if d.Type != MediaBox {
+		return nil
+	}
+	if minf := d.FindChild(MediaInformationBox); minf != nil {
+		return minf.FindChild(SampleTableBox)
+	}
+	return nil
+}
+
+// Timescale returns media timescale of the mdia box
+func (d *BoxDetail) Timescale(r io.ReadSeeker) uint32 {
+	if mdhdDetail := d.FindChild(MediaHeaderBox); mdhdDetail != nil {
+		if b, _ := mdhdDetail.Load(r); b != nil {
+			if mdhd, ok := b.(*Mdhd); ok {
+				return mdhd.Timescale
+			}
+		}
+	}
+	return 0
+}
+
+// Samples resolves offsets, sizes and decode times of all samples in the stbl box
+func Samples(r io.ReadSeeker, stbl *BoxDetail) ([]*Sample, error) {
+	if stbl == nil || stbl.Type != SampleTableBox {
+		return nil, ErrSampleTableNotFound
+	}
+	var stsc *Stsc
+	var stsz *Stsz
+	var stts *Stts
+	var chunkOffsets []uint64
+	for _, child := range stbl.Children {
+		switch child.Type {
+		case SampleToChunkBox, SampleSizeBox, TimeToSampleBox, ChunkOffsetBox, ChunkLargeOffsetBox:
+		default:
+			continue
+		}
+		boxer, err := child.Load(r)
+		if err != nil {
+			return nil, err
+		}
+		switch b := boxer.(type) {
+		case *Stsc:
+			stsc = b
+		case *Stsz:
+			stsz = b
+		case *Stts:
+			stts = b
+		case *Stco:
+			chunkOffsets = make([]uint64, len(b.Offsets))
+			for i, offset := range b.Offsets {
+				chunkOffsets[i] = uint64(offset)
+			}
+		case *Co64:
+			chunkOffsets = b.Offsets
+		}
+	}
+	if stsc == nil || stsz == nil || chunkOffsets == nil {
+		return nil, ErrSampleTableNotFound
+	}
+
+	samples := make([]*Sample, 0, stsz.Count)
+chunks:
+	for i, j := 0, 0; i < len(chunkOffsets) && uint32(len(samples)) < stsz.Count; i++ {
+		if j+1 < len(stsc.Entries) && uint32(i+1) >= stsc.Entries[j+1].FirstChunk {
+			j++
+		}
+		if j >= len(stsc.Entries) {
+			break
+		}
+		offset := chunkOffsets[i]
+		for k := uint32(0); k < stsc.Entries[j].SamplesPerChunk && uint32(len(samples)) < stsz.Count; k++ {
+			size := stsz.Size
+			if size == 0 {
+				if len(samples) >= len(stsz.EntrySizes) {
+					break chunks
+				}
+				size = stsz.EntrySizes[len(samples)]
+			}
+			samples = append(samples, &Sample{Offset: offset, Size: size})
+			offset += uint64(size)
+		}
+	}
+
+	if stts != nil {
+		var t uint64
+		n := 0
+		for _, entry := range stts.Entries {
+			for k := uint32(0); k < entry.SampleCount && n < len(samples); k++ {
+				samples[n].Time = t
+				t += uint64(entry.SampleDelta)
+				n++
+			}
+		}
+	}
+	return samples, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/fukco/media-metadata/internal/common"
 	"github.com/fukco/media-metadata/internal/exif"
 	"github.com/fukco/media-metadata/internal/manufacturer"
+	"math"
 	"time"
 )
 
@@ -165,6 +166,39 @@ func init() {
 	AddBoxDef(&Mdia{}, true, IsBox)
 }
 
+/************************** mdhd **************************/
+type Mdhd struct {
+	BoxBase
+	CreationTimeV0     uint32 `mp4:"size=32,ver=0"`
+	ModificationTimeV0 uint32 `mp4:"size=32,ver=0"`
+	CreationTimeV1     uint64 `mp4:"size=64,ver=1"`
+	ModificationTimeV1 uint64 `mp4:"size=64,ver=1"`
+	Timescale          uint32 `mp4:"size=32"`
+	DurationV0         uint32 `mp4:"size=32,ver=0"`
+	DurationV1         uint64 `mp4:"size=64,ver=1"`
+	Language           uint16 `mp4:"size=16"` // pad(1) + ISO-639-2/T language code(15)
+	PreDefined         uint16 `mp4:"size=16"`
+}
+
+func (m *Mdhd) BoxType() BoxType {
+	return MediaHeaderBox
+}
+
+func init() {
+	AddBoxDef(&Mdhd{}, false, IsFullBox, 0, 1)
+}
+
+func (m *Mdhd) GetDuration(version uint8) uint64 {
+	switch version {
+	case 0:
+		return uint64(m.DurationV0)
+	case 1:
+		return m.DurationV1
+	default:
+		return 0
+	}
+}
+
 /*************************** hdlr ****************************/
 type Hdlr struct {
 	BoxBase
@@ -238,6 +272,58 @@ func init() {
 	AddBoxDef(&Stsc{}, false, IsFullBox)
 }
 
+/************************** stsd **************************/
+type Stsd struct {
+	BoxBase
+	EntryCount uint32 `mp4:"size=32"`
+	Entries    []byte `mp4:"size=8"` // sample entries, decoded by handler specific readers
+}
+
+func (s *Stsd) BoxType() BoxType {
+	return SampleDescriptionBox
+}
+
+func init() {
+	AddBoxDef(&Stsd{}, false, IsFullBox, 0)
+}
+
+// DataFormat returns the format of the first sample entry, e.g. "mebx" or "avc1"
+func (s *Stsd) DataFormat() string {
+	if s.EntryCount == 0 || len(s.Entries) < 8 {
+		return ""
+	}
+	return string(s.Entries[4:8])
+}
+
+/************************** stts **************************/
+type Stts struct {
+	BoxBase
+	EntryCount uint32      `mp4:"size=32"`
+	Entries    []SttsEntry `mp4:"len=dynamic,size=64"`
+}
+
+type SttsEntry struct {
+	SampleCount uint32 `mp4:"size=32"`
+	SampleDelta uint32 `mp4:"size=32"`
+}
+
+func (s *Stts) BoxType() BoxType {
+	return TimeToSampleBox
+}
+
+// GetFieldLength returns length of dynamic field
+func (s *Stts) GetFieldLength(name string, ctx *Context) uint {
+	switch name {
+	case "Entries":
+		return uint(s.EntryCount)
+	}
+	panic(fmt.Errorf("invalid name of dynamic-length field: boxType=stts fieldName=%s", name))
+}
+
+func init() {
+	AddBoxDef(&Stts{}, false, IsFullBox)
+}
+
 /************************** stsz **************************/
 type Stsz struct {
 	BoxBase
@@ -291,6 +377,30 @@ func init() {
 	AddBoxDef(&Stco{}, false, IsFullBox)
 }
 
+/************************** co64 **************************/
+type Co64 struct {
+	BoxBase
+	Count   uint32   `mp4:"size=32"`
+	Offsets []uint64 `mp4:"size=64,len=dynamic"`
+}
+
+func (c *Co64) GetFieldLength(name string, ctx *Context) uint {
+	switch name {
+	case "Offsets":
+		return uint(c.Count)
+	default:
+		return 0
+	}
+}
+
+func (c *Co64) BoxType() BoxType {
+	return ChunkLargeOffsetBox
+}
+
+func init() {
+	AddBoxDef(&Co64{}, false, IsFullBox)
+}
+
 /************************** keys **************************/
 type Keys struct {
 	BoxBase
@@ -351,13 +461,98 @@ type MetadataDataAtom struct {
 	Data     []byte  `mp4:"size=8,len=dynamic"`
 }
 
+// well-known data types, Reference: QuickTime File Format Specification, Well-Known Types
 const (
 	DataTypeReversed        = 0
 	DataTypeStringUTF8      = 1
 	DataTypeStringUTF16     = 2
+	DataTypeIntBigEndian    = 21
+	DataTypeUintBigEndian   = 22
+	DataTypeFloat32         = 23
+	DataTypeFloat64         = 24
+	DataTypeInt8            = 65
+	DataTypeInt16BigEndian  = 66
+	DataTypeInt32BigEndian  = 67
+	DataTypeInt64BigEndian  = 74
+	DataTypeUint8           = 75
+	DataTypeUint16BigEndian = 76
 	DataTypeUint32BigEndian = 77
+	DataTypeUint64BigEndian = 78
 )
 
+// DecodeWellKnownType converts raw data of a well-known type to go value
+func DecodeWellKnownType(dataType uint32, data []byte) (any, error) {
+	switch dataType {
+	case DataTypeStringUTF8:
+		return string(data), nil
+	case DataTypeIntBigEndian:
+		switch len(data) {
+		case 1:
+			return int64(int8(data[0])), nil
+		case 2:
+			return int64(int16(binary.BigEndian.Uint16(data))), nil
+		case 4:
+			return int64(int32(binary.BigEndian.Uint32(data))), nil
+		case 8:
+			return int64(binary.BigEndian.Uint64(data)), nil
+		}
+	case DataTypeUintBigEndian:
+		switch len(data) {
+		case 1:
+			return uint64(data[0]), nil
+		case 2:
+			return uint64(binary.BigEndian.Uint16(data)), nil
+		case 4:
+			return uint64(binary.BigEndian.Uint32(data)), nil
+		case 8:
+			return binary.BigEndian.Uint64(data), nil
+		}
+	case DataTypeFloat32:
+		if len(data) == 4 {
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+		}
+	case DataTypeFloat64:
+		if len(data) == 8 {
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+		}
+	case DataTypeInt8:
+		if len(data) == 1 {
+			return int64(int8(data[0])), nil
+		}
+	case DataTypeInt16BigEndian:
+		if len(data) == 2 {
+			return int64(int16(binary.BigEndian.Uint16(data))), nil
+		}
+	case DataTypeInt32BigEndian:
+		if len(data) == 4 {
+			return int64(int32(binary.BigEndian.Uint32(data))), nil
+		}
+	case DataTypeInt64BigEndian:
+		if len(data) == 8 {
+			return int64(binary.BigEndian.Uint64(data)), nil
+		}
+	case DataTypeUint8:
+		if len(data) == 1 {
+			return uint64(data[0]), nil
+		}
+	case DataTypeUint16BigEndian:
+		if len(data) == 2 {
+			return uint64(binary.BigEndian.Uint16(data)), nil
+		}
+	case DataTypeUint32BigEndian:
+		if len(data) == 4 {
+			return binary.BigEndian.Uint32(data), nil
+		}
+	case DataTypeUint64BigEndian:
+		if len(data) == 8 {
+			return binary.BigEndian.Uint64(data), nil
+		}
+	default:
+		return nil, fmt.Errorf("not supported type: %d", dataType)
+	}
+	return nil, fmt.Errorf("invalid data length %d for type: %d", len(data), dataType)
+}
+
 func (m *MetadataDataAtom) GetFieldLength(name string, ctx *Context) uint {
 	switch name {
 	case "Data":
@@ -368,12 +563,7 @@ func (m *MetadataDataAtom) GetFieldLength(name string, ctx *Context) uint {
 }
 
 func (m *MetadataDataAtom) Value() (any, error) {
-	if m.DataType == DataTypeStringUTF8 {
-		return string(m.Data), nil
-	} else if m.DataType == DataTypeUint32BigEndian {
-		return binary.BigEndian.Uint32(m.Data), nil
-	}
-	return nil, fmt.Errorf("not supported type: %d", m.DataType)
+	return DecodeWellKnownType(m.DataType, m.Data)
 }
 
 func (i *Ilst) BoxType() BoxType {
@@ -420,6 +610,44 @@ func init() {
 	AddBoxDef(&Udta{}, true, IsBox)
 }
 
+/************************** ©xyz **************************/
+type Xyz struct {
+	BoxBase
+	Length   uint16 `mp4:"size=16"`
+	Language uint16 `mp4:"size=16"`
+	Value    []byte `mp4:"size=8"`
+}
+
+func (x *Xyz) BoxType() BoxType {
+	return UserDataLocation
+}
+
+func init() {
+	AddBoxDef(&Xyz{}, false, IsBox)
+}
+
+// ISO6709 returns the location string, e.g. "+31.2304+121.4737/"
+func (x *Xyz) ISO6709() string {
+	if int(x.Length) < len(x.Value) {
+		return string(x.Value[:x.Length])
+	}
+	return string(x.Value)
+}
+
+/************************** XMP_ **************************/
+type XMP struct {
+	BoxBase
+	Data []byte `mp4:"size=8"`
+}
+
+func (x *XMP) BoxType() BoxType {
+	return UserDataXMP
+}
+
+func init() {
+	AddBoxDef(&XMP{}, false, IsBox)
+}
+
 /************************** PANA **************************/
 type PANA struct {
 	BoxBase
@@ -494,6 +722,9 @@ func init() {
 func TypeUUIDProf() UserType {
 	return [16]byte{0x50, 0x52, 0x4F, 0x46, 0x21, 0xD2, 0x4F, 0xCE, 0xBB, 0x88, 0x69, 0x5C, 0xFA, 0xC9, 0xC7, 0x40}
 }
+func TypeUUIDXMP() UserType {
+	return [16]byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}
+}
 func TypeUUIDCanon() UserType {
 	return [16]byte{0x85, 0xC0, 0xB6, 0x87, 0x82, 0x0F, 0x11, 0xE0, 0x81, 0x11, 0xF4, 0xCE, 0x46, 0x2B, 0x6A, 0x48}
 }
@@ -541,6 +772,23 @@ func init() {
 	AddUUIDBoxDef(&UUIDProf{}, TypeUUIDProf(), false, IsFullBox)
 }
 
+type UUIDXMP struct {
+	BoxBase
+	Data []byte `mp4:"size=8"`
+}
+
+func (u *UUIDXMP) BoxType() BoxType {
+	return UUIDExtensionBox
+}
+
+func (u *UUIDXMP) UserType() UserType {
+	return TypeUUIDXMP()
+}
+
+func init() {
+	AddUUIDBoxDef(&UUIDXMP{}, TypeUUIDXMP(), false, IsBox)
+}
+
 type UUIDCanon struct {
 	BoxBase
 }
@@ -170,6 +170,9 @@ func (u *unmarshaller) unmarshalSlice(v reflect.Value, f *field, ctx *Context) e
 	length := uint64(f.length)
 	if f.length == LengthUnlimited {
 		if f.size != 0 {
+			if u.index > u.size {
+				return errors.New("invalid alignment")
+			}
 			left := (u.size - u.index) * 8
 			if left%uint64(f.size) != 0 {
 				return errors.New("invalid alignment")
@@ -183,7 +186,7 @@ func (u *unmarshaller) unmarshalSlice(v reflect.Value, f *field, ctx *Context) e
 	if elemType.Kind() == reflect.Uint8 && f.size == 8 {
 		totalSize := length * uint64(f.size) / 8
 
-		if !readerHasSize(u.reader, totalSize) {
+		if u.index+totalSize > u.size || !readerHasSize(u.reader, totalSize) {
 			return fmt.Errorf("not enough bits")
 		}
 
@@ -0,0 +1,331 @@
+package box
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+var (
+	// ErrChunkOffsetOverflow shifted offset is negative or does not fit the entry, a stco offset beyond 32 bits requires co64
+	ErrChunkOffsetOverflow = errors.New("shifted chunk offset out of range")
+	// ErrChunkOffsetTruncated entry count of stco or co64 exceeds its payload
+	ErrChunkOffsetTruncated = errors.New("chunk offset table truncated")
+)
+
+// sampleTablePath containers leading to the chunk offsets, they must be parsed so the offsets can be shifted
+var sampleTablePath = map[BoxType]bool{
+	MovieBox:            true,
+	TrackBox:            true,
+	MediaBox:            true,
+	MediaInformationBox: true,
+	SampleTableBox:      true,
+}
+
+// Node raw box which can be edited and serialized back,
+// children are only parsed for supported container boxes, payload of other boxes is kept as it is
+type Node struct {
+	Type     BoxType
+	UserType UserType
+	// FullBoxHeader version and flags of full box, nil for box
+	*FullBoxHeader
+	// LargeSize box uses 64-bit extended size
+	LargeSize bool
+	Payload   []byte
+	Children  []*Node
+	// Tail bytes following the last child of container, like the 32-bit zero terminator of QuickTime udta
+	Tail []byte
+}
+
+// NewNode creates a leaf box
+func NewNode(boxType BoxType, payload []byte) *Node {
+	return &Node{Type: boxType, Payload: payload}
+}
+
+// NewFullNode creates a leaf full box with version and flags of zero
+func NewFullNode(boxType BoxType, payload []byte) *Node {
+	return &Node{Type: boxType, FullBoxHeader: &FullBoxHeader{}, Payload: payload}
+}
+
+// ReadNode reads the box at the current position of reader including all descendants
+func ReadNode(r io.ReadSeeker) (*Node, error) {
+	return readNode(r, 0)
+}
+
+func readNode(r io.ReadSeeker, depth int) (*Node, error) {
+	if depth > maxBoxDepth {
+		return nil, ErrBoxTooDeep
+	}
+	bi, err := ReadBoxInfo(r)
+	if err != nil {
+		return nil, err
+	}
+	if bi.Size < bi.HeaderSize {
+		return nil, fmt.Errorf("%w: box %s at %d size %d", ErrInvalidBoxSize, bi.Type, bi.Offset, bi.Size)
+	}
+	node := &Node{
+		Type:          bi.Type,
+		UserType:      bi.UserType,
+		FullBoxHeader: bi.FullBoxHeader,
+		LargeSize:     bi.HeaderSize-headerExtraSize(bi) > 8,
+	}
+	if _, err := bi.SeekToPayload(r); err != nil {
+		return nil, err
+	}
+	if !readerHasSize(r, bi.Size-bi.HeaderSize) {
+		return nil, fmt.Errorf("%w: box %s at %d size %d exceeds the data", ErrInvalidBoxSize, bi.Type, bi.Offset, bi.Size)
+	}
+	payload := make([]byte, bi.Size-bi.HeaderSize)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if bi.IsContainerBox() {
+		children, tail, err := readChildNodes(payload, depth+1)
+		if err == nil {
+			node.Children, node.Tail = children, tail
+			return node, nil
+		}
+		// other containers are kept as raw payload, but stco and co64 would be missed if these were
+		if sampleTablePath[bi.Type] {
+			return nil, fmt.Errorf("box %s at %d: %w", bi.Type, bi.Offset, err)
+		}
+	}
+	node.Payload = payload
+	return node, nil
+}
+
+func headerExtraSize(bi *BoxInfo) uint64 {
+	var size uint64
+	if bi.Type == UUIDExtensionBox {
+		size += 16
+	}
+	if bi.FullBoxHeader != nil {
+		size += 4
+	}
+	return size
+}
+
+func readChildNodes(payload []byte, depth int) ([]*Node, []byte, error) {
+	r := bytes.NewReader(payload)
+	children := make([]*Node, 0, 8)
+	for {
+		offset, _ := r.Seek(0, io.SeekCurrent)
+		if len(payload)-int(offset) < 8 {
+			return children, payload[offset:], nil
+		}
+		// size field of zero means extending to the end of file, which is not allowed for children
+		if binary.BigEndian.Uint32(payload[offset:offset+4]) == 0 {
+			return children, payload[offset:], nil
+		}
+		child, err := readNode(r, depth)
+		if err != nil {
+			return nil, nil, err
+		}
+		children = append(children, child)
+	}
+}
+
+// ParseChildren parses payload of the leaf node as child boxes, like items of ilst
+func (n *Node) ParseChildren() error {
+	if n.Children != nil {
+		return nil
+	}
+	children, tail, err := readChildNodes(n.Payload, 0)
+	if err != nil {
+		return err
+	}
+	n.Children, n.Tail, n.Payload = children, tail, nil
+	return nil
+}
+
+// FindChild returns the first direct child with the specified type
+func (n *Node) FindChild(boxType BoxType) *Node {
+	for _, child := range n.Children {
+		if child.Type == boxType {
+			return child
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for the node and all descendants
+func (n *Node) Walk(fn func(node *Node) error) error {
+	if err := fn(n); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the serialized size of the box
+func (n *Node) Size() uint64 {
+	size := n.payloadSize() + n.headerSize(false)
+	if size > math.MaxUint32 {
+		return n.payloadSize() + n.headerSize(true)
+	}
+	return size
+}
+
+func (n *Node) headerSize(large bool) uint64 {
+	size := uint64(8)
+	if large || n.LargeSize {
+		size += 8
+	}
+	if n.Type == UUIDExtensionBox {
+		size += 16
+	}
+	if n.FullBoxHeader != nil {
+		size += 4
+	}
+	return size
+}
+
+func (n *Node) payloadSize() uint64 {
+	if n.Children == nil {
+		return uint64(len(n.Payload))
+	}
+	size := uint64(len(n.Tail))
+	for _, child := range n.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// WriteTo serializes the box, sizes of the box and all descendants are recalculated
+func (n *Node) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, n.Size()))
+	n.encode(buf)
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the serialized box
+func (n *Node) Bytes() []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, n.Size()))
+	n.encode(buf)
+	return buf.Bytes()
+}
+
+func (n *Node) encode(buf *bytes.Buffer) {
+	size := n.Size()
+	b := make([]byte, 8)
+	if size > math.MaxUint32 || n.LargeSize {
+		binary.BigEndian.PutUint32(b, 1)
+		binary.BigEndian.PutUint32(b[4:], uint32(n.Type))
+		buf.Write(b)
+		binary.BigEndian.PutUint64(b, size)
+		buf.Write(b)
+	} else {
+		binary.BigEndian.PutUint32(b, uint32(size))
+		binary.BigEndian.PutUint32(b[4:], uint32(n.Type))
+		buf.Write(b)
+	}
+	if n.Type == UUIDExtensionBox {
+		buf.Write(n.UserType[:])
+	}
+	if n.FullBoxHeader != nil {
+		buf.WriteByte(n.Version)
+		buf.Write(n.Flags[:])
+	}
+	if n.Children == nil {
+		buf.Write(n.Payload)
+		return
+	}
+	for _, child := range n.Children {
+		child.encode(buf)
+	}
+	buf.Write(n.Tail)
+}
+
+// ShiftChunkOffsets adds delta to all stco and co64 offsets not less than the start position,
+// returns the number of shifted offsets. All tables are checked first, nothing is changed on error.
+func (n *Node) ShiftChunkOffsets(start uint64, delta int64) (int, error) {
+	tables := make([]*Node, 0, 4)
+	err := n.Walk(func(node *Node) error {
+		size := chunkOffsetSize(node.Type)
+		if size == 0 {
+			return nil
+		}
+		if len(node.Payload) < 4 {
+			return fmt.Errorf("%w: %s of %d bytes", ErrChunkOffsetTruncated, node.Type, len(node.Payload))
+		}
+		count := uint64(binary.BigEndian.Uint32(node.Payload))
+		if 4+count*size > uint64(len(node.Payload)) {
+			return fmt.Errorf("%w: %s of %d entries in %d bytes", ErrChunkOffsetTruncated, node.Type, count, len(node.Payload))
+		}
+		tables = append(tables, node)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := shiftChunkOffsets(tables, start, delta, false); err != nil {
+		return 0, err
+	}
+	return shiftChunkOffsets(tables, start, delta, true)
+}
+
+// shiftChunkOffsets checks the shifted offsets of the tables, they are written only if apply is true
+func shiftChunkOffsets(tables []*Node, start uint64, delta int64, apply bool) (int, error) {
+	shifted := 0
+	for _, node := range tables {
+		size := int(chunkOffsetSize(node.Type))
+		count := int(binary.BigEndian.Uint32(node.Payload))
+		for i := 0; i < count; i++ {
+			p := node.Payload[4+i*size : 4+(i+1)*size]
+			var offset, limit uint64
+			if size == 4 {
+				offset, limit = uint64(binary.BigEndian.Uint32(p)), math.MaxUint32
+			} else {
+				offset, limit = binary.BigEndian.Uint64(p), math.MaxInt64
+			}
+			if offset < start {
+				continue
+			}
+			value, ok := shiftOffset(offset, delta, limit)
+			if !ok {
+				return 0, fmt.Errorf("%w: %s offset %d shifted by %d", ErrChunkOffsetOverflow, node.Type, offset, delta)
+			}
+			if apply && size == 4 {
+				binary.BigEndian.PutUint32(p, uint32(value))
+			} else if apply {
+				binary.BigEndian.PutUint64(p, value)
+			}
+			shifted++
+		}
+	}
+	return shifted, nil
+}
+
+// chunkOffsetSize entry size of chunk offset box, 0 for other boxes
+func chunkOffsetSize(boxType BoxType) uint64 {
+	switch boxType {
+	case ChunkOffsetBox:
+		return 4
+	case ChunkLargeOffsetBox:
+		return 8
+	}
+	return 0
+}
+
+func shiftOffset(offset uint64, delta int64, limit uint64) (uint64, bool) {
+	if offset > limit {
+		return 0, false
+	}
+	if delta < 0 {
+		if offset < uint64(-delta) {
+			return 0, false
+		}
+		return offset - uint64(-delta), true
+	}
+	if uint64(delta) > limit || offset > limit-uint64(delta) {
+		return 0, false
+	}
+	return offset + uint64(delta), true
+}
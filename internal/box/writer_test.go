@@ -0,0 +1,31 @@
+package box
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestReadNodeBrokenChildren containers on the way to chunk offsets must parse, other containers fall back to raw payload
+func TestReadNodeBrokenChildren(t *testing.T) {
+	// child claims 64 bytes in a payload of 12
+	broken := append(testBox("free"), 0, 0, 0, 64, 'f', 'r', 'e', 'e')
+	stco := testFullBox("stco", []byte{0, 0, 0, 0})
+
+	udta := testBox("moov", testBox("udta", broken), testBox("trak", testBox("mdia", testBox("minf", testBox("stbl", stco)))))
+	moov, err := ReadNode(bytes.NewReader(udta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moov.Children[0].Children != nil || len(moov.Children[0].Payload) != len(broken) {
+		t.Errorf("udta with broken children is not kept as payload")
+	}
+	if !bytes.Equal(moov.Bytes(), udta) {
+		t.Errorf("moov is not written back as read")
+	}
+
+	stbl := testBox("moov", testBox("trak", testBox("mdia", testBox("minf", testBox("stbl", stco, broken)))))
+	if _, err := ReadNode(bytes.NewReader(stbl)); !errors.Is(err, ErrInvalidBoxSize) {
+		t.Fatalf("got %v, want ErrInvalidBoxSize", err)
+	}
+}
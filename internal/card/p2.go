@@ -0,0 +1,174 @@
+package card
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const p2Contents = "CONTENTS"
+
+// P2Clip files of a clip on P2 card, paths are empty if not found. A shot recorded across cards is the clip of its
+// first card with the clips of other cards in Spans
+type P2Clip struct {
+	// Name clip name like 0001AB
+	Name string
+	// XML clip XML of CLIP folder
+	XML string `json:",omitempty"`
+	// Video MXF of VIDEO folder
+	Video string `json:",omitempty"`
+	// Audio MXF of each channel of AUDIO folder
+	Audio []string `json:",omitempty"`
+	Proxy string   `json:",omitempty"`
+	Icon  string   `json:",omitempty"`
+	// GlobalShotID shared by clips of a shot recorded across cards
+	GlobalShotID string `json:",omitempty"`
+	// Spans clips of the shot on the following cards in recording order
+	Spans []*P2Clip `json:",omitempty"`
+	// MissingSpans is true if clips of the shot are on cards not read
+	MissingSpans bool `json:",omitempty"`
+	// ClipMain parsed clip XML, nil if there is no XML
+	ClipMain *panasonic.ClipMain `json:"-"`
+}
+
+// ReadP2Cards reads clips of P2 cards, root is the card or its CONTENTS folder. Clips of a shot recorded across
+// the cards are joined, cards may be passed in any order
+func ReadP2Cards(roots ...string) ([]*P2Clip, error) {
+	var clips []*P2Clip
+	for _, root := range roots {
+		cardClips, err := readP2Card(root)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, cardClips...)
+	}
+	return joinP2Spans(clips), nil
+}
+
+func readP2Card(root string) ([]*P2Clip, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Base(root), p2Contents) {
+		contents := findDir(root, p2Contents)
+		if contents == "" {
+			return nil, fmt.Errorf("%s not found in %s", p2Contents, root)
+		}
+		root = contents
+	}
+	// clips are listed by XML, and by MXF if XML is lost
+	names := make(map[string]bool)
+	var order []string
+	for _, folder := range []struct{ name, ext string }{{"CLIP", ".XML"}, {"VIDEO", ".MXF"}} {
+		entries, err := os.ReadDir(findDir(root, folder.name))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.EqualFold(filepath.Ext(name), folder.ext) {
+				continue
+			}
+			name = strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+			if !names[name] {
+				names[name] = true
+				order = append(order, name)
+			}
+		}
+	}
+	sort.Strings(order)
+	clips := make([]*P2Clip, 0, len(order))
+	for _, name := range order {
+		clip, err := newP2Clip(root, name)
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	return clips, nil
+}
+
+func newP2Clip(contents, name string) (*P2Clip, error) {
+	clip := &P2Clip{
+		Name:  name,
+		XML:   findFile(findDir(contents, "CLIP"), name+".XML"),
+		Video: findFile(findDir(contents, "VIDEO"), name+".MXF"),
+		Proxy: findFile(findDir(contents, "PROXY"), name+".MP4"),
+		Icon:  findFile(findDir(contents, "ICON"), name+".BMP"),
+	}
+	// audio of channel N is named like 0001AB0N.MXF
+	audio := findDir(contents, "AUDIO")
+	if entries, err := os.ReadDir(audio); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasPrefix(strings.ToUpper(entry.Name()), name) && strings.EqualFold(filepath.Ext(entry.Name()), ".MXF") {
+				clip.Audio = append(clip.Audio, filepath.Join(audio, entry.Name()))
+			}
+		}
+	}
+	if clip.XML == "" {
+		return clip, nil
+	}
+	f, err := os.Open(clip.XML)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	clip.ClipMain, err = panasonic.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("clip %s: %w", clip.XML, err)
+	}
+	clip.GlobalShotID = clip.ClipMain.ClipContent.Relation.GlobalShotID
+	return clip, nil
+}
+
+// joinP2Spans moves clips of a shot into Spans of its first clip, ordered by offset in shot
+func joinP2Spans(clips []*P2Clip) []*P2Clip {
+	shots := make(map[string][]*P2Clip)
+	for _, clip := range clips {
+		if clip.GlobalShotID != "" {
+			shots[clip.GlobalShotID] = append(shots[clip.GlobalShotID], clip)
+		}
+	}
+	result := make([]*P2Clip, 0, len(clips))
+	for _, clip := range clips {
+		if clip.GlobalShotID == "" {
+			result = append(result, clip)
+			continue
+		}
+		spans := shots[clip.GlobalShotID]
+		if spans == nil {
+			continue
+		}
+		delete(shots, clip.GlobalShotID)
+		sort.SliceStable(spans, func(i, j int) bool {
+			return spans[i].ClipMain.ClipContent.Relation.OffsetInShot < spans[j].ClipMain.ClipContent.Relation.OffsetInShot
+		})
+		first, last := spans[0], spans[len(spans)-1]
+		first.Spans = spans[1:]
+		if len(first.Spans) == 0 {
+			first.Spans = nil
+		}
+		first.MissingSpans = first.ClipMain.ClipContent.Relation.Connection.Previous.GlobalClipID != "" ||
+			last.ClipMain.ClipContent.Relation.Connection.Next.GlobalClipID != ""
+		result = append(result, first)
+	}
+	return result
+}
+
+// findDir finds folder of name in dir case-insensitively
+func findDir(dir, name string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package card
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// p2XML clip XML of a clip of shot at offset, previous and next are global clip IDs of connected clips
+func p2XML(clipID, shotID string, offset int, previous, next string) string {
+	connection := ""
+	if previous != "" {
+		connection += "<Previous><GlobalClipID>" + previous + "</GlobalClipID></Previous>"
+	}
+	if next != "" {
+		connection += "<Next><GlobalClipID>" + next + "</GlobalClipID></Next>"
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<P2Main xmlns="urn:schemas-Professional-Plug-in:P2:ClipMetadata:v3.1"><ClipContent>
+<GlobalClipID>` + clipID + `</GlobalClipID>
+<Relation><OffsetInShot>` + strconv.Itoa(offset) + `</OffsetInShot><GlobalShotID>` + shotID + `</GlobalShotID><Connection>` + connection + `</Connection></Relation>
+</ClipContent></P2Main>`
+}
+
+// writeP2Cards writes a shot spanned across three cards and a clip recorded on the first card
+func writeP2Cards(t *testing.T) string {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"CARD1/CONTENTS/CLIP/0001AB.XML":    p2XML("C1", "S1", 0, "", "C2"),
+		"CARD1/CONTENTS/VIDEO/0001AB.MXF":   "",
+		"CARD1/CONTENTS/AUDIO/0001AB00.MXF": "",
+		"CARD1/CONTENTS/AUDIO/0001AB01.MXF": "",
+		"CARD1/CONTENTS/PROXY/0001AB.MP4":   "",
+		"CARD1/CONTENTS/ICON/0001AB.BMP":    "",
+		"CARD1/CONTENTS/CLIP/0002AB.XML":    p2XML("C4", "S2", 0, "", ""),
+		"CARD1/CONTENTS/VIDEO/0002AB.MXF":   "",
+		"CARD2/Contents/Clip/0001CD.xml":    p2XML("C2", "S1", 9000, "C1", "C3"),
+		"CARD2/Contents/Video/0001CD.mxf":   "",
+		"CARD3/CONTENTS/CLIP/0001EF.XML":    p2XML("C3", "S1", 18000, "C2", ""),
+		"CARD3/CONTENTS/VIDEO/0001EF.MXF":   "",
+		// clip XML is lost
+		"CARD3/CONTENTS/VIDEO/0002EF.MXF": "",
+	})
+	return dir
+}
+
+func TestReadP2Cards(t *testing.T) {
+	dir := writeP2Cards(t)
+	card1, card2, card3 := filepath.Join(dir, "CARD1"), filepath.Join(dir, "CARD2"), filepath.Join(dir, "CARD3")
+	tests := []struct {
+		name    string
+		roots   []string
+		names   []string
+		spans   []string
+		missing bool
+	}{
+		{"all cards", []string{card1, card2, card3}, []string{"0001AB", "0002AB", "0002EF"}, []string{"0001CD", "0001EF"}, false},
+		// clips keep the order of cards, the shot is where its clip is read first
+		{"cards in any order", []string{card3, filepath.Join(card1, "CONTENTS"), card2}, []string{"0001AB", "0002EF", "0002AB"}, []string{"0001CD", "0001EF"}, false},
+		{"first card only", []string{card1}, []string{"0001AB", "0002AB"}, nil, true},
+		{"last cards only", []string{card2, card3}, []string{"0001CD", "0002EF"}, []string{"0001EF"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clips, err := ReadP2Cards(tt.roots...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, clip := range clips {
+				names = append(names, clip.Name)
+			}
+			if !slices.Equal(names, tt.names) {
+				t.Fatalf("clips = %v, want %v", names, tt.names)
+			}
+			first := clips[0]
+			var spans []string
+			for _, span := range first.Spans {
+				spans = append(spans, span.Name)
+			}
+			if !slices.Equal(spans, tt.spans) || first.MissingSpans != tt.missing {
+				t.Errorf("spans = %v missing %v, want %v missing %v", spans, first.MissingSpans, tt.spans, tt.missing)
+			}
+			for _, clip := range clips[1:] {
+				if len(clip.Spans) > 0 || clip.MissingSpans {
+					t.Errorf("clip %s of one card has spans %+v", clip.Name, clip.Spans)
+				}
+			}
+		})
+	}
+}
+
+func TestReadP2CardFiles(t *testing.T) {
+	dir := writeP2Cards(t)
+	clips, err := ReadP2Cards(filepath.Join(dir, "CARD1"), filepath.Join(dir, "CARD2"), filepath.Join(dir, "CARD3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := filepath.Join(dir, "CARD1", "CONTENTS")
+	clip := clips[0]
+	if clip.XML != filepath.Join(contents, "CLIP", "0001AB.XML") || clip.Video != filepath.Join(contents, "VIDEO", "0001AB.MXF") ||
+		clip.Proxy != filepath.Join(contents, "PROXY", "0001AB.MP4") || clip.Icon != filepath.Join(contents, "ICON", "0001AB.BMP") ||
+		len(clip.Audio) != 2 || clip.GlobalShotID != "S1" || clip.ClipMain == nil {
+		t.Errorf("clip = %+v", clip)
+	}
+	if span := clip.Spans[0]; span.Video != filepath.Join(dir, "CARD2", "Contents", "Video", "0001CD.mxf") || span.ClipMain == nil {
+		t.Errorf("span of other case = %+v", span)
+	}
+	if lost := clips[2]; lost.XML != "" || lost.ClipMain != nil || lost.Video == "" {
+		t.Errorf("clip without XML = %+v", lost)
+	}
+}
+
+func TestReadP2CardsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"BROKEN/CONTENTS/CLIP/0001AB.XML": "<P2Main><ClipContent>",
+		"EMPTY/DCIM/100/IMG.JPG":          "",
+	})
+	for _, root := range []string{"BROKEN", "EMPTY", "MISSING"} {
+		if clips, err := ReadP2Cards(filepath.Join(dir, root)); err == nil {
+			t.Errorf("%s: clips = %+v, want error", root, clips)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package card finds files of a clip in the folder structure written by cameras, like sidecar XML, proxy and thumbnail.
+package card
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const sonyMediaProfile = "MEDIAPRO.XML"
+
+// sonyRoots folders of MEDIAPRO.XML from the card root, M4ROOT of XAVC S/HS cards and XDROOT of XDCAM cards.
+// Files of a clip are one folder below the root: CLIP, SUB and THMBNL of M4ROOT, Clip, Sub and Thmbnl of XDROOT
+var sonyRoots = []string{filepath.Join("PRIVATE", "M4ROOT"), "XDROOT"}
+
+// SonyClip files of a clip on Sony card, paths are empty if not found
+type SonyClip struct {
+	// Original clip of CLIP folder, the proxy links to it
+	Original  string
+	Proxy     string `json:",omitempty"`
+	Thumbnail string `json:",omitempty"`
+	// Sidecar NonRealTimeMeta XML like C0001M01.XML
+	Sidecar      string `json:",omitempty"`
+	MediaProfile string `json:",omitempty"`
+	// Material entry of the clip in MEDIAPRO.XML
+	Material *nrtmd.Material `json:",omitempty"`
+}
+
+// FindSonyClip links clip with its original, proxy, thumbnail and sidecar by MEDIAPRO.XML of the card. Clips copied
+// without the card structure, like transcoded or rewrapped files, only get the sidecar next to them. Returns nil if
+// no file is found
+func FindSonyClip(path string) *SonyClip {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	if clip := findSonyClipOnCard(path); clip != nil {
+		return clip
+	}
+	if sidecar := findSonySidecar(path); sidecar != "" {
+		return &SonyClip{Original: path, Sidecar: sidecar}
+	}
+	return nil
+}
+
+// ReadSonyCard reads clips of MEDIAPRO.XML, root is the card or its M4ROOT or XDROOT folder
+func ReadSonyCard(root string) ([]*SonyClip, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, sonyRoot := range sonyRoots {
+		if dir := filepath.Join(root, sonyRoot); existing(dir) != "" {
+			root = dir
+			break
+		}
+	}
+	profilePath := findFile(root, sonyMediaProfile)
+	if profilePath == "" {
+		return nil, fmt.Errorf("%s not found in %s", sonyMediaProfile, root)
+	}
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	profile, err := nrtmd.ReadMediaProfile(f)
+	if err != nil {
+		return nil, err
+	}
+	clips := make([]*SonyClip, len(profile.Materials))
+	for i := range profile.Materials {
+		clips[i] = newSonyClip(profile, i, root, profilePath)
+	}
+	return clips, nil
+}
+
+// findSonyClipOnCard reads MEDIAPRO.XML of the folder above the folder of clip, see sonyRoots. The root folder may
+// have another name in copies of the card
+func findSonyClipOnCard(path string) *SonyClip {
+	root := filepath.Dir(filepath.Dir(path))
+	profilePath := findFile(root, sonyMediaProfile)
+	if profilePath == "" {
+		return nil
+	}
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	profile, err := nrtmd.ReadMediaProfile(f)
+	if err != nil {
+		return nil
+	}
+	return sonyClipFromProfile(profile, root, profilePath, path)
+}
+
+// sonyClipFromProfile finds material of path, which is the original or one of its proxies
+func sonyClipFromProfile(profile *nrtmd.MediaProfile, root, profilePath, path string) *SonyClip {
+	for i := range profile.Materials {
+		clip := newSonyClip(profile, i, root, profilePath)
+		if samePath(clip.Original, path) || samePath(clip.Proxy, path) {
+			return clip
+		}
+	}
+	return nil
+}
+
+func newSonyClip(profile *nrtmd.MediaProfile, index int, root, profilePath string) *SonyClip {
+	resolve := func(uri string) string {
+		if uri == "" {
+			return ""
+		}
+		return filepath.Join(root, filepath.FromSlash(uri))
+	}
+	material := &profile.Materials[index]
+	clip := &SonyClip{
+		Original:     resolve(material.Uri),
+		Thumbnail:    existing(resolve(material.RelevantFile("JPG"))),
+		Sidecar:      existing(resolve(material.RelevantFile("XML"))),
+		MediaProfile: profilePath,
+		Material:     material,
+	}
+	if len(material.Proxies) > 0 {
+		clip.Proxy = resolve(material.Proxies[0].Uri)
+	}
+	if clip.Sidecar == "" {
+		clip.Sidecar = findSonySidecar(clip.Original)
+	}
+	return clip
+}
+
+// findSonySidecar finds C0001M01.XML or C0001_M01.XML of clip C0001.MP4
+func findSonySidecar(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	for _, name := range []string{base + "M01.XML", base + "_M01.XML"} {
+		if sidecar := findFile(filepath.Dir(path), name); sidecar != "" {
+			return sidecar
+		}
+	}
+	return ""
+}
+
+// findFile finds file of name in dir case-insensitively, cards are FAT or exFAT while copies may be on case-sensitive
+// file systems
+func findFile(dir, name string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), name) {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
+
+func existing(path string) string {
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+func samePath(a, b string) bool {
+	return a != "" && strings.EqualFold(filepath.Clean(a), filepath.Clean(b))
+}
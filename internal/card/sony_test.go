@@ -0,0 +1,183 @@
+package card
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files of slash separated paths under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func mediaProfile(materials ...string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<MediaProfile xmlns="http://xmlns.sony.net/pro/metadata/mediaprofile" createdAt="2024-05-01T10:00:00+08:00" version="2.00">
+<Properties><System systemId="0123" systemKind="ILME-FX3"/><Attached mediaId="0456" mediaKind="ProfessionalDisc" mediaName="CARD"/></Properties>
+<Contents>` + strings.Join(materials, "") + `</Contents>
+</MediaProfile>`
+}
+
+const m4rootMaterial = `<Material uri="./CLIP/C0001.MP4" type="MP4" videoType="AVC_3840_2160_HP@L51" fps="25p" dur="100" ch="2" umid="0D12">
+<Proxy uri="./SUB/C0001S03.MP4" type="MP4" videoType="AVC_1280_720_HP@L31" fps="25p" ch="2"/>
+<RelevantInfo uri="./CLIP/C0001M01.XML" type="XML"/>
+<RelevantInfo uri="./THMBNL/C0001T01.JPG" type="JPG"/>
+</Material>`
+
+const xdrootMaterial = `<Material uri="./Clip/Clip0001.MXF" type="MXF" videoType="AVC_3840_2160_HP@L51" fps="25p" dur="100" ch="4" umid="0D34">
+<Proxy uri="./Sub/Clip0001S01.MXF" type="MXF" fps="25p" ch="2"/>
+<RelevantInfo uri="./Clip/Clip0001M01.XML" type="XML"/>
+<RelevantInfo uri="./Thmbnl/Clip0001T01.JPG" type="JPG"/>
+</Material>`
+
+func TestFindSonyClip(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"A001/PRIVATE/M4ROOT/MEDIAPRO.XML":        mediaProfile(m4rootMaterial),
+		"A001/PRIVATE/M4ROOT/CLIP/C0001.MP4":      "",
+		"A001/PRIVATE/M4ROOT/CLIP/C0001M01.XML":   "",
+		"A001/PRIVATE/M4ROOT/SUB/C0001S03.MP4":    "",
+		"A001/PRIVATE/M4ROOT/THMBNL/C0001T01.JPG": "",
+		"A001/PRIVATE/M4ROOT/CLIP/C0002.MP4":      "",
+		"B001/XDROOT/MEDIAPRO.XML":                mediaProfile(xdrootMaterial),
+		"B001/XDROOT/Clip/Clip0001.MXF":           "",
+		"B001/XDROOT/Clip/Clip0001M01.XML":        "",
+		"B001/XDROOT/Sub/Clip0001S01.MXF":         "",
+		"copy/C0003.MP4":                          "",
+		"copy/c0003m01.xml":                       "",
+		"copy/C0004.MP4":                          "",
+		"copy/C0004_M01.XML":                      "",
+		"copy/C0005.MP4":                          "",
+		"renamed/SONY/mediapro.xml":               mediaProfile(m4rootMaterial),
+		"renamed/SONY/CLIP/C0001.MP4":             "",
+		"renamed/SONY/CLIP/C0001M01.XML":          "",
+	})
+	m4root := filepath.Join(dir, "A001", "PRIVATE", "M4ROOT")
+	xdroot := filepath.Join(dir, "B001", "XDROOT")
+	renamed := filepath.Join(dir, "renamed", "SONY")
+	tests := []struct {
+		name string
+		path string
+		want *SonyClip
+	}{
+		{
+			name: "M4ROOT original",
+			path: filepath.Join(m4root, "CLIP", "C0001.MP4"),
+			want: &SonyClip{
+				Original:     filepath.Join(m4root, "CLIP", "C0001.MP4"),
+				Proxy:        filepath.Join(m4root, "SUB", "C0001S03.MP4"),
+				Thumbnail:    filepath.Join(m4root, "THMBNL", "C0001T01.JPG"),
+				Sidecar:      filepath.Join(m4root, "CLIP", "C0001M01.XML"),
+				MediaProfile: filepath.Join(m4root, "MEDIAPRO.XML"),
+			},
+		},
+		{
+			name: "M4ROOT proxy links to original",
+			path: filepath.Join(m4root, "SUB", "C0001S03.MP4"),
+			want: &SonyClip{
+				Original:     filepath.Join(m4root, "CLIP", "C0001.MP4"),
+				Proxy:        filepath.Join(m4root, "SUB", "C0001S03.MP4"),
+				Thumbnail:    filepath.Join(m4root, "THMBNL", "C0001T01.JPG"),
+				Sidecar:      filepath.Join(m4root, "CLIP", "C0001M01.XML"),
+				MediaProfile: filepath.Join(m4root, "MEDIAPRO.XML"),
+			},
+		},
+		{
+			name: "XDROOT original without thumbnail",
+			path: filepath.Join(xdroot, "Clip", "Clip0001.MXF"),
+			want: &SonyClip{
+				Original:     filepath.Join(xdroot, "Clip", "Clip0001.MXF"),
+				Proxy:        filepath.Join(xdroot, "Sub", "Clip0001S01.MXF"),
+				Sidecar:      filepath.Join(xdroot, "Clip", "Clip0001M01.XML"),
+				MediaProfile: filepath.Join(xdroot, "MEDIAPRO.XML"),
+			},
+		},
+		{
+			name: "renamed root of a copied card",
+			path: filepath.Join(renamed, "CLIP", "C0001.MP4"),
+			want: &SonyClip{
+				Original:     filepath.Join(renamed, "CLIP", "C0001.MP4"),
+				Proxy:        filepath.Join(renamed, "SUB", "C0001S03.MP4"),
+				Sidecar:      filepath.Join(renamed, "CLIP", "C0001M01.XML"),
+				MediaProfile: filepath.Join(renamed, "mediapro.xml"),
+			},
+		},
+		{
+			name: "sidecar of a copied clip in another case",
+			path: filepath.Join(dir, "copy", "C0003.MP4"),
+			want: &SonyClip{Original: filepath.Join(dir, "copy", "C0003.MP4"), Sidecar: filepath.Join(dir, "copy", "c0003m01.xml")},
+		},
+		{
+			name: "sidecar with underscore",
+			path: filepath.Join(dir, "copy", "C0004.MP4"),
+			want: &SonyClip{Original: filepath.Join(dir, "copy", "C0004.MP4"), Sidecar: filepath.Join(dir, "copy", "C0004_M01.XML")},
+		},
+		{name: "clip without sidecar", path: filepath.Join(dir, "copy", "C0005.MP4")},
+		{name: "clip not in MEDIAPRO.XML", path: filepath.Join(m4root, "CLIP", "C0002.MP4")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindSonyClip(tt.path)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("FindSonyClip = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("FindSonyClip = nil")
+			}
+			if tt.want.MediaProfile != "" && got.Material == nil {
+				t.Error("Material is nil")
+			}
+			got.Material = nil
+			if *got != *tt.want {
+				t.Errorf("FindSonyClip = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSonyCard(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"A001/PRIVATE/M4ROOT/MEDIAPRO.XML": mediaProfile(m4rootMaterial, strings.ReplaceAll(m4rootMaterial, "C0001", "C0002")),
+		"B001/XDROOT/MEDIAPRO.XML":         mediaProfile(xdrootMaterial),
+	})
+	tests := []struct {
+		name  string
+		root  string
+		first string
+		count int
+	}{
+		{"M4ROOT card", filepath.Join(dir, "A001"), filepath.Join(dir, "A001", "PRIVATE", "M4ROOT", "CLIP", "C0001.MP4"), 2},
+		{"M4ROOT folder", filepath.Join(dir, "A001", "PRIVATE", "M4ROOT"), filepath.Join(dir, "A001", "PRIVATE", "M4ROOT", "CLIP", "C0001.MP4"), 2},
+		{"XDROOT card", filepath.Join(dir, "B001"), filepath.Join(dir, "B001", "XDROOT", "Clip", "Clip0001.MXF"), 1},
+		{"XDROOT folder", filepath.Join(dir, "B001", "XDROOT"), filepath.Join(dir, "B001", "XDROOT", "Clip", "Clip0001.MXF"), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clips, err := ReadSonyCard(tt.root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(clips) != tt.count || clips[0].Original != tt.first {
+				t.Errorf("got %d clips, first %+v", len(clips), clips[0])
+			}
+		})
+	}
+	if _, err := ReadSonyCard(filepath.Join(dir, "missing")); err == nil {
+		t.Error("card without MEDIAPRO.XML, want error")
+	}
+}
@@ -12,6 +12,10 @@ const (
 	mp4EpochToUnix   int64 = ((1903*365 + 1903/4 - 1903/100 + 1903/400) - (1969*365 + 1969/4 - 1969/100 + 1969/400)) * secondsPerDay
 )
 
+// MetadataKeyPrefix namespace of metadata item keys written by this tool,
+// the key of DaVinci Resolve field is MetadataKeyPrefix + field name, like com.github.fukco.media-metadata.ReelNumber
+const MetadataKeyPrefix = "com.github.fukco.media-metadata."
+
 func Mp4Epoch(sec int64) *time.Time {
 	t := time.Unix(sec+mp4EpochToUnix, 0)
 	return &t
@@ -30,6 +34,17 @@ func ConvertBitrate(input uint32) string {
 	return fmt.Sprintf("%d%s", input, units[i])
 }
 
+// ExposureTimeFormat formats exposure time in seconds, e.g. 1/50 or 1.5
+func ExposureTimeFormat(seconds float64) string {
+	if seconds <= 0 {
+		return ""
+	}
+	if seconds <= 0.25 {
+		return fmt.Sprintf("1/%.0f", 1/seconds)
+	}
+	return fmt.Sprintf("%.1f", seconds)
+}
+
 func FormatFNumber(value string) string {
 	return ""
 }
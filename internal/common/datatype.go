@@ -34,7 +34,7 @@ func (r *Rational) Value() any {
 }
 
 func (r *Rational) String() string {
-	if r.Numerator == 0 || r.Denominator == 0 {
+	if r == nil || r.Numerator == 0 || r.Denominator == 0 {
 		return "0"
 	} else {
 		if r.Denominator/r.Numerator >= 3 {
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Location normalized geographic location of the clip, WGS 84
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	// Altitude meters above sea level
+	Altitude *float64 `json:",omitempty"`
+	// Heading degrees from true north
+	Heading   *float64   `json:",omitempty"`
+	Timestamp *time.Time `json:",omitempty"`
+	// Source which metadata supplied the location
+	Source string
+}
+
+// ParseISO6709 parses location string like "+31.2304+121.4737+010.000/" or "+3113.82+12128.42/"
+// Reference: ISO 6709:2008 Annex H
+func ParseISO6709(s string) (*Location, error) {
+	s = strings.TrimSpace(s)
+	if idx := strings.Index(s, "CRS"); idx >= 0 {
+		s = s[:idx]
+	}
+	s = strings.TrimRight(s, "/")
+	parts := make([]string, 0, 3)
+	for i := 0; i < len(s); {
+		if s[i] != '+' && s[i] != '-' {
+			return nil, fmt.Errorf("invalid ISO 6709 string: %s", s)
+		}
+		j := i + 1
+		for j < len(s) && s[j] != '+' && s[j] != '-' {
+			j++
+		}
+		parts = append(parts, s[i:j])
+		i = j
+	}
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid ISO 6709 string: %s", s)
+	}
+	latitude, err := parseISO6709Angle(parts[0], 2)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := parseISO6709Angle(parts[1], 3)
+	if err != nil {
+		return nil, err
+	}
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("ISO 6709 coordinate out of range: %s", s)
+	}
+	location := &Location{Latitude: latitude, Longitude: longitude}
+	if len(parts) > 2 {
+		altitude, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		location.Altitude = &altitude
+	}
+	return location, nil
+}
+
+// parseISO6709Angle parses ±DD.D, ±DDMM.M or ±DDMMSS.S, degreeDigits is 2 for latitude and 3 for longitude
+func parseISO6709Angle(s string, degreeDigits int) (float64, error) {
+	sign := 1.0
+	if s[0] == '-' {
+		sign = -1
+	}
+	s = s[1:]
+	intLength := strings.Index(s, ".")
+	if intLength < 0 {
+		intLength = len(s)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	switch intLength {
+	case degreeDigits:
+		return sign * value, nil
+	case degreeDigits + 2:
+		degrees, _ := strconv.ParseFloat(s[:degreeDigits], 64)
+		minutes, _ := strconv.ParseFloat(s[degreeDigits:], 64)
+		return sign * (degrees + minutes/60), nil
+	case degreeDigits + 4:
+		degrees, _ := strconv.ParseFloat(s[:degreeDigits], 64)
+		minutes, _ := strconv.ParseFloat(s[degreeDigits:degreeDigits+2], 64)
+		seconds, _ := strconv.ParseFloat(s[degreeDigits+2:], 64)
+		return sign * (degrees + minutes/60 + seconds/3600), nil
+	default:
+		return 0, fmt.Errorf("invalid ISO 6709 angle: %s", s)
+	}
+}
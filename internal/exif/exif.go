@@ -2,12 +2,17 @@ package exif
 
 type ExifMeta struct {
 	Tags map[string][]*ExifTag
+	GPS  *GPSInfo `json:",omitempty"`
+	// XMP packet of JPEG APP1 segment or tiff tag 0x02bc
+	XMP []byte `json:"-"`
 }
 
 type Base struct {
 	TiffHeader  *TiffHeader
 	Directories []*TiffDirectory
 	MakerIFD    *MakerDirectory
+
+	ifdCount int
 }
 
 type MakerDirectory struct {
@@ -29,6 +34,7 @@ const (
 	IFD0     = DirectoryType(GroupIFD0)
 	ExifIFD  = DirectoryType(GroupExif)
 	IFD1     = DirectoryType(GroupIFD1)
+	GPSIFD   = DirectoryType(GroupGPS)
 	MakerIFD = DirectoryType(GroupMakerNotes)
 )
 
@@ -38,6 +44,7 @@ const (
 	GroupIFD0                GroupName = "IFD0"
 	GroupIFD1                GroupName = "IFD1"
 	GroupExif                GroupName = "ExifIFD"
+	GroupGPS                 GroupName = "GPS"
 	GroupMakerNotes          GroupName = "Maker"
 	GroupCanonCameraSettings GroupName = "Canon CameraSettings"
 	GroupCanonShotInfo       GroupName = "Canon ShotInfo"
@@ -0,0 +1,69 @@
+package exif
+
+import (
+	"encoding/binary"
+	"github.com/fukco/media-metadata/internal/manufacturer"
+	"testing"
+)
+
+type fuzzEntry struct {
+	tag      uint16
+	dataType DataType
+	count    uint32
+	value    []byte
+}
+
+// fuzzIFD little endian IFD at offset start followed by the values larger than 4 bytes
+func fuzzIFD(start int, entries []fuzzEntry) []byte {
+	order := binary.LittleEndian
+	data := order.AppendUint16(nil, uint16(len(entries)))
+	var values []byte
+	for _, entry := range entries {
+		data = order.AppendUint16(data, entry.tag)
+		data = order.AppendUint16(data, uint16(entry.dataType))
+		data = order.AppendUint32(data, entry.count)
+		if len(entry.value) > 4 {
+			data = order.AppendUint32(data, uint32(start+2+len(entries)*12+4+len(values)))
+			values = append(values, entry.value...)
+		} else {
+			data = append(data, append(entry.value, make([]byte, 4-len(entry.value))...)...)
+		}
+	}
+	data = order.AppendUint32(data, 0)
+	return append(data, values...)
+}
+
+func rational(values ...uint32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+func FuzzExif(f *testing.F) {
+	gps := []fuzzEntry{
+		{0x0000, DTByte, 4, []byte{2, 3, 0, 0}},
+		{0x0002, DTRational, 3, rational(35, 1, 40, 1, 1234, 100)},
+		{0x0006, DTRational, 1, rational(1234, 10)},
+		{0x0011, DTRational, 1, rational(9000, 100)},
+	}
+	ifd0 := []fuzzEntry{
+		{0x010f, DTAscii, 6, []byte("Canon\x00")},
+		{0x0110, DTAscii, 4, []byte("R5C\x00")},
+		{0x8825, DTLong, 1, make([]byte, 4)},
+	}
+	tiff := append([]byte{'I', 'I', 0x2a, 0, 8, 0, 0, 0}, fuzzIFD(8, ifd0)...)
+	binary.LittleEndian.PutUint32(tiff[8+2+2*12+8:], uint32(len(tiff)))
+	f.Add(append(tiff, fuzzIFD(len(tiff), gps)...))
+	f.Add([]byte{'M', 'M', 0, 0x2a, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0})
+	f.Add(append([]byte("Panasonic\x00\x00\x00"), make([]byte, 16)...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		mfrs := []manufacturer.Manufacturer{manufacturer.Unknown, manufacturer.CANON, manufacturer.FUJIFILM, manufacturer.PANASONIC}
+		for _, mfr := range mfrs {
+			for _, ignoreHeader := range []bool{false, true} {
+				_, _ = Process(data, ignoreHeader, mfr)
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package exif
+
+import (
+	"strings"
+	"time"
+)
+
+// GPSInfo numeric values of GPS IFD
+type GPSInfo struct {
+	Latitude     float64
+	Longitude    float64
+	Altitude     *float64   `json:",omitempty"`
+	ImgDirection *float64   `json:",omitempty"`
+	Timestamp    *time.Time `json:",omitempty"`
+}
+
+func degrees(ratVals [][]int64) float64 {
+	result, unit := 0.0, 1.0
+	for _, ratVal := range ratVals {
+		result += rationalValue(ratVal) / unit
+		unit *= 60
+	}
+	return result
+}
+
+func readGPSInfo(directory *TiffDirectory) *GPSInfo {
+	entries := make(map[uint16]*TiffEntry, len(directory.Entries))
+	for _, entry := range directory.Entries {
+		entries[entry.TagId] = entry
+	}
+	latitude, latOk := entries[0x02]
+	longitude, lonOk := entries[0x04]
+	if !latOk || !lonOk || len(latitude.RatVals) != 3 || len(longitude.RatVals) != 3 {
+		return nil
+	}
+	gps := &GPSInfo{
+		Latitude:  degrees(latitude.RatVals),
+		Longitude: degrees(longitude.RatVals),
+	}
+	if ref, ok := entries[0x01]; ok && len(ref.StrVals) > 0 && strings.HasPrefix(ref.StrVals[0], "S") {
+		gps.Latitude = -gps.Latitude
+	}
+	if ref, ok := entries[0x03]; ok && len(ref.StrVals) > 0 && strings.HasPrefix(ref.StrVals[0], "W") {
+		gps.Longitude = -gps.Longitude
+	}
+	if altitude, ok := entries[0x06]; ok && len(altitude.RatVals) == 1 {
+		value := rationalValue(altitude.RatVals[0])
+		if ref, ok := entries[0x05]; ok && len(ref.IntVals) > 0 && ref.IntVals[0] == 1 {
+			value = -value
+		}
+		gps.Altitude = &value
+	}
+	if direction, ok := entries[0x11]; ok && len(direction.RatVals) == 1 {
+		value := rationalValue(direction.RatVals[0])
+		gps.ImgDirection = &value
+	}
+	dateStamp, dateOk := entries[0x1d]
+	timeStamp, timeOk := entries[0x07]
+	if dateOk && timeOk && len(dateStamp.StrVals) > 0 && len(timeStamp.RatVals) == 3 {
+		if date, err := time.Parse("2006:01:02", strings.TrimSpace(dateStamp.StrVals[0])); err == nil {
+			seconds := rationalValue(timeStamp.RatVals[0])*3600 + rationalValue(timeStamp.RatVals[1])*60 + rationalValue(timeStamp.RatVals[2])
+			t := date.Add(time.Duration(seconds * float64(time.Second))).UTC()
+			gps.Timestamp = &t
+		}
+	}
+	return gps
+}
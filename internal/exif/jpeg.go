@@ -1,7 +1,38 @@
 package exif
 
+import "bytes"
+
+const XMPHeader = "http://ns.adobe.com/xap/1.0/\x00"
+
 type applicationMarketSegment struct {
 	marker string
 	length uint32
 	exif   Base
 }
+
+// ExtractXMP returns the XMP packet of APP1 segments in JPEG data
+func ExtractXMP(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return nil
+		}
+		marker := data[i+1]
+		// start of scan or end of image, no more metadata segments
+		if marker == 0xda || marker == 0xd9 {
+			return nil
+		}
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte(XMPHeader)) {
+			return segment[len(XMPHeader):]
+		}
+		i += 2 + length
+	}
+	return nil
+}
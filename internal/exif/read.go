@@ -18,6 +18,14 @@ const (
 	LittleEndianHeader = "49492a00"
 )
 
+// maxIFDCount limits IFDs of one tiff structure, linked or nested IFDs may point to each other in corrupted data
+const maxIFDCount = 64
+
+var (
+	ErrOutOfBounds = errors.New("exif offset out of bounds")
+	ErrTooManyIFD  = errors.New("too many exif IFDs")
+)
+
 func readByteOrder(data []byte) (binary.ByteOrder, error) {
 	if hex.EncodeToString(data) == BigEndianHeader {
 		return binary.BigEndian, nil
@@ -28,43 +36,43 @@ func readByteOrder(data []byte) (binary.ByteOrder, error) {
 }
 
 func readIFD(data []byte, offset uint32, directoryType DirectoryType, exif *Base, mfr manufacturer.Manufacturer) error {
+	exif.ifdCount++
+	if exif.ifdCount > maxIFDCount {
+		return ErrTooManyIFD
+	}
 	order := exif.TiffHeader.Order
-	numOfDE := order.Uint16(data[offset : offset+2])
+	b, err := bytesAt(data, offset, 2)
+	if err != nil {
+		return err
+	}
+	numOfDE := order.Uint16(b)
 	entries := make([]*TiffEntry, 0, numOfDE)
 	for i := 0; uint16(i) < numOfDE; i++ {
-		start := offset + 2 + uint32(i*12)
-		tagId := order.Uint16(data[start : start+2])
-		dataType := DataType(order.Uint16(data[start+2 : start+4]))
-		count := order.Uint32(data[start+4 : start+8])
-		valueOrOffsetBytes := data[start+8 : start+12]
-		entry := &TiffEntry{
-			TagId: tagId,
-			Type:  dataType,
-			Count: count,
-			Order: order,
-		}
-		if TypeSize[dataType]*count > 4 {
-			valueOffset := order.Uint32(valueOrOffsetBytes)
-			entry.ValOrOffset = valueOffset
-			entry.Val = data[valueOffset : valueOffset+TypeSize[dataType]*count]
-		} else {
-			entry.Val = valueOrOffsetBytes
+		entry, valueOrOffsetBytes, err := readEntry(data, offset+2+uint32(i*12), order)
+		if err != nil {
+			return err
 		}
+		tagId := entry.TagId
 		if tagId == 0x8769 {
 			if err := readIFD(data, order.Uint32(valueOrOffsetBytes), ExifIFD, exif, mfr); err != nil {
 				return err
 			}
+		} else if tagId == 0x8825 {
+			if err := readIFD(data, order.Uint32(valueOrOffsetBytes), GPSIFD, exif, mfr); err != nil {
+				return err
+			}
 		} else if tagId == 0x927c {
-			if string(data[order.Uint32(valueOrOffsetBytes):order.Uint32(valueOrOffsetBytes)+9]) == string(Panasonic) {
-				if err := readMakerNotes(data, order.Uint32(valueOrOffsetBytes)+12, exif, Panasonic); err != nil {
+			makerNoteOffset := order.Uint32(valueOrOffsetBytes)
+			if signature, err := bytesAt(data, makerNoteOffset, 9); err == nil && string(signature) == string(Panasonic) {
+				if err := readMakerNotes(data, makerNoteOffset+12, exif, Panasonic); err != nil {
 					return err
 				}
-			} else if string(data[order.Uint32(valueOrOffsetBytes):order.Uint32(valueOrOffsetBytes)+8]) == string(FUJIFILM) {
-				if err := readMakerNotes(data[order.Uint32(valueOrOffsetBytes):], 12, exif, FUJIFILM); err != nil {
+			} else if signature, err := bytesAt(data, makerNoteOffset, 8); err == nil && string(signature) == string(FUJIFILM) {
+				if err := readMakerNotes(data[makerNoteOffset:], 12, exif, FUJIFILM); err != nil {
 					return err
 				}
 			} else if mfr == manufacturer.CANON {
-				if err := readMakerNotes(data, order.Uint32(valueOrOffsetBytes), exif, Canon); err != nil {
+				if err := readMakerNotes(data, makerNoteOffset, exif, Canon); err != nil {
 					return err
 				}
 			}
@@ -75,7 +83,15 @@ func readIFD(data []byte, offset uint32, directoryType DirectoryType, exif *Base
 			entries = append(entries, entry)
 		}
 	}
-	nextIFDOffset := order.Uint32(data[offset+2+uint32(numOfDE*12) : offset+2+uint32(numOfDE*12)+4])
+	b, err = bytesAt(data, offset+2+uint32(numOfDE)*12, 4)
+	if err != nil {
+		return err
+	}
+	nextIFDOffset := order.Uint32(b)
+	if directoryType == GPSIFD {
+		// GPS IFD has no linked IFD
+		nextIFDOffset = 0
+	}
 	directory := &TiffDirectory{
 		Group:               []string{string(directoryType)},
 		NumOfDE:             numOfDE,
@@ -92,28 +108,53 @@ func readIFD(data []byte, offset uint32, directoryType DirectoryType, exif *Base
 	return nil
 }
 
+// readEntry reads the 12 bytes directory entry at start, value is read from offset if it is larger than 4 bytes
+func readEntry(data []byte, start uint32, order binary.ByteOrder) (*TiffEntry, []byte, error) {
+	b, err := bytesAt(data, start, 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataType := DataType(order.Uint16(b[2:4]))
+	entry := &TiffEntry{
+		TagId: order.Uint16(b[:2]),
+		Type:  dataType,
+		Count: order.Uint32(b[4:8]),
+		Order: order,
+	}
+	valueOrOffsetBytes := b[8:12]
+	if size := uint64(TypeSize[dataType]) * uint64(entry.Count); size > 4 {
+		valueOffset := order.Uint32(valueOrOffsetBytes)
+		entry.ValOrOffset = valueOffset
+		if entry.Val, err = bytesAt(data, valueOffset, size); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		entry.Val = valueOrOffsetBytes
+	}
+	return entry, valueOrOffsetBytes, nil
+}
+
+// bytesAt returns data[offset:offset+length] if it is in bounds
+func bytesAt(data []byte, offset uint32, length uint64) ([]byte, error) {
+	end := uint64(offset) + length
+	if end > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: %d+%d of %d", ErrOutOfBounds, offset, length, len(data))
+	}
+	return data[offset:end], nil
+}
+
 func readMakerNotes(data []byte, offset uint32, exif *Base, maker Maker) error {
 	order := exif.TiffHeader.Order
-	numOfDE := order.Uint16(data[offset : offset+2])
+	b, err := bytesAt(data, offset, 2)
+	if err != nil {
+		return err
+	}
+	numOfDE := order.Uint16(b)
 	entries := make([]*TiffEntry, 0, numOfDE)
 	for i := 0; uint16(i) < numOfDE; i++ {
-		start := offset + 2 + uint32(i*12)
-		tagId := order.Uint16(data[start : start+2])
-		dataType := DataType(order.Uint16(data[start+2 : start+4]))
-		count := order.Uint32(data[start+4 : start+8])
-		valueOrOffsetBytes := data[start+8 : start+12]
-		entry := &TiffEntry{
-			TagId: tagId,
-			Type:  dataType,
-			Count: count,
-			Order: order,
-		}
-		if TypeSize[dataType]*count > 4 {
-			valueOffset := order.Uint32(valueOrOffsetBytes)
-			entry.ValOrOffset = valueOffset
-			entry.Val = data[valueOffset : valueOffset+TypeSize[dataType]*count]
-		} else {
-			entry.Val = valueOrOffsetBytes
+		entry, _, err := readEntry(data, offset+2+uint32(i*12), order)
+		if err != nil {
+			return err
 		}
 		if err := entry.convertVals(); err != nil {
 			return err
@@ -133,24 +174,27 @@ func readMakerNotes(data []byte, offset uint32, exif *Base, maker Maker) error {
 
 func exifEntryConvert2Tags(entry *TiffEntry, group Group) ([]*ExifTag, error) {
 	tagDefinition := exifTagDefinitionMap[entry.TagId]
+	if len(group) > 0 && group[0] == string(GroupGPS) {
+		tagDefinition = gpsTagDefinitionMap[entry.TagId]
+	}
 	var value any
 	format := entry.Format
 	var valueStr string
 	switch format {
 	case IntVal:
-		if entry.Count == 1 {
+		if len(entry.IntVals) == 1 {
 			value = entry.IntVals[0]
 		} else {
 			value = entry.IntVals
 		}
 	case FloatVal:
-		if entry.Count == 1 {
+		if len(entry.FloatVals) == 1 {
 			value = entry.FloatVals[0]
 		} else {
 			value = entry.FloatVals
 		}
 	case RatVal:
-		if entry.Count == 1 {
+		if len(entry.RatVals) == 1 {
 			value = entry.RatVals[0]
 		} else {
 			value = entry.RatVals
@@ -198,19 +242,19 @@ func makerNotesConvert2Tags(entry *TiffEntry, group Group, maker Maker) ([]*Exif
 	var valueStr string
 	switch format {
 	case IntVal:
-		if entry.Count == 1 {
+		if len(entry.IntVals) == 1 {
 			value = entry.IntVals[0]
 		} else {
 			value = entry.IntVals
 		}
 	case FloatVal:
-		if entry.Count == 1 {
+		if len(entry.FloatVals) == 1 {
 			value = entry.FloatVals[0]
 		} else {
 			value = entry.FloatVals
 		}
 	case RatVal:
-		if entry.Count == 1 {
+		if len(entry.RatVals) == 1 {
 			value = entry.RatVals[0]
 		} else {
 			value = entry.RatVals
@@ -256,12 +300,11 @@ func makerNotesConvert2Tags(entry *TiffEntry, group Group, maker Maker) ([]*Exif
 					subTagDefinitionMap := tagDefinition.SubTagDefinition.subTagDefinitionMap
 					for key := range subTagDefinitionMap {
 						subTagDefinition := subTagDefinitionMap[key]
+						if key.(int) >= len(data) {
+							return nil, errors.New("invalid data or tag definition")
+						}
 						if subTagDefinition.Fn != nil {
-							if key.(int) < len(data) {
-								valueStr = subTagDefinition.Fn(data[key.(int)])
-							} else {
-								return nil, errors.New("invalid data or tag definition")
-							}
+							valueStr = subTagDefinition.Fn(data[key.(int)])
 						} else {
 							valueStr = fmt.Sprintf("%v", data[key.(int)])
 						}
@@ -311,6 +354,18 @@ func toExifMeta(base *Base) (*ExifMeta, error) {
 		}
 	}
 	exifMeta := &ExifMeta{Tags: make(map[string][]*ExifTag, 8)}
+	for i := range base.Directories {
+		directory := base.Directories[i]
+		if directory.Group[0] == string(GroupGPS) {
+			exifMeta.GPS = readGPSInfo(directory)
+		} else if directory.Group[0] == string(GroupIFD0) {
+			for _, entry := range directory.Entries {
+				if entry.TagId == 0x02bc {
+					exifMeta.XMP = entry.Val
+				}
+			}
+		}
+	}
 	for i := range exifTags {
 		exifTag := exifTags[i]
 		groupName := strings.Join(exifTag.Group, "/")
@@ -328,6 +383,9 @@ func readExif(data []byte, ignoreHeader bool, mfr manufacturer.Manufacturer) (*B
 	var order binary.ByteOrder = binary.LittleEndian
 	var offset uint32
 	if !ignoreHeader {
+		if len(data) < 8 {
+			return nil, ErrOutOfBounds
+		}
 		readOrder, err := readByteOrder(data[:4])
 		if err != nil {
 			return nil, err
@@ -344,14 +402,27 @@ func readExif(data []byte, ignoreHeader bool, mfr manufacturer.Manufacturer) (*B
 }
 
 func Process(data []byte, ignoreHeader bool, mfr manufacturer.Manufacturer) (*ExifMeta, error) {
-	exif, _ := readExif(data, ignoreHeader, mfr)
+	exif, err := readExif(data, ignoreHeader, mfr)
+	if err != nil {
+		return nil, err
+	}
 	return toExifMeta(exif)
 }
 
 func ProcessJPEG(data []byte, mfr manufacturer.Manufacturer) (*ExifMeta, error) {
-	if !(hex.EncodeToString(data[:4]) == SOI+APP1 && hex.EncodeToString(data[6:12]) == ExifHeader) {
+	if len(data) < 12 || !(hex.EncodeToString(data[:4]) == SOI+APP1 && hex.EncodeToString(data[6:12]) == ExifHeader) {
 		return nil, fmt.Errorf("invalid JPEG format")
 	}
-	size := binary.BigEndian.Uint16(data[4:6])
-	return Process(data[12:int(size+4)], false, mfr)
+	size := int(binary.BigEndian.Uint16(data[4:6]))
+	if size < 8 || size+4 > len(data) {
+		return nil, fmt.Errorf("invalid JPEG APP1 size: %d", size)
+	}
+	exifMeta, err := Process(data[12:size+4], false, mfr)
+	if err != nil {
+		return nil, err
+	}
+	if xmp := ExtractXMP(data); xmp != nil {
+		exifMeta.XMP = xmp
+	}
+	return exifMeta, nil
 }
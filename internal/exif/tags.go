@@ -10,16 +10,22 @@ import (
 
 var exifTagDefinitionMap = map[uint16]*TagDefinition{
 	// IFD0
-	0x010f:  {Name: "Make"},
-	0x0110:  {Name: "Model"},
-	0x0132:  {Name: "Date Time"},
-	0x013b:  {Name: "Artist"},
-	0x0201:  {Name: "JPEGInterchangeFormat"},
-	0x0202:  {Name: "JPEGInterchangeFormatLength"},
+	0x010f: {Name: "Make"},
+	0x0110: {Name: "Model"},
+	0x0132: {Name: "Date Time"},
+	0x013b: {Name: "Artist"},
+	0x0201: {Name: "JPEGInterchangeFormat"},
+	0x0202: {Name: "JPEGInterchangeFormatLength"},
+	0x02bc: {Name: "Application Notes", Fn: func(v any) string {
+		return "(XMP packet)"
+	}},
 	0x08298: {Name: "Copyright"},
 	// EXIF IFD
 	0x829a: {Name: "Exposure time", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
 		if data[0]*3 > data[1] {
 			return fmt.Sprintf("%.1f″", float64(data[0])/float64(data[1]))
 		} else if data[0] == 0 || data[1] == 0 {
@@ -29,7 +35,10 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x829d: {Name: "F number", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
 		if data[0] == 0 || data[1] == 0 {
 			return "undef"
 		} else if data[0] < data[1] {
@@ -39,7 +48,10 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x8822: {Name: "Exposure program", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		// 0 未定义 1 手动 2 自动（程序曝光） 3 光圈优先 4 快门优先 5 创意（偏向景深） 6 动作（偏向高速快门） 7 肖像模式 8 风景模式
 		switch data {
 		case 0:
@@ -66,7 +78,10 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 	}},
 	0x8827: {Name: "Photographic Sensitivity"},
 	0x8830: {Name: "Sensitivity Type", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 0:
 			return "Unknown"
@@ -95,11 +110,17 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 	0x9011: {Name: "Offset Time Original"},
 	0x9201: {Name: "Shutter Speed"},
 	0x9204: {Name: "Exposure bias", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
 		return fmt.Sprintf("%.1f", float64(data[0])/float64(data[1]))
 	}},
 	0x9207: {Name: "Metering mode", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		// 0 未知 1 平均测光 2 中央重点测光 3 点测光 4 多点测光 5 矩阵测光 6 部分测光 255 其他
 		case 0:
@@ -123,15 +144,29 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x920a: {Name: "Lens focal length", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
 		return fmt.Sprintf("%.1f mm", float64(data[0])/float64(data[1]))
 	}},
 	0xa431: {Name: "Body Serial Number", Fn: func(v any) string {
-		str := v.(string)
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		return strings.TrimRight(str, string(byte(0)))
 	}},
 	0xa432: {Name: "Lens Specification", Fn: func(v any) string {
-		data := v.([][]int64)
+		data, ok := v.([][]int64)
+		if !ok || len(data) != 4 {
+			return fmt.Sprintf("%v", v)
+		}
+		for _, r := range data {
+			if len(r) != 2 || r[1] == 0 {
+				return fmt.Sprintf("%v", v)
+			}
+		}
 		minFocalLength := data[0]
 		maxFocalLength := data[1]
 		minFNumber := data[2]
@@ -162,9 +197,94 @@ var exifTagDefinitionMap = map[uint16]*TagDefinition{
 	0xa435: {Name: "Lens Serial Number"},
 }
 
+var gpsTagDefinitionMap = map[uint16]*TagDefinition{
+	0x00: {Name: "GPS Version ID", Fn: func(v any) string {
+		data, ok := v.([]int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		str := make([]string, 0, len(data))
+		for _, num := range data {
+			str = append(str, strconv.FormatInt(num, 10))
+		}
+		return strings.Join(str, ".")
+	}},
+	0x01: {Name: "GPS Latitude Ref"},
+	0x02: {Name: "GPS Latitude", Fn: gpsCoordinateFormat},
+	0x03: {Name: "GPS Longitude Ref"},
+	0x04: {Name: "GPS Longitude", Fn: gpsCoordinateFormat},
+	0x05: {Name: "GPS Altitude Ref", Fn: func(v any) string {
+		switch v {
+		case int64(0):
+			return "Above Sea Level"
+		case int64(1):
+			return "Below Sea Level"
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	}},
+	0x06: {Name: "GPS Altitude", Fn: func(v any) string {
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
+		if data[1] == 0 {
+			return "undef"
+		}
+		return fmt.Sprintf("%.1f m", rationalValue(data))
+	}},
+	0x07: {Name: "GPS Time Stamp", Fn: func(v any) string {
+		data, ok := v.([][]int64)
+		if !ok || len(data) != 3 {
+			return fmt.Sprintf("%v", v)
+		}
+		return fmt.Sprintf("%02.0f:%02.0f:%02.0f", rationalValue(data[0]), rationalValue(data[1]), rationalValue(data[2]))
+	}},
+	0x08: {Name: "GPS Satellites"},
+	0x09: {Name: "GPS Status"},
+	0x0a: {Name: "GPS Measure Mode"},
+	0x0b: {Name: "GPS Dilution Of Precision"},
+	0x0c: {Name: "GPS Speed Ref"},
+	0x0d: {Name: "GPS Speed"},
+	0x0e: {Name: "GPS Track Ref"},
+	0x0f: {Name: "GPS Track"},
+	0x10: {Name: "GPS Img Direction Ref"},
+	0x11: {Name: "GPS Img Direction", Fn: func(v any) string {
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
+		if data[1] == 0 {
+			return "undef"
+		}
+		return fmt.Sprintf("%.2f", rationalValue(data))
+	}},
+	0x12: {Name: "GPS Map Datum"},
+	0x1d: {Name: "GPS Date Stamp"},
+	0x1f: {Name: "GPS Horizontal Positioning Error"},
+}
+
+func rationalValue(data []int64) float64 {
+	if len(data) != 2 || data[1] == 0 {
+		return 0
+	}
+	return float64(data[0]) / float64(data[1])
+}
+
+func gpsCoordinateFormat(v any) string {
+	data, ok := v.([][]int64)
+	if !ok || len(data) != 3 {
+		return fmt.Sprintf("%v", v)
+	}
+	return fmt.Sprintf("%.0f° %.0f' %.2f\"", rationalValue(data[0]), rationalValue(data[1]), rationalValue(data[2]))
+}
+
 var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 	0x02: {Name: "Firmware Version", Fn: func(v any) string {
-		data := v.([]uint8)
+		data, ok := v.([]uint8)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		str := make([]string, 0, len(data))
 		for _, num := range data {
 			str = append(str, strconv.FormatUint(uint64(num), 10))
@@ -173,7 +293,11 @@ var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 		return res
 	}},
 	0x03: {Name: "White Balance", Fn: func(v any) string {
-		switch v.(int64) {
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		switch data {
 		case 1:
 			return "Auto"
 		case 2:
@@ -205,7 +329,11 @@ var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x07: {Name: "Focus Mode", Fn: func(v any) string {
-		switch v.(int64) {
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		switch data {
 		case 1:
 			return "Auto"
 		case 2:
@@ -225,7 +353,10 @@ var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x0f: {Name: "AF Area Mode", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		byteSlice := make([]byte, 0, len(data))
 		for _, datum := range data {
 			byteSlice = append(byteSlice, byte(datum))
@@ -274,17 +405,27 @@ var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x25: {Name: "Internal Serial Number", Fn: func(v any) string {
-		str := string(v.([]byte))
+		data, ok := v.([]byte)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		str := string(data)
 		return strings.TrimRight(str, string(byte(0)))
 	}},
 	0x44: {Name: "Color Temp Kelvin"},
 	0x51: {Name: "Lens Type"},
 	0x52: {Name: "Lens Serial Number", Fn: func(v any) string {
-		str := v.(string)
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		return strings.TrimRight(str, string(byte(0)))
 	}},
 	0x60: {Name: "Lens Firmware Version", Fn: func(v any) string {
-		data := v.([]uint8)
+		data, ok := v.([]uint8)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		str := make([]string, 0, len(data))
 		for _, num := range data {
 			str = append(str, strconv.FormatUint(uint64(num), 10))
@@ -293,11 +434,18 @@ var panasonicTagDefinitionMap = map[uint16]*TagDefinition{
 		return res
 	}},
 	0x9d: {Name: "Internal ND Filter", Fn: func(v any) string {
-		data := v.([]int64)
+		data, ok := v.([]int64)
+		if !ok || len(data) != 2 {
+			return fmt.Sprintf("%v", v)
+		}
 		return fmt.Sprintf("%.1f", float64(data[0])/float64(data[1]))
 	}},
 	0x9f: {Name: "Shutter Type", Fn: func(v any) string {
-		switch v.(int64) {
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
+		switch data {
 		case 0:
 			return "Mechanical"
 		case 1:
@@ -315,7 +463,10 @@ var fujiExifTagDefinitionMap = map[uint16]*TagDefinition{
 		return fmt.Sprintf("%v", v)
 	}},
 	0x1002: {Name: "White Balance", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 0x0:
 			return "Auto"
@@ -360,7 +511,10 @@ var fujiExifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x1031: {Name: "Picture Mode", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 0x0:
 			return "Auto"
@@ -433,7 +587,10 @@ var fujiExifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x1401: {Name: "Film Mode", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 0x0:
 			return "F0/Standard (Provia)"
@@ -470,7 +627,10 @@ var fujiExifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x3803: {Name: "Video Recording Mode", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 0x0:
 			return "Normal"
@@ -483,7 +643,10 @@ var fujiExifTagDefinitionMap = map[uint16]*TagDefinition{
 		}
 	}},
 	0x3806: {Name: "Video Compression", Fn: func(v any) string {
-		data := v.(int64)
+		data, ok := v.(int64)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		switch data {
 		case 1:
 			return "Log GOP"
@@ -505,7 +668,10 @@ var canonExifTagDefinitionMap = map[uint16]*TagDefinition{
 	0x7:  {Name: "Canon Firmware Version"},
 	0x10: {Name: "Canon Model ID"}, // Ref:https://exiftool.org/TagNames/Canon.html#CanonModelID
 	0x38: {Name: "Battery Type", Fn: func(v any) string {
-		data := v.([]uint8)
+		data, ok := v.([]uint8)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		if len(data) == 76 {
 			if hex.EncodeToString(data[:4]) == "4c000000" {
 				replacer := strings.NewReplacer(string(byte(0)), "", string(byte(1)), "")
@@ -516,7 +682,10 @@ var canonExifTagDefinitionMap = map[uint16]*TagDefinition{
 		return ""
 	}},
 	0x95: {Name: "Lens Model", Fn: func(v any) string {
-		data := v.(string)
+		data, ok := v.(string)
+		if !ok {
+			return fmt.Sprintf("%v", v)
+		}
 		index := strings.Index(data, string([]byte{1, 1, 1, 1}))
 		if index >= 0 {
 			return strings.TrimSpace(data[:index])
@@ -532,7 +701,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 	string(GroupCanonCameraSettings): {tagDefinitionType: byIndex,
 		subTagDefinitionMap: map[any]*TagDefinition{
 			7: {Name: "Focus Mode", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "One-shot AF"
 				case 1:
@@ -562,7 +735,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			9: {Name: "Record Mode", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 1:
 					return "JPEG"
 				case 2:
@@ -596,7 +773,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			11: {Name: "Easy Mode", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Full auto"
 				case 1:
@@ -752,7 +933,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			17: {Name: "Metering Mode", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Default"
 				case 1:
@@ -770,7 +955,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			18: {Name: "Focus Range", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Manual"
 				case 1:
@@ -798,7 +987,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			19: {Name: "AF Point", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "N/A"
 				case 0x2005:
@@ -822,7 +1015,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			20: {Name: "Canon Exposure Mode", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Easy"
 				case 1:
@@ -846,7 +1043,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			32: {Name: "Focus Continuous", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Single"
 				case 1:
@@ -858,7 +1059,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			34: {Name: "Image Stabilization", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Off"
 				case 1:
@@ -888,17 +1093,27 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 	string(GroupCanonShotInfo): {tagDefinitionType: byIndex,
 		subTagDefinitionMap: map[any]*TagDefinition{
 			1: {Name: "Auto ISO", Fn: func(v any) string {
-				data := v.(int64)
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
 				value := math.Exp(float64(data)/32*math.Log(2)) * 100
 				return fmt.Sprintf("%.0f", value)
 			}},
 			2: {Name: "Base ISO", Fn: func(v any) string {
-				data := v.(int64)
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
 				value := math.Exp(float64(data)/32*math.Log(2)) * 100 / 32
 				return fmt.Sprintf("%.0f", value)
 			}},
 			7: {Name: "WhiteBalance", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0:
 					return "Auto"
 				case 1:
@@ -948,14 +1163,21 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			12: {Name: "Camera Temperature", Fn: func(v any) string {
-				data := v.(int64)
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
 				if data == 0 {
 					return "N/A"
 				}
 				return fmt.Sprintf("%d °C", data-128)
 			}},
 			28: {Name: "ND Filter", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case -1:
 					return "n/a"
 				case 0:
@@ -972,7 +1194,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 		subTagDefinitionMap: map[any]*TagDefinition{
 			9: {Name: "Color Temperature"},
 			10: {Name: "Picture Style", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0x00:
 					return "None"
 				case 0x01:
@@ -1029,7 +1255,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 	string(GroupCanonLogInfo): {tagDefinitionType: byIndex,
 		subTagDefinitionMap: map[any]*TagDefinition{
 			4: {Name: "Compression Format", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0x00:
 					return "Editing (ALL-I)"
 				case 0x01:
@@ -1045,7 +1275,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			9: {Name: "ColorSpace2", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0x00:
 					return "BT.709"
 				case 0x01:
@@ -1057,7 +1291,11 @@ var canonSubTagDefinitionMap = map[string]*SubTagDefinition{
 				}
 			}},
 			11: {Name: "Canon Log Version", Fn: func(v any) string {
-				switch v.(int64) {
+				data, ok := v.(int64)
+				if !ok {
+					return fmt.Sprintf("%v", v)
+				}
+				switch data {
 				case 0x00:
 					return "OFF"
 				case 0x01:
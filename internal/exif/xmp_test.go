@@ -0,0 +1,21 @@
+package exif
+
+import (
+	"bytes"
+	"github.com/fukco/media-metadata/internal/manufacturer"
+	"testing"
+)
+
+// TestXMPOfIFD0 the packet of tag 0x02BC is kept for the XMP reader
+func TestXMPOfIFD0(t *testing.T) {
+	packet := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`)
+	ifd0 := []fuzzEntry{{tag: 0x02bc, dataType: DTUndefined, count: uint32(len(packet)), value: packet}}
+	tiff := append([]byte{'I', 'I', 0x2a, 0, 8, 0, 0, 0}, fuzzIFD(8, ifd0)...)
+	exifMeta, err := Process(tiff, false, manufacturer.Unknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(exifMeta.XMP, packet) {
+		t.Fatalf("got %q", exifMeta.XMP)
+	}
+}
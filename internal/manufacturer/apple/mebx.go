@@ -0,0 +1,137 @@
+package apple
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/fukco/media-metadata/internal/box"
+	"io"
+)
+
+const MebxDataFormat = "mebx"
+
+var ErrInvalidMebx = errors.New("invalid mebx sample entry")
+
+// maxTimedSampleSize timed metadata samples are small, larger size comes from corrupted sample table
+const maxTimedSampleSize = 1 << 20
+
+// MebxKey key definition of the timed metadata track, local key id refers to it in every sample
+type MebxKey struct {
+	LocalID   uint32
+	Namespace string
+	Key       string
+	DataType  uint32
+}
+
+// TimedSample decoded timed metadata sample, values are keyed by the key names declared in the sample entry
+type TimedSample struct {
+	// Time presentation time in seconds
+	Time   float64
+	Values map[string]any
+}
+
+// ParseMebxKeys reads key definitions from the sample entries of stsd box
+//
+// mebx sample entry: size(4) type(4) reserved(6) data_reference_index(2) keys box
+// every key of keys box is a box typed by local key id, containing keyd and dtyp boxes
+func ParseMebxKeys(entries []byte) (map[uint32]*MebxKey, error) {
+	if len(entries) < 16 || string(entries[4:8]) != MebxDataFormat {
+		return nil, ErrInvalidMebx
+	}
+	entrySize := int(binary.BigEndian.Uint32(entries[:4]))
+	if entrySize < 16 || entrySize > len(entries) {
+		return nil, ErrInvalidMebx
+	}
+	keys := make(map[uint32]*MebxKey, 8)
+	err := walkBoxes(entries[16:entrySize], func(boxType uint32, payload []byte) error {
+		if box.BoxType(boxType) != box.MetadataItemKeysAtom {
+			return nil
+		}
+		return walkBoxes(payload, func(localID uint32, keyPayload []byte) error {
+			key := &MebxKey{LocalID: localID}
+			err := walkBoxes(keyPayload, func(boxType uint32, data []byte) error {
+				switch fourCC(boxType) {
+				case "keyd":
+					if len(data) < 4 {
+						return ErrInvalidMebx
+					}
+					key.Namespace = string(data[:4])
+					key.Key = string(data[4:])
+				case "dtyp":
+					if len(data) < 8 {
+						return ErrInvalidMebx
+					}
+					// namespace 0 is well-known type
+					if binary.BigEndian.Uint32(data[:4]) == 0 {
+						key.DataType = binary.BigEndian.Uint32(data[4:8])
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			keys[localID] = key
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ReadTimedSample reads a mebx sample, which is a sequence of boxes typed by local key id
+func ReadTimedSample(r io.ReadSeeker, sample *box.Sample, timescale uint32, keys map[uint32]*MebxKey) (*TimedSample, error) {
+	if sample.Size > maxTimedSampleSize {
+		return nil, fmt.Errorf("%w: sample size %d", ErrInvalidMebx, sample.Size)
+	}
+	if _, err := r.Seek(int64(sample.Offset), io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, sample.Size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	timedSample := &TimedSample{Values: make(map[string]any, len(keys))}
+	if timescale > 0 {
+		timedSample.Time = float64(sample.Time) / float64(timescale)
+	}
+	err := walkBoxes(data, func(localID uint32, payload []byte) error {
+		key, ok := keys[localID]
+		if !ok {
+			return nil
+		}
+		value, err := box.DecodeWellKnownType(key.DataType, payload)
+		if err != nil {
+			// keep raw data of custom types
+			value = payload
+		}
+		timedSample.Values[key.Key] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return timedSample, nil
+}
+
+func walkBoxes(data []byte, fn func(boxType uint32, payload []byte) error) error {
+	for i := 0; i+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i : i+4]))
+		if size < 8 || i+size > len(data) {
+			return fmt.Errorf("%w: box size %d at %d", ErrInvalidMebx, size, i)
+		}
+		if err := fn(binary.BigEndian.Uint32(data[i+4:i+8]), data[i+8:i+size]); err != nil {
+			return err
+		}
+		i += size
+	}
+	return nil
+}
+
+func fourCC(v uint32) string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return string(b)
+}
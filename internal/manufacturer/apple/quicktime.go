@@ -0,0 +1,107 @@
+package apple
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuickTime metadata keys, Reference: AVFoundation AVMetadataIdentifier
+const (
+	KeyMake                       = "com.apple.quicktime.make"
+	KeyModel                      = "com.apple.quicktime.model"
+	KeySoftware                   = "com.apple.quicktime.software"
+	KeyCreationDate               = "com.apple.quicktime.creationdate"
+	KeyLocationISO6709            = "com.apple.quicktime.location.ISO6709"
+	KeyLocationAccuracyHorizontal = "com.apple.quicktime.location.accuracy.horizontal"
+	KeyCameraLensModel            = "com.apple.quicktime.camera.lens_model"
+	KeyCameraFocalLength35mm      = "com.apple.quicktime.camera.focal_length.35mm_equivalent"
+	KeyCameraIdentifier           = "com.apple.quicktime.camera.identifier"
+	KeyFullFrameRatePlayback      = "com.apple.quicktime.full-frame-rate-playback-intent"
+	KeyContentIdentifier          = "com.apple.quicktime.content.identifier"
+)
+
+const creationDateLayout = "2006-01-02T15:04:05-0700"
+
+type QuickTimeMeta struct {
+	Make                     string
+	Model                    string
+	Software                 string
+	CreationDate             string
+	LocationISO6709          string
+	LensModel                string
+	FocalLength35mm          float64
+	CameraIdentifier         string
+	FullFrameRatePlayback    bool
+	ContentIdentifier        string
+	HorizontalAccuracyMeters float64
+}
+
+// GetCreationTime parses creation date, which keeps the local timezone of the device
+func (q *QuickTimeMeta) GetCreationTime() *time.Time {
+	if q.CreationDate == "" {
+		return nil
+	}
+	for _, layout := range []string{creationDateLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, q.CreationDate); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// IsApple checks the key values are written by apple devices
+func IsApple(keyValues map[string]any) bool {
+	if value, ok := keyValues[KeyMake].(string); ok {
+		return value == "Apple"
+	}
+	return false
+}
+
+// QuickTimeMetaFromKeyValues collects com.apple.quicktime keys of the moov level metadata
+func QuickTimeMetaFromKeyValues(keyValues map[string]any) *QuickTimeMeta {
+	q := &QuickTimeMeta{
+		Make:              stringValue(keyValues[KeyMake]),
+		Model:             stringValue(keyValues[KeyModel]),
+		Software:          stringValue(keyValues[KeySoftware]),
+		CreationDate:      stringValue(keyValues[KeyCreationDate]),
+		LocationISO6709:   stringValue(keyValues[KeyLocationISO6709]),
+		LensModel:         stringValue(keyValues[KeyCameraLensModel]),
+		CameraIdentifier:  stringValue(keyValues[KeyCameraIdentifier]),
+		ContentIdentifier: stringValue(keyValues[KeyContentIdentifier]),
+	}
+	q.FocalLength35mm, _ = floatValue(keyValues[KeyCameraFocalLength35mm])
+	q.HorizontalAccuracyMeters, _ = floatValue(keyValues[KeyLocationAccuracyHorizontal])
+	if value, ok := floatValue(keyValues[KeyFullFrameRatePlayback]); ok {
+		q.FullFrameRatePlayback = value != 0
+	}
+	if *q == (QuickTimeMeta{}) {
+		return nil
+	}
+	return q
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func floatValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -10,4 +10,15 @@ const (
 	NIKON
 	PANASONIC
 	SONY
+	APPLE
 )
+
+var names = [...]string{"Unknown", "Atomos", "Canon", "Fujifilm", "Nikon", "Panasonic", "Sony", "Apple"}
+
+// Name returns name of manufacturer, not String as Manufacturer is embedded in meta.Metadata
+func (m Manufacturer) Name() string {
+	if int(m) < len(names) {
+		return names[m]
+	}
+	return names[Unknown]
+}
@@ -0,0 +1,49 @@
+package nikon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/fukco/media-metadata/internal/box"
+	"github.com/fukco/media-metadata/internal/exif"
+	"testing"
+)
+
+// fuzzTag NCTG tag of 4-byte ID, 2-byte data format and 2-byte count
+func fuzzTag(id uint32, dataType exif.DataType, count uint16, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, id)
+	b = binary.BigEndian.AppendUint16(b, uint16(dataType))
+	b = binary.BigEndian.AppendUint16(b, count)
+	return append(b, data...)
+}
+
+func FuzzNCTG(f *testing.F) {
+	f.Add(bytes.Join([][]byte{
+		fuzzTag(0x01, exif.DTAscii, 6, []byte("NIKON\x00")),
+		fuzzTag(0x02, exif.DTAscii, 6, []byte("Z 8\x00\x00\x00")),
+		fuzzTag(0x13, exif.DTLong, 1, []byte{0, 0, 0x01, 0x2c}),
+		fuzzTag(0x1013, exif.DTShort, 1, []byte{0, 1}),
+		fuzzTag(0x200001b, exif.DTShort, 7, make([]byte, 14)),
+		fuzzTag(0x2000087, exif.DTByte, 1, []byte{9}),
+		fuzzTag(0x110a432, exif.DTRational, 4, []byte{
+			0, 0, 0, 24, 0, 0, 0, 1, 0, 0, 0, 120, 0, 0, 0, 1,
+			0, 0, 0, 4, 0, 0, 0, 1, 0, 0, 0, 4, 0, 0, 0, 1,
+		}),
+	}, nil))
+	f.Add(fuzzTag(0x13, exif.DTLong, 1, []byte{0, 0}))
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		data := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+		data = append(append(data, "NCTG"...), payload...)
+		r := bytes.NewReader(data)
+		bi, err := box.ReadBoxInfo(r)
+		if err != nil {
+			return
+		}
+		b, err := box.ReadBoxPayload(r, bi, &box.Context{})
+		if err != nil {
+			return
+		}
+		if nctgBox, ok := b.(*box.NCTG); ok {
+			_, _ = ProcessNCTG(nctgBox)
+		}
+	})
+}
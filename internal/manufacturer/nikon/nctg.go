@@ -305,7 +305,7 @@ var flashModeMap = map[byte]string{
 
 func lensInfoFormat(tagData []byte) string {
 	result := ""
-	if len(tagData) == 32 {
+	if len(tagData) == 32 && binary.BigEndian.Uint32(tagData[4:8]) != 0 && binary.BigEndian.Uint32(tagData[12:16]) != 0 {
 		minFocal := binary.BigEndian.Uint32(tagData[:4]) / binary.BigEndian.Uint32(tagData[4:8])
 		maxFocal := binary.BigEndian.Uint32(tagData[8:12]) / binary.BigEndian.Uint32(tagData[12:16])
 		minFVal := &common.UFraction{
@@ -337,21 +337,52 @@ func pictureControlFormat([]byte) string {
 
 }
 
+// nctgTagMinSize min data size of tags decoded with fixed offsets
+var nctgTagMinSize = map[uint32]int{
+	0x13:      4,
+	0x1013:    2,
+	0x1108822: 2,
+	0x1109207: 2,
+	0x2000022: 2,
+	0x200001b: 2,
+	0x20000b1: 2,
+	0x2000087: 1,
+	0x200001f: 7,
+	0x2000023: 55,
+	0x2000025: 12,
+	0x20000b7: 6,
+	0x2000098: 4,
+}
+
+// setNCTGField sets field of the tag name, tags of data format not matching the field are ignored
+func setNCTGField(nctg *NCTG, name string, value any) {
+	field := reflect.ValueOf(nctg).Elem().FieldByName(name)
+	v := reflect.ValueOf(value)
+	switch {
+	case !field.CanSet():
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.CanInt() && field.CanInt():
+		field.SetInt(v.Int())
+	}
+}
+
 func ProcessNCTG(nctgBox *box.NCTG) (*NCTG, error) {
 	nctg := &NCTG{}
 	for _, tag := range nctgBox.Tags {
+		if len(tag.Data) < nctgTagMinSize[tag.ID] {
+			continue
+		}
 		if tagName, ok := nctgNameMap[tag.ID]; ok {
 			if exif.DataType(tag.DataFormat) == exif.DTAscii {
-				refValue := reflect.ValueOf(nctg).Elem()
-				refValue.FieldByName(tagName).SetString(strings.TrimSpace(string(bytes.TrimRight(tag.Data, "\x00"))))
+				setNCTGField(nctg, tagName, strings.TrimSpace(string(bytes.TrimRight(tag.Data, "\x00"))))
 			} else if exif.DataType(tag.DataFormat) == exif.DTLong {
 				switch tag.ID {
 				case 0x13:
 					nctg.FrameCount = int(binary.BigEndian.Uint32(tag.Data[:4]))
 				default:
-					refValue := reflect.ValueOf(nctg).Elem()
 					if len(tag.Data) == 4 {
-						refValue.FieldByName(tagName).SetInt(int64(binary.BigEndian.Uint32(tag.Data)))
+						setNCTGField(nctg, tagName, int64(binary.BigEndian.Uint32(tag.Data)))
 					}
 				}
 			} else if exif.DataType(tag.DataFormat) == exif.DTByte {
@@ -384,9 +415,8 @@ func ProcessNCTG(nctgBox *box.NCTG) (*NCTG, error) {
 				case 0x20000b1:
 					nctg.HighISONoiseReduction = highISONoiseReductionMap[binary.BigEndian.Uint16(tag.Data)]
 				default:
-					refValue := reflect.ValueOf(nctg).Elem()
 					if len(tag.Data) == 2 {
-						refValue.FieldByName(tagName).SetInt(int64(binary.BigEndian.Uint16(tag.Data)))
+						setNCTGField(nctg, tagName, int64(binary.BigEndian.Uint16(tag.Data)))
 					}
 				}
 			} else if exif.DataType(tag.DataFormat) == exif.DTRational {
@@ -396,24 +426,22 @@ func ProcessNCTG(nctgBox *box.NCTG) (*NCTG, error) {
 				case 0x2000084:
 					nctg.Lens = lensInfoFormat(tag.Data)
 				default:
-					refValue := reflect.ValueOf(nctg).Elem()
 					if len(tag.Data) == 8 {
 						uFraction := &common.UFraction{
 							Numerator:   binary.BigEndian.Uint32(tag.Data[:4]),
 							Denominator: binary.BigEndian.Uint32(tag.Data[4:8]),
 						}
-						refValue.FieldByName(tagName).Set(reflect.ValueOf(uFraction))
+						setNCTGField(nctg, tagName, uFraction)
 					}
 				}
 			} else if exif.DataType(tag.DataFormat) == exif.DTSRational {
 				if tag.ID == 0x1109204 {
-					refValue := reflect.ValueOf(nctg).Elem()
 					if len(tag.Data) == 8 {
 						fraction := &common.Rational{
 							Numerator:   int32(binary.BigEndian.Uint32(tag.Data[:4])),
 							Denominator: int32(binary.BigEndian.Uint32(tag.Data[4:8])),
 						}
-						refValue.FieldByName(tagName).Set(reflect.ValueOf(fraction))
+						setNCTGField(nctg, tagName, fraction)
 					}
 				}
 			} else if exif.DataType(tag.DataFormat) == exif.DTUndefined {
@@ -1,57 +1,176 @@
 package panasonic
 
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// ClipMain Reference: urn:schemas-Professional-Plug-in:Semi-Pro:ClipMetadata, also P2Main of P2 clip XML
 type ClipMain struct {
 	ClipContent        ClipContent
 	CameraUnitMetadata CameraUnitMetadata `xml:"UserArea>AcquisitionMetadata>CameraUnitMetadata" json:"CameraUnitMetadata"`
+	LensUnitMetadata   LensUnitMetadata   `xml:"UserArea>AcquisitionMetadata>LensUnitMetadata" json:"LensUnitMetadata"`
+}
+
+// Read reads clip XML like CONTENTS/CLIP/0001AB.XML of P2 card
+func Read(r io.Reader) (*ClipMain, error) {
+	v := &ClipMain{}
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
 }
 
 type ClipContent struct {
 	GlobalClipID string
 	Duration     string
-	Video        Video `xml:"EssenceList>Video"`
+	// EditUnit duration of a frame like 1001/60000
+	EditUnit     string
+	Video        Video   `xml:"EssenceList>Video"`
+	Audio        []Audio `xml:"EssenceList>Audio"`
+	Relation     Relation
 	ClipMetadata ClipMetadata
 }
 
+// Relation shot recorded across P2 cards, clips of the shot share GlobalShotID
+type Relation struct {
+	// OffsetInShot frames of the shot before the clip
+	OffsetInShot int
+	GlobalShotID string
+	Connection   Connection
+}
+
+type Connection struct {
+	Top      ConnectedClip
+	Previous ConnectedClip
+	Next     ConnectedClip
+}
+
+type ConnectedClip struct {
+	GlobalClipID string
+	P2SerialNo   string `xml:"P2SerialNo."`
+}
+
 type Video struct {
-	Codec         Codec
-	ActiveLine    string
-	ActivePixel   string
-	BitDepth      string
-	FrameRate     string
-	StartTimecode string
+	Codec       Codec
+	ActiveLine  string
+	ActivePixel string
+	BitDepth    string
+	// FrameRate like 59.94p or 59.94i
+	FrameRate        string
+	StartTimecode    string
+	StartBinaryGroup string
+	AspectRatio      string
 }
 
 type Codec struct {
 	BitRate string `xml:",attr"`
-	Codec   string `xml:",chardata"`
+	// Class AVC-Intra class like 100
+	Class string `xml:",attr"`
+	Codec string `xml:",chardata"`
+}
+
+// Audio track of the clip, one element of each channel unless Channel is recorded
+type Audio struct {
+	Channel       int
+	SamplingRate  string
+	BitsPerSample string
+}
+
+// Channels audio channels of all tracks
+func (c ClipContent) Channels() int {
+	channels := 0
+	for _, audio := range c.Audio {
+		channels += max(audio.Channel, 1)
+	}
+	return channels
 }
 
 type ClipMetadata struct {
-	Shoot  Shoot
-	Device Device
+	UserClipName string
+	DataSource   string
+	Access       Access
+	Device       Device
+	Shoot        Shoot
+	Scenario     Scenario
+	// ShotMark is true if the clip is marked in camera
+	ShotMark bool
+	Memos    []Memo `xml:"MemoList>Memo"`
+}
+
+type Access struct {
+	Creator        string
+	CreationDate   string
+	LastUpdateDate string
 }
 
 type Shoot struct {
+	Shooter   string
 	StartDate string
+	EndDate   string
+	Location  Location
+}
+
+type Location struct {
+	Altitude  string
+	Longitude string
+	Latitude  string
+	Source    string
+	PlaceName string
+}
+
+type Scenario struct {
+	ProgramName string
+	SceneNo     string `xml:"SceneNo."`
+	TakeNo      string `xml:"TakeNo."`
 }
 
 type Device struct {
 	Manufacturer string
+	SerialNo     string `xml:"SerialNo."`
 	ModelName    string
 }
 
-//type UserArea struct {
-//	AcquisitionMetadata AcquisitionMetadata
-//}
-//
-//type AcquisitionMetadata struct {
-//	CameraUnitMetadata CameraUnitMetadata
-//}
+// Memo text memo at Offset frames of the clip
+type Memo struct {
+	MemoID   string `xml:",attr"`
+	Offset   int
+	Duration int
+	Person   string
+	Text     string
+}
 
 type CameraUnitMetadata struct {
+	// CaptureFrameRate sensor frame rate of VFR recording
+	CaptureFrameRate string
+	// VFR ON if variable frame rate is enabled
+	VFR            string
 	ISOSensitivity string
-	Gamma          Gamma
-	Gamut          Gamut
+	// ExposureIndex EI of dual native ISO cameras
+	ExposureIndex string
+	// CameraMasterGainAdjustment gain in dB
+	CameraMasterGainAdjustment string
+	ShutterSpeedAngle          string
+	ShutterSpeedTime           string
+	WhiteBalance               WhiteBalance
+	// NeutralDensityFilterWheelSetting ND filter like 1/16, CLEAR if not used
+	NeutralDensityFilterWheelSetting string
+	Gamma                            Gamma
+	Gamut                            Gamut
+	LUT                              LUT
+}
+
+// IsVFR reports variable frame rate recording
+func (c CameraUnitMetadata) IsVFR() bool {
+	return strings.EqualFold(c.VFR, "ON")
+}
+
+type WhiteBalance struct {
+	// ColorTemperature in Kelvin like 5600K
+	ColorTemperature string
+	// Tint green-magenta correction, negative to green
+	Tint string
 }
 
 type Gamma struct {
@@ -61,3 +180,22 @@ type Gamma struct {
 type Gamut struct {
 	CaptureGamut string
 }
+
+// LUT look applied to recording or monitoring output
+type LUT struct {
+	LUTName string
+	// Baked is true if LUT is applied to the recording
+	Baked bool
+}
+
+type LensUnitMetadata struct {
+	// LensAttributes lens model
+	LensAttributes string
+	IrisFNumber    string
+	IrisTNumber    string
+	// FocusPositionFromImagePlane focus distance in metres
+	FocusPositionFromImagePlane string
+	// LensZoomActualFocalLength focal length in millimetres
+	LensZoomActualFocalLength         string
+	LensZoom35mmStillCameraEquivalent string
+}
@@ -0,0 +1,83 @@
+package panasonic
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	f, err := os.Open("testdata/ClipMain.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	clipMain, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ClipMain{
+		ClipContent: ClipContent{
+			GlobalClipID: "060A2B340101010501010D4313000000C8D0C02E72050590008045FFFE421E20",
+			Duration:     "1500",
+			EditUnit:     "1001/24000",
+			Video: Video{
+				Codec:            Codec{BitRate: "400", Class: "4K422", Codec: "AVC-I_4K422/23.98p"},
+				ActiveLine:       "2160",
+				ActivePixel:      "4096",
+				BitDepth:         "10",
+				FrameRate:        "23.98p",
+				StartTimecode:    "12:34:56:10",
+				StartBinaryGroup: "00000000",
+				AspectRatio:      "17:9",
+			},
+			Audio: []Audio{{SamplingRate: "48000", BitsPerSample: "24"}, {SamplingRate: "48000", BitsPerSample: "24"}},
+			Relation: Relation{
+				GlobalShotID: "060A2B340101010501010D4313000000C8D0C02E72050590008045FFFE421E21",
+				Connection: Connection{
+					Top:  ConnectedClip{GlobalClipID: "060A2B340101010501010D4313000000C8D0C02E72050590008045FFFE421E20", P2SerialNo: "AAA0123B4567"},
+					Next: ConnectedClip{GlobalClipID: "060A2B340101010501010D4313000000C8D0C02E72050590008045FFFE421E22", P2SerialNo: "AAA0123B4568"},
+				},
+			},
+			ClipMetadata: ClipMetadata{
+				UserClipName: "Interview",
+				DataSource:   "SHOOTING",
+				Access:       Access{Creator: "Operator", CreationDate: "2024-05-01T09:59:58+09:00", LastUpdateDate: "2024-05-01T10:01:00+09:00"},
+				Device:       Device{Manufacturer: "Panasonic", SerialNo: "C1TA12345", ModelName: "AU-EVA1"},
+				Shoot:        Shoot{Shooter: "Operator", StartDate: "2024-05-01T10:00:00+09:00", EndDate: "2024-05-01T10:01:02+09:00"},
+				Scenario:     Scenario{ProgramName: "Documentary", SceneNo: "12", TakeNo: "3"},
+				ShotMark:     true,
+				Memos:        []Memo{{MemoID: "1", Offset: 240, Person: "Director", Text: "laugh"}, {MemoID: "2", Offset: 960}},
+			},
+		},
+		CameraUnitMetadata: CameraUnitMetadata{
+			CaptureFrameRate:                 "59.94",
+			VFR:                              "ON",
+			ISOSensitivity:                   "800",
+			ExposureIndex:                    "2500",
+			CameraMasterGainAdjustment:       "6dB",
+			ShutterSpeedAngle:                "180.0",
+			WhiteBalance:                     WhiteBalance{ColorTemperature: "5600K", Tint: "-3.5"},
+			NeutralDensityFilterWheelSetting: "1/16",
+			Gamma:                            Gamma{CaptureGamma: "V-Log"},
+			Gamut:                            Gamut{CaptureGamut: "V-Gamut"},
+			LUT:                              LUT{LUTName: "VLOG_709"},
+		},
+		LensUnitMetadata: LensUnitMetadata{
+			LensAttributes:                    "LUMIX G X VARIO 12-35mm F2.8",
+			IrisFNumber:                       "2.8",
+			FocusPositionFromImagePlane:       "3.2",
+			LensZoomActualFocalLength:         "25",
+			LensZoom35mmStillCameraEquivalent: "50",
+		},
+	}
+	if !reflect.DeepEqual(clipMain, want) {
+		t.Errorf("Read = %+v, want %+v", clipMain, want)
+	}
+	if channels := clipMain.ClipContent.Channels(); channels != 2 {
+		t.Errorf("Channels = %d, want 2", channels)
+	}
+	if !clipMain.CameraUnitMetadata.IsVFR() {
+		t.Error("IsVFR = false")
+	}
+}
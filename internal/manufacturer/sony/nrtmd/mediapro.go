@@ -0,0 +1,97 @@
+package nrtmd
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+)
+
+// MediaProfile MEDIAPRO.XML of PRIVATE/M4ROOT or XDROOT, index of clips on the card
+type MediaProfile struct {
+	CreatedAt  string `xml:"createdAt,attr"`
+	Version    string `xml:"version,attr"`
+	Properties MediaProperties
+	Materials  []Material `xml:"Contents>Material"`
+}
+
+type MediaProperties struct {
+	System   MediaSystem
+	Attached MediaAttached
+}
+
+type MediaSystem struct {
+	SystemId   string `xml:"systemId,attr"`
+	SystemKind string `xml:"systemKind,attr"`
+}
+
+type MediaAttached struct {
+	MediaId   string `xml:"mediaId,attr"`
+	MediaKind string `xml:"mediaKind,attr"`
+	MediaName string `xml:"mediaName,attr"`
+}
+
+// Material clip of the card, URIs are relative to M4ROOT or XDROOT like ./CLIP/C0001.MP4
+type Material struct {
+	Uri          string         `xml:"uri,attr"`
+	Type         string         `xml:"type,attr"`
+	VideoType    string         `xml:"videoType,attr"`
+	AudioType    string         `xml:"audioType,attr"`
+	Fps          string         `xml:"fps,attr"`
+	Dur          int            `xml:"dur,attr"`
+	Ch           int            `xml:"ch,attr"`
+	AspectRatio  string         `xml:"aspectRatio,attr"`
+	Umid         string         `xml:"umid,attr"`
+	Proxies      []Proxy        `xml:"Proxy"`
+	RelevantInfo []RelevantInfo `xml:"RelevantInfo"`
+}
+
+type Proxy struct {
+	Uri       string `xml:"uri,attr"`
+	Type      string `xml:"type,attr"`
+	VideoType string `xml:"videoType,attr"`
+	AudioType string `xml:"audioType,attr"`
+	Fps       string `xml:"fps,attr"`
+	Ch        int    `xml:"ch,attr"`
+}
+
+// RelevantInfo file of the clip, XML for NonRealTimeMeta sidecar, JPG for thumbnail
+type RelevantInfo struct {
+	Uri  string `xml:"uri,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// RelevantFile URI of the first relevant file of type
+func (m Material) RelevantFile(fileType string) string {
+	for _, info := range m.RelevantInfo {
+		if info.Type == fileType {
+			return info.Uri
+		}
+	}
+	return ""
+}
+
+func ReadMediaProfile(r io.Reader) (*MediaProfile, error) {
+	profile := &MediaProfile{}
+	if err := xml.NewDecoder(r).Decode(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func Read(r io.Reader) (*NonRealTimeMeta, error) {
+	v := &NonRealTimeMeta{}
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Merge fills elements missing in m with those of other, like the sidecar of a clip whose embedded XML is incomplete
+func (m *NonRealTimeMeta) Merge(other *NonRealTimeMeta) {
+	target, source := reflect.ValueOf(m).Elem(), reflect.ValueOf(other).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		if target.Field(i).IsZero() {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+}
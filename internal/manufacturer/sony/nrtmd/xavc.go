@@ -0,0 +1,163 @@
+package nrtmd
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// XAVCFormat recording format decoded from videoCodec of VideoFrame like AVC_3840_2160_HP@L51
+type XAVCFormat struct {
+	// Name file format and resolution as in camera menu like XAVC HS 4K
+	Name string
+	// Family XAVC S, XAVC HS or XAVC S-I
+	Family string
+	// Codec AVC or HEVC
+	Codec string
+	// Profile H.264 or H.265 profile like High 4:2:2 Intra
+	Profile string
+	Level   string `json:",omitempty"`
+	Width   int
+	Height  int
+	// Resolution like HD, 4K, DCI 4K or 8K of the long side
+	Resolution string
+	// Vertical is true for clips recorded in portrait orientation
+	Vertical bool `json:",omitempty"`
+	Chroma   string
+	BitDepth int
+	// GOP Long GOP or Intra
+	GOP string
+}
+
+type xavcProfile struct {
+	codec    string
+	code     string
+	name     string
+	family   string
+	chroma   string
+	bitDepth int
+	intra    bool
+}
+
+// xavcProfiles profiles of videoCodec
+// https://en.wikipedia.org/wiki/Advanced_Video_Coding#Profiles
+// https://en.wikipedia.org/wiki/High_Efficiency_Video_Coding#Profiles
+var xavcProfiles = []xavcProfile{
+	{"AVC", "BP", "Baseline", "XAVC S", "4:2:0", 8, false},
+	{"AVC", "MP", "Main", "XAVC S", "4:2:0", 8, false},
+	{"AVC", "HP", "High", "XAVC S", "4:2:0", 8, false},
+	{"AVC", "H10P", "High 10", "XAVC S", "4:2:0", 10, false},
+	{"AVC", "H422P", "High 4:2:2", "XAVC S", "4:2:2", 10, false},
+	{"AVC", "H10IP", "High 10 Intra", "XAVC S-I", "4:2:0", 10, true},
+	{"AVC", "H422IP", "High 4:2:2 Intra", "XAVC S-I", "4:2:2", 10, true},
+	{"AVC", "H444IP", "High 4:4:4 Intra", "XAVC S-I", "4:4:4", 12, true},
+	{"HEVC", "MP", "Main", "XAVC HS", "4:2:0", 8, false},
+	{"HEVC", "M10P", "Main 10", "XAVC HS", "4:2:0", 10, false},
+	{"HEVC", "M42210P", "Main 4:2:2 10", "XAVC HS", "4:2:2", 10, false},
+	{"HEVC", "M42210IP", "Main 4:2:2 10 Intra", "XAVC HS", "4:2:2", 10, true},
+}
+
+// xavcResolutions names of resolution by the minimum width of the long side, in descending order
+var xavcResolutions = []struct {
+	width int
+	name  string
+}{
+	{7680, "8K"},
+	{5760, "6K"},
+	{4096, "DCI 4K"},
+	{3840, "4K"},
+	{2048, "2K"},
+	{1280, "HD"},
+	{0, "SD"},
+}
+
+// intraClasses bitrate classes of XAVC Intra, Mbps at 30 fps
+var intraClasses = []float64{50, 100, 200, 300, 480}
+
+// longGOPBitrates bitrates of record settings of Long GOP formats in Mbps
+var longGOPBitrates = []float64{6, 9, 16, 25, 35, 45, 50, 60, 75, 100, 140, 150, 200, 280, 400, 520, 600}
+
+// XAVC decodes videoCodec of the clip, size of VideoLayout is used if videoCodec has none
+func (f VideoFormat) XAVC() (*XAVCFormat, bool) {
+	codec, level, _ := strings.Cut(f.VideoFrame.VideoCodec, "@")
+	parts := strings.Split(codec, "_")
+	if len(parts) < 2 {
+		return nil, false
+	}
+	code := parts[len(parts)-1]
+	var profile *xavcProfile
+	for i := range xavcProfiles {
+		if xavcProfiles[i].codec == parts[0] && xavcProfiles[i].code == code {
+			profile = &xavcProfiles[i]
+			break
+		}
+	}
+	if profile == nil {
+		return nil, false
+	}
+	format := &XAVCFormat{
+		Family:   profile.family,
+		Codec:    profile.codec,
+		Profile:  profile.name,
+		Level:    xavcLevel(level),
+		Chroma:   profile.chroma,
+		BitDepth: profile.bitDepth,
+		GOP:      "Long GOP",
+	}
+	if profile.intra {
+		format.GOP = "Intra"
+	}
+	if len(parts) == 4 {
+		format.Width, _ = strconv.Atoi(parts[1])
+		format.Height, _ = strconv.Atoi(parts[2])
+	}
+	if format.Width == 0 || format.Height == 0 {
+		format.Width, _ = strconv.Atoi(f.VideoLayout.Pixel)
+		format.Height, _ = strconv.Atoi(f.VideoLayout.NumOfVerticalLine)
+	}
+	format.Vertical = format.Height > format.Width
+	long := max(format.Width, format.Height)
+	if long > 0 {
+		for _, resolution := range xavcResolutions {
+			if long >= resolution.width {
+				format.Resolution = resolution.name
+				break
+			}
+		}
+	}
+	format.Name = strings.TrimSpace(format.Family + " " + format.Resolution)
+	return format, true
+}
+
+// xavcLevel formats level like L51 as 5.1
+func xavcLevel(level string) string {
+	level = strings.TrimPrefix(level, "L")
+	if len(level) == 2 && !strings.Contains(level, ".") {
+		return level[:1] + "." + level[1:]
+	}
+	return level
+}
+
+// BitrateClass record setting of average bitrate in kbps, class like Class 300 of Intra formats, which is the bitrate
+// at 30 fps, or nominal bitrate like 100M of Long GOP formats. Empty if bitrate or frame rate is unknown
+func (f *XAVCFormat) BitrateClass(bitrateKbps uint32, fps float64) string {
+	if bitrateKbps == 0 || fps <= 0 {
+		return ""
+	}
+	mbps := float64(bitrateKbps) / 1000
+	if f.GOP == "Intra" {
+		return "Class " + strconv.FormatFloat(nearest(intraClasses, mbps*30/fps), 'f', -1, 64)
+	}
+	return strconv.FormatFloat(nearest(longGOPBitrates, mbps), 'f', -1, 64) + "M"
+}
+
+// nearest value of values by ratio, average bitrate is below the nominal one
+func nearest(values []float64, value float64) float64 {
+	result := values[0]
+	for _, v := range values {
+		if math.Abs(math.Log(v/value)) < math.Abs(math.Log(result/value)) {
+			result = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package nrtmd
+
+import "testing"
+
+func TestXAVC(t *testing.T) {
+	tests := []struct {
+		codec  string
+		pixel  string
+		lines  string
+		want   XAVCFormat
+		kbps   uint32
+		fps    float64
+		class  string
+		failed bool
+	}{
+		{codec: "AVC_3840_2160_HP@L51", kbps: 98000, fps: 25, class: "100M",
+			want: XAVCFormat{"XAVC S 4K", "XAVC S", "AVC", "High", "5.1", 3840, 2160, "4K", false, "4:2:0", 8, "Long GOP"}},
+		{codec: "AVC_3840_2160_H422P@L51", kbps: 136000, fps: 25, class: "140M",
+			want: XAVCFormat{"XAVC S 4K", "XAVC S", "AVC", "High 4:2:2", "5.1", 3840, 2160, "4K", false, "4:2:2", 10, "Long GOP"}},
+		{codec: "AVC_3840_2160_H422IP@L51", kbps: 250000, fps: 25, class: "Class 300",
+			want: XAVCFormat{"XAVC S-I 4K", "XAVC S-I", "AVC", "High 4:2:2 Intra", "5.1", 3840, 2160, "4K", false, "4:2:2", 10, "Intra"}},
+		{codec: "AVC_1920_1080_HP@L42", kbps: 49000, fps: 50, class: "50M",
+			want: XAVCFormat{"XAVC S HD", "XAVC S", "AVC", "High", "4.2", 1920, 1080, "HD", false, "4:2:0", 8, "Long GOP"}},
+		{codec: "AVC_1920_1080_H422IP@L42", kbps: 185000, fps: 59.94, class: "Class 100",
+			want: XAVCFormat{"XAVC S-I HD", "XAVC S-I", "AVC", "High 4:2:2 Intra", "4.2", 1920, 1080, "HD", false, "4:2:2", 10, "Intra"}},
+		{codec: "HEVC_3840_2160_M10P@L51", kbps: 48000, fps: 23.976, class: "50M",
+			want: XAVCFormat{"XAVC HS 4K", "XAVC HS", "HEVC", "Main 10", "5.1", 3840, 2160, "4K", false, "4:2:0", 10, "Long GOP"}},
+		{codec: "HEVC_3840_2160_M42210P@L51", kbps: 195000, fps: 119.88, class: "200M",
+			want: XAVCFormat{"XAVC HS 4K", "XAVC HS", "HEVC", "Main 4:2:2 10", "5.1", 3840, 2160, "4K", false, "4:2:2", 10, "Long GOP"}},
+		{codec: "HEVC_4096_2160_M42210P@L52",
+			want: XAVCFormat{"XAVC HS DCI 4K", "XAVC HS", "HEVC", "Main 4:2:2 10", "5.2", 4096, 2160, "DCI 4K", false, "4:2:2", 10, "Long GOP"}},
+		{codec: "HEVC_7680_4320_M42210P@L61",
+			want: XAVCFormat{"XAVC HS 8K", "XAVC HS", "HEVC", "Main 4:2:2 10", "6.1", 7680, 4320, "8K", false, "4:2:2", 10, "Long GOP"}},
+		{codec: "HEVC_2160_3840_M10P@L51",
+			want: XAVCFormat{"XAVC HS 4K", "XAVC HS", "HEVC", "Main 10", "5.1", 2160, 3840, "4K", true, "4:2:0", 10, "Long GOP"}},
+		{codec: "AVC_H422IP@L52", pixel: "6048", lines: "4032", kbps: 600000, fps: 24, class: "Class 480",
+			want: XAVCFormat{"XAVC S-I 6K", "XAVC S-I", "AVC", "High 4:2:2 Intra", "5.2", 6048, 4032, "6K", false, "4:2:2", 10, "Intra"}},
+		{codec: "AVC_H422IP", want: XAVCFormat{"XAVC S-I", "XAVC S-I", "AVC", "High 4:2:2 Intra", "", 0, 0, "", false, "4:2:2", 10, "Intra"}},
+		{codec: "MPEG2HD422_1920_1080_422P@HL", failed: true},
+		{codec: "AVC", failed: true},
+		{codec: "", failed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.codec, func(t *testing.T) {
+			f := VideoFormat{VideoFrame: VideoFrame{VideoCodec: tt.codec}, VideoLayout: VideoLayout{Pixel: tt.pixel, NumOfVerticalLine: tt.lines}}
+			got, ok := f.XAVC()
+			if ok == tt.failed {
+				t.Fatalf("XAVC ok = %v", ok)
+			}
+			if tt.failed {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("XAVC = %+v, want %+v", *got, tt.want)
+			}
+			if class := got.BitrateClass(tt.kbps, tt.fps); class != tt.class {
+				t.Errorf("BitrateClass = %q, want %q", class, tt.class)
+			}
+		})
+	}
+}
@@ -1,22 +1,45 @@
 package nrtmd
 
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// names of AcquisitionRecord groups
+const (
+	LensUnitMetadataSet      = "LensUnitMetadataSet"
+	CameraUnitMetadataSet    = "CameraUnitMetadataSet"
+	SonyF65CameraMetadataSet = "SonyF65CameraMetadataSet"
+)
+
 const (
-	CameraUnitMetadataSet = "CameraUnitMetadataSet"
 	CaptureGammaEquation  = "CaptureGammaEquation"
 	CaptureColorPrimaries = "CaptureColorPrimaries"
 )
 
+// NonRealTimeMeta Reference: urn:schemas-professionalDisc:nonRealTimeMeta
 type NonRealTimeMeta struct {
+	LastUpdate        string `xml:"lastUpdate,attr"`
+	TargetMaterial    TargetMaterial
 	Duration          Duration
+	LtcChangeTable    LtcChangeTable
 	CreationDate      CreationDate
-	Device            Device
+	KeyFrames         []KeyFrame `xml:"KeyFrame"`
 	VideoFormat       VideoFormat
+	AudioFormat       AudioFormat
 	SubStream         SubStream
+	Device            Device
+	Lens              Lens
 	RecordingMode     RecordingMode
+	KlvPacketTable    KlvPacketTable
 	AcquisitionRecord AcquisitionRecord
 	RelevantFiles     RelevantFiles
 }
 
+type TargetMaterial struct {
+	UmidRef string `xml:"umidRef,attr"`
+}
+
 type Duration struct {
 	Value string `xml:"value,attr"`
 }
@@ -25,9 +48,77 @@ type CreationDate struct {
 	Value string `xml:"value,attr"`
 }
 
+// LtcChangeTable LTC of frames where it does not increment from the previous frame, the first change is the start timecode
+type LtcChangeTable struct {
+	TcFps int `xml:"tcFps,attr"`
+	// HalfStep timecode of 50p and 60p counts frame pairs
+	HalfStep   bool        `xml:"halfStep,attr"`
+	LtcChanges []LtcChange `xml:"LtcChange"`
+}
+
+type LtcChange struct {
+	FrameCount int `xml:"frameCount,attr"`
+	// Value SMPTE 12M BCD of frames, seconds, minutes and hours like 00000001 for 01:00:00:00
+	Value string `xml:"value,attr"`
+	// Status increment, end, or another status if timecode does not increment
+	Status string `xml:"status,attr"`
+}
+
+// Timecode timecode of LtcChange
+type Timecode struct {
+	Hour      int
+	Min       int
+	Sec       int
+	Frame     int
+	DropFrame bool
+}
+
+// Timecode parses Value, drop frame is bit 6 of frames
+func (c LtcChange) Timecode() (Timecode, bool) {
+	b, err := hex.DecodeString(c.Value)
+	if err != nil || len(b) != 4 {
+		return Timecode{}, false
+	}
+	digits := [4]byte{b[0] & 0x3f, b[1] & 0x7f, b[2] & 0x7f, b[3] & 0x3f}
+	for _, d := range digits {
+		if d&0x0f > 9 || d>>4 > 9 {
+			return Timecode{}, false
+		}
+	}
+	bcd := func(d byte) int {
+		return int(d>>4)*10 + int(d&0x0f)
+	}
+	return Timecode{
+		Hour:      bcd(digits[3]),
+		Min:       bcd(digits[2]),
+		Sec:       bcd(digits[1]),
+		Frame:     bcd(digits[0]),
+		DropFrame: b[0]&0x40 != 0,
+	}, true
+}
+
+// StartTimecode timecode of the first frame
+func (t LtcChangeTable) StartTimecode() (Timecode, bool) {
+	for _, change := range t.LtcChanges {
+		if change.FrameCount == 0 {
+			return change.Timecode()
+		}
+	}
+	return Timecode{}, false
+}
+
+type KeyFrame struct {
+	FrameCount int `xml:"frameCount,attr"`
+}
+
 type VideoFormat struct {
-	VideoFrame  VideoFrame
-	VideoLayout VideoLayout
+	VideoRecPort VideoRecPort
+	VideoFrame   VideoFrame
+	VideoLayout  VideoLayout
+}
+
+type VideoRecPort struct {
+	Port string `xml:"port,attr"`
 }
 
 type VideoFrame struct {
@@ -36,12 +127,30 @@ type VideoFrame struct {
 	FormatFps  string `xml:"formatFps,attr"`
 }
 
+// Interlaced is true for format frame rate like 50i
+func (f VideoFrame) Interlaced() bool {
+	return strings.HasSuffix(f.FormatFps, "i")
+}
+
 type VideoLayout struct {
 	Pixel             string `xml:"pixel,attr"`
 	NumOfVerticalLine string `xml:"numOfVerticalLine,attr"`
 	AspectRatio       string `xml:"aspectRatio,attr"`
 }
 
+type AudioFormat struct {
+	NumOfChannel  int            `xml:"numOfChannel,attr"`
+	AudioRecPorts []AudioRecPort `xml:"AudioRecPort"`
+}
+
+// AudioRecPort input of an audio track
+type AudioRecPort struct {
+	Port       string `xml:"port,attr"`
+	AudioCodec string `xml:"audioCodec,attr"`
+	// TrackDst track of the clip like CH1
+	TrackDst string `xml:"trackDst,attr"`
+}
+
 type SubStream struct {
 	Codec string `xml:"codec,attr"`
 }
@@ -57,8 +166,72 @@ type Device struct {
 	SerialNo     string `xml:"serialNo,attr"`
 }
 
+type Lens struct {
+	ModelName string `xml:"modelName,attr"`
+}
+
+type KlvPacketTable struct {
+	KlvPackets []KlvPacket `xml:"KlvPacket"`
+}
+
+// KlvPacket packet of a frame, shot marks are packets of text _ShotMark1 and _ShotMark2
+type KlvPacket struct {
+	Key        string `xml:"key,attr"`
+	FrameCount int    `xml:"frameCount,attr"`
+	// LengthValue hex of BER length and value
+	LengthValue string `xml:"lengthValue,attr"`
+	Status      string `xml:"status,attr"`
+}
+
+// Text value of packet, empty if it is not printable
+func (p KlvPacket) Text() string {
+	b, err := hex.DecodeString(p.LengthValue)
+	if err != nil || len(b) == 0 || int(b[0]) != len(b)-1 {
+		return ""
+	}
+	for _, c := range b[1:] {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return string(b[1:])
+}
+
+// ShotMark shot mark of a frame
+type ShotMark struct {
+	FrameCount int
+	// Name _ShotMark1 or _ShotMark2
+	Name string
+}
+
+func (t KlvPacketTable) ShotMarks() []ShotMark {
+	var marks []ShotMark
+	for _, packet := range t.KlvPackets {
+		if text := packet.Text(); strings.HasPrefix(text, "_ShotMark") {
+			marks = append(marks, ShotMark{FrameCount: packet.FrameCount, Name: text})
+		}
+	}
+	return marks
+}
+
 type AcquisitionRecord struct {
-	Groups []Group `xml:"Group"`
+	Groups       []Group       `xml:"Group"`
+	ChangeTables []ChangeTable `xml:"ChangeTable"`
+}
+
+// Item value of item in group
+func (r AcquisitionRecord) Item(group, name string) (string, bool) {
+	for _, g := range r.Groups {
+		if g.Name != group {
+			continue
+		}
+		for _, item := range g.Items {
+			if item.Name == name {
+				return item.Value, true
+			}
+		}
+	}
+	return "", false
 }
 
 type Group struct {
@@ -71,6 +244,17 @@ type GroupItem struct {
 	Value string `xml:"value,attr"`
 }
 
+// ChangeTable frames where recording of a kind of metadata like LensControlInformation starts or changes
+type ChangeTable struct {
+	Name   string  `xml:"name,attr"`
+	Events []Event `xml:"Event"`
+}
+
+type Event struct {
+	FrameCount int    `xml:"frameCount,attr"`
+	Status     string `xml:"status,attr"`
+}
+
 type RelevantFiles struct {
 	RelatedTo []RelatedTo `xml:"RelatedTo"`
 }
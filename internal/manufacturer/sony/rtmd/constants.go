@@ -61,6 +61,63 @@ func (mode AutoWhiteBalanceMode) String() string {
 	}
 }
 
+type ColorCorrectionFilter byte
+
+func (filter ColorCorrectionFilter) String() string {
+	switch filter {
+	case 0x00:
+		return "Cross effect"
+	case 0x01:
+		return "CC 3200K"
+	case 0x02:
+		return "CC 4300K"
+	case 0x03:
+		return "CC 6300K"
+	case 0x04:
+		return "CC 5600K"
+	case 0xff:
+		return "Undefined"
+	default:
+		return "Reserved"
+	}
+}
+
+type ImageSensorReadoutMode byte
+
+func (mode ImageSensorReadoutMode) String() string {
+	switch mode {
+	case 0x00:
+		return "Interlaced field"
+	case 0x01:
+		return "Interlaced frame"
+	case 0x02:
+		return "Progressive frame"
+	case 0xff:
+		return "Undefined"
+	default:
+		return "Reserved"
+	}
+}
+
+type GammaForCDL byte
+
+func (gamma GammaForCDL) String() string {
+	switch gamma {
+	case 0x00:
+		return "Same as capture gamma"
+	case 0x01:
+		return "Scene linear"
+	case 0x02:
+		return "S-Log"
+	case 0x03:
+		return "Cine-Log"
+	case 0xff:
+		return "Undefined"
+	default:
+		return "Reserved"
+	}
+}
+
 type LightingPreset byte
 
 func (mode LightingPreset) String() string {
@@ -0,0 +1,51 @@
+package rtmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// fuzzSet local set of 2-byte tag and 2-byte length with the set key and BER length of 0x83 form
+func fuzzSet(key string, tags map[uint16][]byte) []byte {
+	var content []byte
+	for code, data := range tags {
+		content = binary.BigEndian.AppendUint16(content, code)
+		content = binary.BigEndian.AppendUint16(content, uint16(len(data)))
+		content = append(content, data...)
+	}
+	set, _ := hex.DecodeString(key)
+	set = append(set, 0x83, 0x00)
+	set = binary.BigEndian.AppendUint16(set, uint16(len(content)))
+	return append(set, content...)
+}
+
+func FuzzReadRTMD(f *testing.F) {
+	frameHeader := append([]byte{0x00, 0x1c, 0x01, 0x00}, make([]byte, 24)...)
+	frame := bytes.Join([][]byte{
+		frameHeader,
+		fuzzSet(LensUnitMetadataHex, map[uint16][]byte{
+			0x8000: {0x10, 0x00},
+			0x8004: {0x01, 0x90},
+		}),
+		fuzzSet(CameraUnitMetadataHex, map[uint16][]byte{
+			0x8106: {0x00, 0x00, 0x75, 0x30, 0x00, 0x00, 0x03, 0xe9},
+			0x810b: {0x03, 0x20},
+		}),
+		fuzzSet(UserDefinedAcquisitionMetadataHex, map[uint16][]byte{
+			0xe300: {0x00},
+		}),
+		make([]byte, 20),
+	}, nil)
+	f.Add(frame, uint32(len(frame)))
+	f.Add(frame, uint32(0))
+	f.Add(frame[:len(frame)/2], uint32(len(frame)))
+	f.Fuzz(func(t *testing.T, data []byte, sampleSize uint32) {
+		rtmd, err := ReadRTMD(bytes.NewReader(data), sampleSize, 0)
+		if err != nil {
+			return
+		}
+		_ = rtmd.RawTags()
+	})
+}
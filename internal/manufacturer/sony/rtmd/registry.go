@@ -0,0 +1,172 @@
+package rtmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TagType value type of a registered tag
+type TagType uint8
+
+const (
+	TagUInt8 TagType = iota + 1
+	TagUInt16
+	TagInt16
+	TagUInt32
+	TagRational
+	TagFloat16
+	TagBoolean
+	TagString
+	// TagBCDDateTime BCD year, month, day, hour, minute and second after a leading byte
+	TagBCDDateTime
+	TagBytes
+)
+
+var tagTypeSize = map[TagType]int{
+	TagUInt8: 1, TagUInt16: 2, TagInt16: 2, TagUInt32: 4, TagRational: 8, TagFloat16: 2, TagBoolean: 1, TagBCDDateTime: 8,
+}
+
+// TagDefinition declares a local tag decoded without code, numeric values are multiplied by Scale if it is not 0
+type TagDefinition struct {
+	Set   SetType
+	Code  uint16
+	Name  string
+	Type  TagType
+	Unit  string
+	Scale float64
+}
+
+// TagValue value of a registered tag
+type TagValue struct {
+	Value any
+	Unit  string `json:",omitempty"`
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[SetType]map[code]*TagDefinition)
+)
+
+// builtinTags tags whose meaning is known but not used by other fields
+var builtinTags = []TagDefinition{
+	{Set: UserDefinedAcquisitionMetadataSet, Code: 0xe104, Name: "RotaryShutterMode", Type: TagBoolean},
+}
+
+func init() {
+	for _, definition := range builtinTags {
+		RegisterTag(definition)
+	}
+}
+
+// RegisterTag adds or replaces the decoder of tag, tags decoded into typed fields are not affected
+func RegisterTag(definition TagDefinition) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if registry[definition.Set] == nil {
+		registry[definition.Set] = make(map[code]*TagDefinition)
+	}
+	registry[definition.Set][code(definition.Code)] = &definition
+}
+
+func lookupTag(set SetType, c code) *TagDefinition {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return registry[set][c]
+}
+
+// decodeRegisteredTag returns false if tag is not registered or value is too short for its type
+func (rtmd *RTMD) decodeRegisteredTag(set SetType, t *tag) bool {
+	definition := lookupTag(set, t.code)
+	if definition == nil || len(t.data) < tagTypeSize[definition.Type] {
+		return false
+	}
+	if rtmd.Tags == nil {
+		rtmd.Tags = make(map[string]*TagValue)
+	}
+	rtmd.Tags[definition.Name] = &TagValue{Value: definition.decode(t.data), Unit: definition.Unit}
+	return true
+}
+
+func (definition *TagDefinition) decode(raw rawData) any {
+	scale := func(value float64) any {
+		if definition.Scale != 0 {
+			return value * definition.Scale
+		}
+		return value
+	}
+	switch definition.Type {
+	case TagUInt8:
+		return scale(float64(raw[0]))
+	case TagUInt16:
+		return scale(float64(raw.BigEndianUint16()))
+	case TagInt16:
+		return scale(float64(int16(raw.BigEndianUint16())))
+	case TagUInt32:
+		return scale(float64(raw.BigEndianUint32()))
+	case TagRational:
+		rational := raw.Rational()
+		if rational.Denominator == 0 {
+			return nil
+		}
+		return scale(float64(rational.Numerator) / float64(rational.Denominator))
+	case TagFloat16:
+		return scale(raw.Float16())
+	case TagBoolean:
+		return raw[0] != 0
+	case TagString:
+		return strings.TrimRight(string(raw), string(byte(0)))
+	case TagBCDDateTime:
+		return fmt.Sprintf("%02x%02x-%02x-%02x %02x:%02x:%02x", raw[1], raw[2], raw[3], raw[4], raw[5], raw[6], raw[7])
+	default:
+		return strings.ToUpper(hex.EncodeToString(raw))
+	}
+}
+
+// RawTag local tag without decoder
+type RawTag struct {
+	// Set UL in the header of the metadata set, sets with unrecognized UL are kept as well
+	Set string
+	// SetID identifier of user defined set from tag 0xE000
+	SetID  string `json:",omitempty"`
+	Code   string
+	Length int
+	Hex    string
+}
+
+func newRawTag(ul string, setID []byte, t *tag) *RawTag {
+	return &RawTag{
+		Set:    ul,
+		SetID:  strings.ToUpper(hex.EncodeToString(setID)),
+		Code:   fmt.Sprintf("%04X", uint16(t.code)),
+		Length: len(t.data),
+		Hex:    strings.ToUpper(hex.EncodeToString(t.data)),
+	}
+}
+
+// RawTags tags of all sets without decoder, in the order of sets
+func (rtmd *RTMD) RawTags() []*RawTag {
+	var tags []*RawTag
+	if rtmd.LensUnitMetadata != nil {
+		for _, t := range rtmd.LensUnitMetadata.unKnownTags {
+			tags = append(tags, newRawTag(LensUnitMetadataHex, nil, t))
+		}
+	}
+	if rtmd.CameraUnitMetadata != nil {
+		for _, t := range rtmd.CameraUnitMetadata.unKnownTags {
+			tags = append(tags, newRawTag(CameraUnitMetadataHex, nil, t))
+		}
+	}
+	for _, set := range rtmd.userDefinedAcquisitionMetadataUnKnownSlice {
+		for _, t := range set.tags {
+			tags = append(tags, newRawTag(UserDefinedAcquisitionMetadataHex, set.id, t))
+		}
+	}
+	for _, set := range rtmd.unknownSets {
+		for _, t := range set.tags {
+			tags = append(tags, newRawTag(set.ul, nil, t))
+		}
+	}
+	return tags
+}
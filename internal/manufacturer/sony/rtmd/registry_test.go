@@ -0,0 +1,33 @@
+package rtmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRawTagsOfUnknownSet(t *testing.T) {
+	const unknownUL = "060e2b34025301010c0201017f7f0000"
+	frame := bytes.Join([][]byte{
+		append([]byte{0x00, 0x1c, 0x01, 0x00}, make([]byte, 24)...),
+		fuzzSet(CameraUnitMetadataHex, map[uint16][]byte{0x8119: {0x03, 0x20}}),
+		fuzzSet(unknownUL, map[uint16][]byte{0x8000: {0x10, 0x00}}),
+		make([]byte, 20),
+	}, nil)
+	rtmd, err := ReadRTMD(bytes.NewReader(frame), uint32(len(frame)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rtmd.LensUnitMetadata.IrisFNumber != 0 {
+		t.Errorf("tag of unknown set decoded as lens tag: %v", rtmd.LensUnitMetadata.IrisFNumber)
+	}
+	tags := rtmd.RawTags()
+	if len(tags) != 2 {
+		t.Fatalf("got %d raw tags, want 2", len(tags))
+	}
+	if tags[0].Set != CameraUnitMetadataHex || tags[0].Code != "8119" || tags[0].Hex != "0320" {
+		t.Errorf("camera tag: %+v", tags[0])
+	}
+	if tags[1].Set != unknownUL || tags[1].Code != "8000" || tags[1].Length != 2 || tags[1].Hex != "1000" {
+		t.Errorf("unknown set tag: %+v", tags[1])
+	}
+}
@@ -9,33 +9,63 @@ import (
 	"github.com/fukco/media-metadata/internal/common"
 	"io"
 	"math"
+	"strings"
+	"time"
 )
 
-type metadataSetType uint8
+// SetType metadata set of local tags
+type SetType uint8
 
 const (
-	lensUnitMetadataSet metadataSetType = iota + 1
-	cameraUnitMetadataSet
-	userDefinedAcquisitionMetadataSet
+	LensUnitMetadataSet SetType = iota + 1
+	CameraUnitMetadataSet
+	UserDefinedAcquisitionMetadataSet
 )
 
+// UL universal label of the metadata set
+func (t SetType) UL() string {
+	switch t {
+	case LensUnitMetadataSet:
+		return LensUnitMetadataHex
+	case CameraUnitMetadataSet:
+		return CameraUnitMetadataHex
+	case UserDefinedAcquisitionMetadataSet:
+		return UserDefinedAcquisitionMetadataHex
+	}
+	return ""
+}
+
 // RTMD Reference: EBU TECH-3349
 type RTMD struct {
-	Timecode                                   *Timecode
-	LensUnitMetadata                           *LensUnitMetadata
-	CameraUnitMetadata                         *CameraUnitMetadata
-	UserDefinedAcquisitionMetadata             *UserDefinedAcquisitionMetadata
+	Timecode                       *Timecode
+	LensUnitMetadata               *LensUnitMetadata
+	CameraUnitMetadata             *CameraUnitMetadata
+	UserDefinedAcquisitionMetadata *UserDefinedAcquisitionMetadata
+	// Tags values of tags decoded by the tag registry, by tag name
+	Tags map[string]*TagValue `json:",omitempty"`
+	// UnknownTags tags without decoder, only set in raw mode
+	UnknownTags                                []*RawTag `json:",omitempty"`
 	userDefinedAcquisitionMetadataUnKnownSlice []*UserDefinedAcquisitionMetadataUnknown
+	// unknownSets sets whose UL is not recognized, all of their tags are kept
+	unknownSets []*unknownSet
 }
 
 type LensUnitMetadata struct {
 	IrisFNumber                 float64
+	IrisTNumber                 float64
+	IrisRingPosition            uint16
 	FocusPositionFromImagePlane float64
+	FocusPositionFromFrontLens  float64
 	FocusRingPosition           uint16
+	MacroSetting                bool
 	LensZoom35mmPtr             float64
 	LensZoomPtr                 float64
 	ZoomRingPosition            uint16
-	unKnownTags                 []*tag
+	// OpticalExtenderMagnification 2 for 2x extender
+	OpticalExtenderMagnification float64
+	// LensAttributes model name of lens
+	LensAttributes string
+	unKnownTags    []*tag
 }
 
 type CameraUnitMetadata struct {
@@ -56,7 +86,30 @@ type CameraUnitMetadata struct {
 	CaptureGammaEquation            string
 	ColorPrimaries                  string
 	CodingEquations                 string
-	unKnownTags                     []*tag
+	ColorCorrectionFilter           string
+	// NDFilter attenuation of ND filter wheel, 1 is clear and 64 is ND 1/64. Variable ND of FX6 and FX9 changes per frame
+	NDFilter               uint16
+	ImageSensorReadoutMode string
+	// CameraMasterBlackLevel, CameraKneePoint and CameraLuminanceDynamicRange in percent of video level
+	CameraMasterBlackLevel      float64
+	CameraKneePoint             float64
+	CameraKneeSlope             *common.Rational
+	CameraLuminanceDynamicRange float64
+	CameraSettingFileURI        string
+	// GammaForCDL gamma which ASC CDL is applied to
+	GammaForCDL string
+	ASCCDL      *ASCCDL
+	// ColorMatrix 3x3 matrix in row-major order
+	ColorMatrix []float64
+	unKnownTags []*tag
+}
+
+// ASCCDL ASC CDL v1.2 values of red, green and blue
+type ASCCDL struct {
+	Slope      [3]float64
+	Offset     [3]float64
+	Power      [3]float64
+	Saturation float64
 }
 
 type UserDefinedAcquisitionMetadata struct {
@@ -65,20 +118,12 @@ type UserDefinedAcquisitionMetadata struct {
 	PreCDLTransform                 string
 	LightingPreset                  string
 	ImageStabilizerEnabled          bool
-}
-
-type Timecode struct {
-	Hour  int
-	Min   int
-	Sec   int
-	Frame int
-}
-
-func (t Timecode) String() string {
-	if t.Frame < 10 {
-		return fmt.Sprintf("%2d:%2d:%2d:%2d", t.Hour, t.Min, t.Sec, t.Frame)
-	}
-	return fmt.Sprintf("%2d:%2d:%2d:%d", t.Hour, t.Min, t.Sec, t.Frame)
+	// CookeBinaryMetadata hex of the Cooke /i binary frame, the layout is not decoded until verified with captured frames
+	CookeBinaryMetadata  string
+	CookeUserMetadata    string
+	CookeCalibrationType string
+	// CurrentRecordDateTime camera clock of the frame in seconds, the time zone is not recorded
+	CurrentRecordDateTime *time.Time `json:",omitempty"`
 }
 
 type UserDefinedAcquisitionMetadataUnknown struct {
@@ -86,6 +131,11 @@ type UserDefinedAcquisitionMetadataUnknown struct {
 	tags []*tag
 }
 
+type unknownSet struct {
+	ul   string
+	tags []*tag
+}
+
 type MetadataSetInfo struct {
 	name       string
 	bodyOffset int64
@@ -102,7 +152,7 @@ type tag struct {
 type code uint16
 
 func (t code) String() string {
-	return fmt.Sprintf("%#04X", t)
+	return fmt.Sprintf("%#04X", uint16(t))
 }
 
 func (data rawData) BigEndianUint16() uint16 {
@@ -113,6 +163,66 @@ func (data rawData) BigEndianUint32() uint32 {
 	return binary.BigEndian.Uint32(data)
 }
 
+// Iris F or T number of 16-bit value, 2^(8*(1-value/0x10000))
+func (data rawData) Iris() float64 {
+	return math.Pow(2, (1-float64(binary.BigEndian.Uint16(data))/0x10000)*8)
+}
+
+// Text string of printable value, otherwise hex
+func (data rawData) Text() string {
+	text := strings.TrimRight(string(data), string(byte(0)))
+	for _, c := range []byte(text) {
+		if c < 0x20 || c > 0x7e {
+			return strings.ToUpper(hex.EncodeToString(data))
+		}
+	}
+	return text
+}
+
+// Float16 IEEE 754 half precision
+func (data rawData) Float16() float64 {
+	bits := binary.BigEndian.Uint16(data)
+	sign := 1.0
+	if bits&0x8000 != 0 {
+		sign = -1
+	}
+	exponent := int(bits >> 10 & 0x1f)
+	fraction := float64(bits & 0x3ff)
+	switch exponent {
+	case 0:
+		return sign * fraction / 1024 * math.Pow(2, -14)
+	case 0x1f:
+		if fraction != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * (1 + fraction/1024) * math.Pow(2, float64(exponent-15))
+}
+
+// Rational numerator and denominator of 32-bit signed integers
+func (data rawData) Rational() *common.Rational {
+	return &common.Rational{
+		Numerator:   int32(binary.BigEndian.Uint32(data[:4])),
+		Denominator: int32(binary.BigEndian.Uint32(data[4:])),
+	}
+}
+
+// Array elements of MXF array, the 8-byte header of element count and size is skipped if present
+func (data rawData) Array(count, size int) []rawData {
+	if len(data) >= 8+count*size && int(binary.BigEndian.Uint32(data[:4])) == count && int(binary.BigEndian.Uint32(data[4:8])) == size {
+		data = data[8:]
+	}
+	if len(data) < count*size {
+		return nil
+	}
+	elements := make([]rawData, count)
+	for i := range elements {
+		elements[i] = data[i*size : (i+1)*size]
+	}
+	return elements
+}
+
 func (data rawData) CommonDistanceFormat() float64 {
 	u := binary.BigEndian.Uint16(data)
 	e := int8(u>>8&0xf0) >> 4
@@ -120,7 +230,7 @@ func (data rawData) CommonDistanceFormat() float64 {
 	return float64(m) * math.Pow10(int(e))
 }
 
-func ReadRTMD(r io.ReadSeeker, sampleSize uint32, offset uint32) (*RTMD, error) {
+func ReadRTMD(r io.ReadSeeker, sampleSize uint32, offset uint64) (*RTMD, error) {
 	_, err := r.Seek(int64(offset), 0)
 	if err != nil {
 		return nil, err
@@ -132,19 +242,14 @@ func ReadRTMD(r io.ReadSeeker, sampleSize uint32, offset uint32) (*RTMD, error)
 	}
 	frameHeader := buf.Next(28)
 	n += 28
-	if binary.BigEndian.Uint32(frameHeader[:4]) != 0x001c0100 {
+	if !bytes.Equal(frameHeader[:4], frameHeaderSignature) {
 		return nil, fmt.Errorf("not RTMD tag")
 	}
 	rtmd := &RTMD{
-		Timecode: &Timecode{
-			Hour:  int(frameHeader[13]),
-			Min:   int(frameHeader[14]),
-			Sec:   int(frameHeader[15]),
-			Frame: int(binary.BigEndian.Uint16(frameHeader[16:18])),
-		},
-		LensUnitMetadata:                           &LensUnitMetadata{unKnownTags: make([]*tag, 0, 8)},
-		CameraUnitMetadata:                         &CameraUnitMetadata{unKnownTags: make([]*tag, 0, 8)},
-		UserDefinedAcquisitionMetadata:             &UserDefinedAcquisitionMetadata{},
+		Timecode:                       parseTimecode(frameHeader),
+		LensUnitMetadata:               &LensUnitMetadata{unKnownTags: make([]*tag, 0, 8)},
+		CameraUnitMetadata:             &CameraUnitMetadata{unKnownTags: make([]*tag, 0, 8)},
+		UserDefinedAcquisitionMetadata: &UserDefinedAcquisitionMetadata{},
 		userDefinedAcquisitionMetadataUnKnownSlice: make([]*UserDefinedAcquisitionMetadataUnknown, 0, 16),
 	}
 
@@ -160,16 +265,19 @@ func ReadRTMD(r io.ReadSeeker, sampleSize uint32, offset uint32) (*RTMD, error)
 		if hex.EncodeToString(header[:4]) != "060e2b34" {
 			break
 		}
-		var dataSetType metadataSetType
-		switch hex.EncodeToString(header[:16]) {
+		var dataSetType SetType
+		ul := hex.EncodeToString(header[:16])
+		switch ul {
 		case LensUnitMetadataHex:
-			dataSetType = lensUnitMetadataSet
+			dataSetType = LensUnitMetadataSet
 		case CameraUnitMetadataHex:
-			dataSetType = cameraUnitMetadataSet
+			dataSetType = CameraUnitMetadataSet
 		case UserDefinedAcquisitionMetadataHex:
-			dataSetType = userDefinedAcquisitionMetadataSet
+			dataSetType = UserDefinedAcquisitionMetadataSet
 			rtmd.userDefinedAcquisitionMetadataUnKnownSlice = append(rtmd.userDefinedAcquisitionMetadataUnKnownSlice,
 				&UserDefinedAcquisitionMetadataUnknown{tags: make([]*tag, 0, 16)})
+		default:
+			rtmd.unknownSets = append(rtmd.unknownSets, &unknownSet{ul: ul})
 		}
 		length := binary.BigEndian.Uint16(header[18:])
 
@@ -177,36 +285,56 @@ func ReadRTMD(r io.ReadSeeker, sampleSize uint32, offset uint32) (*RTMD, error)
 			return nil, err
 		}
 		content := buf.Next(int(length))
+		n += int(length)
 
 		for i := 0; i < int(length); {
+			if i+4 > int(length) {
+				return nil, fmt.Errorf("%w: truncated tag header at %d", ErrInvalidRTMD, i)
+			}
 			myTag := &tag{}
 			myTag.code = code(binary.BigEndian.Uint16(content[i : i+2]))
 			size := int(binary.BigEndian.Uint16(content[i+2 : i+4]))
+			if i+4+size > int(length) {
+				return nil, fmt.Errorf("%w: tag %s size %d exceeds set length %d", ErrInvalidRTMD, myTag.code, size, length)
+			}
 
 			tagData := make([]byte, size)
 			copy(tagData, content[i+4:i+4+size])
 			myTag.data = tagData
 			i += size + 4
+			if dataSetType == 0 {
+				unknown := rtmd.unknownSets[len(rtmd.unknownSets)-1]
+				unknown.tags = append(unknown.tags, myTag)
+				continue
+			}
+			if size < tagMinSize[myTag.code] {
+				// value too short to decode
+				continue
+			}
+			var err error
+			switch dataSetType {
+			case LensUnitMetadataSet:
+				err = myTag.code.processLensUnitMetadata(rtmd, myTag.data)
+			case CameraUnitMetadataSet:
+				err = myTag.code.processCameraUnitMetadata(rtmd, myTag.data)
+			case UserDefinedAcquisitionMetadataSet:
+				err = myTag.code.processUserDefinedAcquisitionMetadata(rtmd, myTag.data)
+			}
+			if !errors.Is(err, ErrNotMatchedTag) {
+				continue
+			}
+			// tags without built-in decoder
+			if rtmd.decodeRegisteredTag(dataSetType, myTag) {
+				continue
+			}
 			switch dataSetType {
-			case lensUnitMetadataSet:
-				err := myTag.code.processLensUnitMetadata(rtmd, myTag.data)
-				if errors.Is(err, ErrNotMatchedTag) {
-					rtmd.LensUnitMetadata.unKnownTags = append(rtmd.LensUnitMetadata.unKnownTags, myTag)
-				}
-			case cameraUnitMetadataSet:
-				err := myTag.code.processCameraUnitMetadata(rtmd, myTag.data)
-				if errors.Is(err, ErrNotMatchedTag) {
-					//fmt.Printf("%X %s\n", int(myTag.code), hex.EncodeToString(myTag.data))
-					rtmd.CameraUnitMetadata.unKnownTags = append(rtmd.CameraUnitMetadata.unKnownTags, myTag)
-				}
-			case userDefinedAcquisitionMetadataSet:
-				err := myTag.code.processUserDefinedAcquisitionMetadata(rtmd, myTag.data)
-				if errors.Is(err, ErrNotMatchedTag) {
-					//fmt.Printf("%X %s\n", int(myTag.code), hex.EncodeToString(myTag.data))
-					unKnownTags := rtmd.userDefinedAcquisitionMetadataUnKnownSlice[len(rtmd.userDefinedAcquisitionMetadataUnKnownSlice)-1].tags
-					unKnownTags = append(unKnownTags, myTag)
-					rtmd.userDefinedAcquisitionMetadataUnKnownSlice[len(rtmd.userDefinedAcquisitionMetadataUnKnownSlice)-1].tags = unKnownTags
-				}
+			case LensUnitMetadataSet:
+				rtmd.LensUnitMetadata.unKnownTags = append(rtmd.LensUnitMetadata.unKnownTags, myTag)
+			case CameraUnitMetadataSet:
+				rtmd.CameraUnitMetadata.unKnownTags = append(rtmd.CameraUnitMetadata.unKnownTags, myTag)
+			case UserDefinedAcquisitionMetadataSet:
+				unknown := rtmd.userDefinedAcquisitionMetadataUnKnownSlice[len(rtmd.userDefinedAcquisitionMetadataUnKnownSlice)-1]
+				unknown.tags = append(unknown.tags, myTag)
 			}
 		}
 
@@ -0,0 +1,62 @@
+package rtmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// maxScanSize limits bytes of mdat scanned for RTMD sample
+const maxScanSize = 64 << 20
+
+const scanBlockSize = 1 << 20
+
+var ErrRTMDNotFound = errors.New("RTMD not found")
+
+var (
+	frameHeaderSignature = []byte{0x00, 0x1c, 0x01, 0x00}
+	setKeySignature      = []byte{0x06, 0x0e, 0x2b, 0x34}
+)
+
+// Scan searches the first RTMD sample between start and end by the signature of frame header followed by a metadata set key,
+// used when sample table is not available like a clip not finalized by the camera
+func Scan(r io.ReadSeeker, start uint64, end uint64) (uint64, error) {
+	if end-start > maxScanSize {
+		end = start + maxScanSize
+	}
+	// keep the tail of previous block, signature may cross block boundary
+	overlap := 28 + len(setKeySignature) - 1
+	buf := make([]byte, 0, scanBlockSize+overlap)
+	bufOffset := start
+	block := make([]byte, scanBlockSize)
+	if _, err := r.Seek(int64(start), io.SeekStart); err != nil {
+		return 0, err
+	}
+	for pos := start; pos < end; {
+		n, err := io.ReadFull(r, block[:min(uint64(len(block)), end-pos)])
+		buf = append(buf, block[:n]...)
+		pos += uint64(n)
+		for i := 0; ; {
+			j := bytes.Index(buf[i:], frameHeaderSignature)
+			if j < 0 || i+j+28+len(setKeySignature) > len(buf) {
+				break
+			}
+			i += j
+			if bytes.Equal(buf[i+28:i+28+len(setKeySignature)], setKeySignature) {
+				return bufOffset + uint64(i), nil
+			}
+			i++
+		}
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return 0, err
+		}
+		if len(buf) > overlap {
+			bufOffset += uint64(len(buf) - overlap)
+			buf = append(buf[:0], buf[len(buf)-overlap:]...)
+		}
+	}
+	return 0, ErrRTMDNotFound
+}
@@ -4,12 +4,21 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
-	"github.com/fukco/media-metadata/internal/common"
-	"math"
 	"strings"
 )
 
-var ErrNotMatchedTag = errors.New("not matched tag")
+var (
+	ErrNotMatchedTag = errors.New("not matched tag")
+	ErrInvalidRTMD   = errors.New("invalid RTMD")
+)
+
+// tagMinSize min value size of tags decoded with fixed size
+var tagMinSize = map[code]int{
+	0x8000: 2, 0x8001: 2, 0x8002: 2, 0x8003: 1, 0x8004: 2, 0x8005: 2, 0x8006: 2, 0x8008: 2, 0x8009: 2, 0x800a: 2, 0x800b: 2,
+	0x8101: 1, 0x8102: 1, 0x8103: 2, 0x8104: 2, 0x8105: 2, 0x8106: 8, 0x8107: 1, 0x8108: 4, 0x8109: 8, 0x810a: 2, 0x810b: 2,
+	0x810c: 2, 0x810d: 1, 0x810e: 2, 0x810f: 2, 0x8110: 2, 0x8111: 8, 0x8112: 2, 0x8115: 2, 0x8116: 1, 0x8117: 20, 0x8118: 72,
+	0xe300: 1, 0xe303: 1, 0xe304: 8,
+}
 
 const (
 	LensUnitMetadataHex               = "060e2b34025301010c02010101010000"
@@ -33,14 +42,26 @@ const (
 func (t code) processLensUnitMetadata(rtmd *RTMD, raw rawData) error {
 	switch t {
 	case 0x8000:
-		data := raw.BigEndianUint16()
-		rtmd.LensUnitMetadata.IrisFNumber = math.Pow(2, (1-float64(data)/0x10000)*8)
+		rtmd.LensUnitMetadata.IrisFNumber = raw.Iris()
 	case 0x8001:
 		rtmd.LensUnitMetadata.FocusPositionFromImagePlane = raw.CommonDistanceFormat()
+	case 0x8002:
+		rtmd.LensUnitMetadata.FocusPositionFromFrontLens = raw.CommonDistanceFormat()
+	case 0x8003:
+		rtmd.LensUnitMetadata.MacroSetting = raw[0] != 0
 	case 0x8004:
 		rtmd.LensUnitMetadata.LensZoom35mmPtr = raw.CommonDistanceFormat()
 	case 0x8005:
 		rtmd.LensUnitMetadata.LensZoomPtr = raw.CommonDistanceFormat()
+	case 0x8006:
+		// in percent
+		rtmd.LensUnitMetadata.OpticalExtenderMagnification = float64(raw.BigEndianUint16()) / 100
+	case 0x8007:
+		rtmd.LensUnitMetadata.LensAttributes = strings.TrimRight(string(raw), string(byte(0)))
+	case 0x8008:
+		rtmd.LensUnitMetadata.IrisTNumber = raw.Iris()
+	case 0x8009:
+		rtmd.LensUnitMetadata.IrisRingPosition = raw.BigEndianUint16()
 	case 0x800a:
 		rtmd.LensUnitMetadata.FocusRingPosition = raw.BigEndianUint16()
 	case 0x800b:
@@ -99,18 +120,18 @@ func (t code) processCameraUnitMetadata(rtmd *RTMD, raw rawData) error {
 		rtmd.CameraUnitMetadata.ImagerDimensionWidth = raw.BigEndianUint16()
 	case 0x8105:
 		rtmd.CameraUnitMetadata.ImagerDimensionHeight = raw.BigEndianUint16()
+	case 0x8102:
+		rtmd.CameraUnitMetadata.ColorCorrectionFilter = ColorCorrectionFilter(raw[0]).String()
+	case 0x8103:
+		rtmd.CameraUnitMetadata.NDFilter = raw.BigEndianUint16()
 	case 0x8106:
-		rtmd.CameraUnitMetadata.CaptureFrameRate = &common.Rational{
-			Numerator:   int32(binary.BigEndian.Uint32(raw[:4])),
-			Denominator: int32(binary.BigEndian.Uint32(raw[4:])),
-		}
+		rtmd.CameraUnitMetadata.CaptureFrameRate = raw.Rational()
+	case 0x8107:
+		rtmd.CameraUnitMetadata.ImageSensorReadoutMode = ImageSensorReadoutMode(raw[0]).String()
 	case 0x8108:
 		rtmd.CameraUnitMetadata.ShutterSpeedAngle = float64(raw.BigEndianUint32()) / 60
 	case 0x8109:
-		rtmd.CameraUnitMetadata.ShutterSpeedTime = &common.Rational{
-			Numerator:   int32(binary.BigEndian.Uint32(raw[:4])),
-			Denominator: int32(binary.BigEndian.Uint32(raw[4:])),
-		}
+		rtmd.CameraUnitMetadata.ShutterSpeedTime = raw.Rational()
 	case 0x810a:
 		rtmd.CameraUnitMetadata.CameraMasterGainAdjustment = float64(int16(binary.BigEndian.Uint16(raw)) / 100)
 	case 0x810b:
@@ -121,10 +142,50 @@ func (t code) processCameraUnitMetadata(rtmd *RTMD, raw rawData) error {
 		rtmd.CameraUnitMetadata.AutoWhiteBalanceMode = AutoWhiteBalanceMode(raw[0]).String()
 	case 0x810e:
 		rtmd.CameraUnitMetadata.WhiteBalance = raw.BigEndianUint16()
+	// percent values in units of 0.1%
+	case 0x810f:
+		rtmd.CameraUnitMetadata.CameraMasterBlackLevel = float64(int16(raw.BigEndianUint16())) / 10
+	case 0x8110:
+		rtmd.CameraUnitMetadata.CameraKneePoint = float64(raw.BigEndianUint16()) / 10
+	case 0x8111:
+		rtmd.CameraUnitMetadata.CameraKneeSlope = raw.Rational()
+	case 0x8112:
+		rtmd.CameraUnitMetadata.CameraLuminanceDynamicRange = float64(raw.BigEndianUint16()) / 10
+	case 0x8113:
+		rtmd.CameraUnitMetadata.CameraSettingFileURI = strings.TrimRight(string(raw), string(byte(0)))
 	case 0x8114:
 		rtmd.CameraUnitMetadata.CameraAttributes = strings.TrimRight(string(raw), string(byte(0)))
 	case 0x8115:
 		rtmd.CameraUnitMetadata.ExposureIndexOfPhotoMeter = raw.BigEndianUint16()
+	case 0x8116:
+		rtmd.CameraUnitMetadata.GammaForCDL = GammaForCDL(raw[0]).String()
+	case 0x8117:
+		// slope, offset and power of red, green and blue, then saturation
+		values := raw.Array(10, 2)
+		if values == nil {
+			return nil
+		}
+		cdl := &ASCCDL{Saturation: values[9].Float16()}
+		for i := 0; i < 3; i++ {
+			cdl.Slope[i] = values[i].Float16()
+			cdl.Offset[i] = values[3+i].Float16()
+			cdl.Power[i] = values[6+i].Float16()
+		}
+		rtmd.CameraUnitMetadata.ASCCDL = cdl
+	case 0x8118:
+		values := raw.Array(9, 8)
+		if values == nil {
+			return nil
+		}
+		matrix := make([]float64, 0, len(values))
+		for _, value := range values {
+			if rational := value.Rational(); rational.Denominator != 0 {
+				matrix = append(matrix, float64(rational.Numerator)/float64(rational.Denominator))
+			} else {
+				matrix = append(matrix, 0)
+			}
+		}
+		rtmd.CameraUnitMetadata.ColorMatrix = matrix
 	default:
 		return ErrNotMatchedTag
 	}
@@ -167,10 +228,23 @@ func (t code) processUserDefinedAcquisitionMetadata(rtmd *RTMD, raw rawData) err
 		rtmd.UserDefinedAcquisitionMetadata.MonitoringDescriptions = strings.TrimRight(string(raw), string(byte(0)))
 	case 0xe113:
 		rtmd.UserDefinedAcquisitionMetadata.PreCDLTransform = strings.TrimRight(string(raw), string(byte(0)))
+	case 0x8007:
+		// also recorded in the user defined set
+		if rtmd.LensUnitMetadata.LensAttributes == "" {
+			rtmd.LensUnitMetadata.LensAttributes = strings.TrimRight(string(raw), string(byte(0)))
+		}
+	case 0xe201:
+		rtmd.UserDefinedAcquisitionMetadata.CookeBinaryMetadata = strings.ToUpper(hex.EncodeToString(raw))
+	case 0xe202:
+		rtmd.UserDefinedAcquisitionMetadata.CookeUserMetadata = strings.TrimRight(string(raw), string(byte(0)))
+	case 0xe203:
+		rtmd.UserDefinedAcquisitionMetadata.CookeCalibrationType = raw.Text()
 	case 0xe300:
 		rtmd.UserDefinedAcquisitionMetadata.ImageStabilizerEnabled = raw[0]&1 == 0
 	case 0xe303:
 		rtmd.UserDefinedAcquisitionMetadata.LightingPreset = LightingPreset(raw[0]).String()
+	case 0xe304:
+		rtmd.UserDefinedAcquisitionMetadata.CurrentRecordDateTime = parseRecordDateTime(raw)
 	default:
 		return ErrNotMatchedTag
 	}
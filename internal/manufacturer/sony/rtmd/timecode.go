@@ -0,0 +1,74 @@
+package rtmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Timecode timecode of the frame header
+type Timecode struct {
+	Hour  int
+	Min   int
+	Sec   int
+	Frame int
+	// DropFrame is not recorded in the frame header, it is set from the LTC change table of NRT XML for the whole track
+	DropFrame bool `json:",omitempty"`
+}
+
+// String formats timecode like 01:00:00:00, frames of drop frame timecode are separated by ;
+func (t Timecode) String() string {
+	separator := ":"
+	if t.DropFrame {
+		separator = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", t.Hour, t.Min, t.Sec, separator, t.Frame)
+}
+
+// FrameCount frames since 00:00:00:00 at timecode base like 30 of 29.97 fps, frame numbers dropped by drop frame timecode
+// are not counted
+func (t Timecode) FrameCount(base int) int {
+	frames := ((t.Hour*60+t.Min)*60+t.Sec)*base + t.Frame
+	if t.DropFrame {
+		// 2 frame numbers of 30 fps, 4 of 60 fps, are dropped every minute except every tenth minute
+		minutes := t.Hour*60 + t.Min
+		frames -= base / 15 * (minutes - minutes/10)
+	}
+	return frames
+}
+
+func isBCD(value byte) bool {
+	return value&0x0f <= 9 && value>>4 <= 9
+}
+
+func fromBCD(value byte) int {
+	return int(value>>4)*10 + int(value&0x0f)
+}
+
+// parseTimecode reads binary hours, minutes and seconds at 13-15 and 16-bit frames at 16-17 of the frame header.
+// Every frame is read the same way, so the encoding does not change within a track
+func parseTimecode(header []byte) *Timecode {
+	return &Timecode{
+		Hour:  int(header[13]),
+		Min:   int(header[14]),
+		Sec:   int(header[15]),
+		Frame: int(binary.BigEndian.Uint16(header[16:18])),
+	}
+}
+
+// parseRecordDateTime reads BCD year, month, day, hour, minute and second after a leading byte,
+// camera clock without time zone is returned as UTC
+func parseRecordDateTime(raw rawData) *time.Time {
+	for _, value := range raw[1:8] {
+		if !isBCD(value) {
+			return nil
+		}
+	}
+	year := fromBCD(raw[1])*100 + fromBCD(raw[2])
+	month, day := fromBCD(raw[3]), fromBCD(raw[4])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return nil
+	}
+	t := time.Date(year, time.Month(month), day, fromBCD(raw[5]), fromBCD(raw[6]), fromBCD(raw[7]), 0, time.UTC)
+	return &t
+}
@@ -19,14 +19,36 @@ func getMediaFormat(file *os.File) Format {
 }
 
 func isSupportExtension(file *os.File) bool {
-	if strings.EqualFold(filepath.Ext(file.Name()), string(Mp4Extension)) ||
-		strings.EqualFold(filepath.Ext(file.Name()), string(MovExtension)) ||
-		strings.EqualFold(filepath.Ext(file.Name()), string(NRAWExtension)) {
+	return isSupportFileName(file.Name())
+}
+
+func isSupportFileName(name string) bool {
+	if strings.EqualFold(filepath.Ext(name), string(Mp4Extension)) ||
+		strings.EqualFold(filepath.Ext(name), string(MovExtension)) ||
+		strings.EqualFold(filepath.Ext(name), string(NRAWExtension)) {
 		return true
 	}
 	return false
 }
 
+// ListMediaFiles walks the folder and returns paths of files with supported extension
+func ListMediaFiles(dir string) ([]string, error) {
+	paths := make([]string, 0, 16)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && isSupportFileName(d.Name()) && !strings.HasPrefix(d.Name(), ".") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
 // IsSupportMediaFile check file is support media file
 func IsSupportMediaFile(file *os.File) bool {
 	if !isSupportExtension(file) {
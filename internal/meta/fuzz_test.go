@@ -0,0 +1,40 @@
+package meta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testBox(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, boxType...), data...)
+}
+
+func testFullBox(boxType string, payload ...[]byte) []byte {
+	return testBox(boxType, append([][]byte{{0, 0, 0, 0}}, payload...)...)
+}
+
+func FuzzRead(f *testing.F) {
+	ftyp := testBox("ftyp", []byte("qt  \x00\x00\x02\x00qt  "))
+	keys := testFullBox("keys", []byte{0, 0, 0, 1}, testBox("mdta", []byte("com.apple.quicktime.make")))
+	ilst := testBox("ilst", testBox("\x00\x00\x00\x01", testBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte("Apple"))))
+	meta := testBox("meta", testFullBox("hdlr", make([]byte, 4), []byte("mdta"), make([]byte, 13)), keys, ilst)
+	stbl := testBox("stbl",
+		testFullBox("stsz", []byte{0, 0, 0, 16, 0, 0, 0, 1}),
+		testFullBox("stco", []byte{0, 0, 0, 1, 0, 0, 0, 0}))
+	trak := testBox("trak", testBox("mdia",
+		testFullBox("mdhd", make([]byte, 20)),
+		testFullBox("hdlr", make([]byte, 4), []byte("meta"), make([]byte, 13)),
+		testBox("minf", stbl)))
+	moov := testBox("moov", testFullBox("mvhd", make([]byte, 96)), trak, meta)
+	f.Add(append(ftyp, moov...))
+	f.Add(append(append(ftyp, moov...), testBox("mdat", make([]byte, 16))...))
+	f.Add(append(ftyp, moov[:len(moov)/2]...))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range []Options{{}, {Recover: true}} {
+			_, _ = ReadWithOptions(bytes.NewReader(data), opts)
+		}
+	})
+}
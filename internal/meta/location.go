@@ -0,0 +1,50 @@
+package meta
+
+import (
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
+)
+
+const (
+	LocationSourceQuickTime = "QuickTime " + apple.KeyLocationISO6709
+	LocationSourceUdta      = "udta ©xyz"
+	LocationSourceExif      = "Exif GPS"
+)
+
+// resolveLocation picks location by priority: QuickTime metadata key, udta ©xyz atom, Exif GPS IFD
+func resolveLocation(metadata *Metadata) *common.Location {
+	var location *common.Location
+	if value, ok := metadata.MetaItemKeyValues[apple.KeyLocationISO6709].(string); ok {
+		if l, err := common.ParseISO6709(value); err == nil {
+			location = l
+			location.Source = LocationSourceQuickTime
+		}
+	}
+	if location == nil && metadata.Mp4Meta != nil && metadata.Mp4Meta.LocationISO6709 != "" {
+		if l, err := common.ParseISO6709(metadata.Mp4Meta.LocationISO6709); err == nil {
+			location = l
+			location.Source = LocationSourceUdta
+		}
+	}
+	if location == nil && metadata.ExifMeta != nil && metadata.ExifMeta.GPS != nil {
+		gps := metadata.ExifMeta.GPS
+		return &common.Location{
+			Latitude:  gps.Latitude,
+			Longitude: gps.Longitude,
+			Altitude:  gps.Altitude,
+			Heading:   gps.ImgDirection,
+			Timestamp: gps.Timestamp,
+			Source:    LocationSourceExif,
+		}
+	}
+	if location == nil {
+		return nil
+	}
+	if metadata.MakerMeta.Apple != nil && metadata.MakerMeta.Apple.QuickTime != nil {
+		location.Timestamp = metadata.MakerMeta.Apple.QuickTime.GetCreationTime()
+	}
+	if location.Timestamp == nil && metadata.Mp4Meta != nil {
+		location.Timestamp = metadata.Mp4Meta.CreationTime
+	}
+	return location
+}
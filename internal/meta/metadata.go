@@ -1,12 +1,19 @@
 package meta
 
 import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/box"
+	"github.com/fukco/media-metadata/internal/card"
+	"github.com/fukco/media-metadata/internal/common"
 	"github.com/fukco/media-metadata/internal/exif"
 	"github.com/fukco/media-metadata/internal/manufacturer"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
 	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
 	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"github.com/fukco/media-metadata/internal/mxf"
+	"github.com/fukco/media-metadata/internal/xmp"
 	"time"
 )
 
@@ -18,17 +25,49 @@ type Metadata struct {
 	MetaItemKeyValues map[string]any
 	*exif.ExifMeta
 	*MakerMeta
+	Location *common.Location `json:",omitempty"`
+	XMP      *xmp.XMP         `json:",omitempty"`
+	// Status completeness of file, only set in recovery mode
+	Status *FileStatus `json:",omitempty"`
+}
+
+// FileStatus completeness of file read in recovery mode, the DIT can flag clips not finalized by the camera
+type FileStatus struct {
+	Incomplete bool
+	// Reasons why the file is incomplete, like missing moov or truncated box
+	Reasons          []string `json:",omitempty"`
+	MajorBrand       string   `json:",omitempty"`
+	CompatibleBrands []string `json:",omitempty"`
+	// RTMDOffset offset of RTMD sample found by scanning mdat
+	RTMDOffset uint64 `json:",omitempty"`
+}
+
+func (s *FileStatus) addReason(format string, a ...any) {
+	s.Incomplete = true
+	s.Reasons = append(s.Reasons, fmt.Sprintf(format, a...))
 }
 
 type Mp4Meta struct {
 	CreationTime     *time.Time
 	ModificationTime *time.Time
+	// LocationISO6709 from udta ©xyz atom
+	LocationISO6709 string `json:",omitempty"`
 	*VideoProfile
+	// VideoFrameRate from stts of the first video track
+	VideoFrameRate *FrameRate `json:",omitempty"`
+}
+
+// FrameRate Fps is the average of samples if Variable is true
+type FrameRate struct {
+	Fps      float64
+	Variable bool `json:",omitempty"`
 }
 
 type VideoProfile struct {
-	VideoAvgBitrate  string
-	PixelAspectRatio string
+	VideoAvgBitrate string
+	// VideoAvgBitrateKbps VideoAvgBitrate before formatting
+	VideoAvgBitrateKbps uint32 `json:"-"`
+	PixelAspectRatio    string
 }
 
 type MakerMeta struct {
@@ -38,6 +77,7 @@ type MakerMeta struct {
 	*Nikon
 	*Panasonic
 	*Sony
+	*Apple
 }
 
 type Atomos struct{}
@@ -48,8 +88,27 @@ type Nikon struct {
 }
 type Panasonic struct {
 	*panasonic.ClipMain
+	// P2 files of the clip on P2 card, set by ReadP2Cards
+	P2 *card.P2Clip `json:",omitempty"`
+	// MXF header metadata of P2 video
+	MXF *mxf.Header `json:",omitempty"`
 }
 type Sony struct {
 	*nrtmd.NonRealTimeMeta
 	*rtmd.RTMD
+	// Card files of the clip on card, set by Metadata.ReadSidecars
+	Card *card.SonyClip `json:",omitempty"`
+}
+type Apple struct {
+	QuickTime *apple.QuickTimeMeta
+	// TimedMetadata the first sample of mebx track, or every sample in raw mode
+	TimedMetadata []*apple.TimedSample `json:",omitempty"`
+
+	timedTrack *appleTimedTrack
+}
+
+type appleTimedTrack struct {
+	samples   []*box.Sample
+	timescale uint32
+	keys      map[uint32]*apple.MebxKey
 }
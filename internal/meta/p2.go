@@ -0,0 +1,51 @@
+package meta
+
+import (
+	"bufio"
+	"github.com/fukco/media-metadata/internal/card"
+	"github.com/fukco/media-metadata/internal/manufacturer"
+	"github.com/fukco/media-metadata/internal/mxf"
+	"os"
+	"path/filepath"
+)
+
+// ReadP2Cards reads clips of P2 cards, root is the card or its CONTENTS folder. A shot recorded across the cards is
+// one Metadata of the clip on its first card, FilePath is the video MXF or the clip XML if there is no video
+func ReadP2Cards(roots ...string) ([]*Metadata, error) {
+	clips, err := card.ReadP2Cards(roots...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Metadata, len(clips))
+	for i, clip := range clips {
+		m := &Metadata{
+			Manufacturer: manufacturer.PANASONIC,
+			MakerMeta:    &MakerMeta{Panasonic: &Panasonic{ClipMain: clip.ClipMain, P2: clip}},
+		}
+		m.FilePath = clip.Video
+		if m.FilePath == "" {
+			m.FilePath = clip.XML
+		}
+		m.FileName = filepath.Base(m.FilePath)
+		// header metadata is only needed if the clip XML is lost, it is cheap to read anyway
+		if clip.Video != "" {
+			m.MakerMeta.Panasonic.MXF = readMXFHeader(clip.Video)
+		}
+		result[i] = m
+	}
+	return result, nil
+}
+
+// readMXFHeader returns nil if file is not readable MXF
+func readMXFHeader(path string) *mxf.Header {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	header, err := mxf.ReadHeader(bufio.NewReader(f))
+	if err != nil {
+		return nil
+	}
+	return header
+}
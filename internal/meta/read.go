@@ -8,10 +8,13 @@ import (
 	"github.com/fukco/media-metadata/internal/common"
 	"github.com/fukco/media-metadata/internal/exif"
 	"github.com/fukco/media-metadata/internal/manufacturer"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
 	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
 	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"github.com/fukco/media-metadata/internal/rangeio"
+	"github.com/fukco/media-metadata/internal/xmp"
 	"io"
 )
 
@@ -20,53 +23,199 @@ type keyItemPair struct {
 	ilst *box.Ilst
 }
 
+// Options options of reading metadata
+type Options struct {
+	// Recover reads what is available from files not finalized by the camera instead of failing,
+	// the result is reported in Metadata.Status
+	Recover bool
+	// Raw keeps tags without decoder, like unknown RTMD local tags, and every sample of Apple timed metadata
+	// in the output, only the first sample is read otherwise
+	Raw bool
+}
+
 func Read(r io.ReadSeeker) (*Metadata, error) {
-	fileStructure, err := box.ReadFileStructure(r)
-	if err != nil {
+	return ReadWithOptions(r, Options{})
+}
+
+// StructureOptions options of reading file structure for metadata, payloads are decoded on demand
+func (opts Options) StructureOptions() box.ReadOptions {
+	if opts.Recover {
+		return box.ReadOptions{KeepUnsupported: true, KeepErrors: true, Recover: true, Lazy: true}
+	}
+	return box.ReadOptions{Lazy: true}
+}
+
+func ReadWithOptions(r io.ReadSeeker, opts Options) (*Metadata, error) {
+	fileStructure, err := box.ReadFileStructureWithOptions(r, opts.StructureOptions())
+	if fileStructure == nil || (err != nil && !opts.Recover) {
 		return nil, err
 	}
+	return ReadWithStructure(r, fileStructure, opts)
+}
+
+// ReadWithStructure reads metadata with the file structure already read with Options.StructureOptions,
+// like one from box.StructureCache shared with other readers of the same file
+func ReadWithStructure(r io.ReadSeeker, fileStructure *box.FileStructure, opts Options) (*Metadata, error) {
+	var status *FileStatus
+	if opts.Recover {
+		status = &FileStatus{}
+		if fileStructure.Err != nil {
+			status.addReason("%v", fileStructure.Err)
+		}
+	}
+	// errors are reported as reasons of incomplete file in recovery mode
+	onError := func(err error) error {
+		if status == nil {
+			return err
+		}
+		status.addReason("%v", err)
+		return nil
+	}
 	metadata := &Metadata{
 		Manufacturer: fileStructure.Mfr,
 		Mp4Meta:      &Mp4Meta{},
 		MakerMeta:    &MakerMeta{},
+		Status:       status,
 	}
-	err = iterator(r, metadata, fileStructure, fileStructure.BoxDetails)
+	err := iterator(r, metadata, fileStructure, fileStructure.BoxDetails, onError)
 	if err != nil {
 		return nil, err
 	}
 	pair := &keyItemPair{}
-	searchKeysAndItems(pair, fileStructure.BoxDetails)
-	err = handleKeyAndItems(pair, metadata, fileStructure)
+	if err = searchKeysAndItems(r, pair, fileStructure.BoxDetails); err == nil {
+		err = handleKeyAndItems(pair, metadata, fileStructure)
+	}
 	if err != nil {
-		return nil, err
+		if err = onError(err); err != nil {
+			return nil, err
+		}
 	}
+	if metadata.ExifMeta != nil && len(metadata.ExifMeta.XMP) > 0 {
+		mergeXMP(metadata, metadata.ExifMeta.XMP)
+	}
+	if status != nil {
+		recoverFile(r, metadata, fileStructure, status)
+	}
+	if opts.Raw && metadata.MakerMeta.Sony != nil && metadata.MakerMeta.Sony.RTMD != nil {
+		metadata.MakerMeta.Sony.RTMD.UnknownTags = metadata.MakerMeta.Sony.RTMD.RawTags()
+	}
+	if opts.Raw && metadata.MakerMeta.Apple != nil {
+		metadata.MakerMeta.Apple.readTimedSeries(r)
+	}
+	metadata.Location = resolveLocation(metadata)
 	return metadata, nil
 }
 
-func searchKeysAndItems(pair *keyItemPair, boxDetails []*box.BoxDetail) {
+// recoverFile checks completeness of file, RTMD is searched in mdat when it is not found from the sample table
+func recoverFile(r io.ReadSeeker, metadata *Metadata, fileStructure *box.FileStructure, status *FileStatus) {
+	var moov, mdat *box.BoxDetail
+	for _, detail := range fileStructure.BoxDetails {
+		switch detail.Type {
+		case box.FileTypeBox:
+			if ftyp, ok := detail.Boxer.(*box.Ftyp); ok {
+				status.MajorBrand = string(ftyp.MajorBrand[:])
+				for _, brand := range ftyp.CompatibleBrands {
+					status.CompatibleBrands = append(status.CompatibleBrands, string(brand.CompatibleBrand[:]))
+				}
+			}
+		case box.MovieBox:
+			moov = detail
+		case box.MediaDataBox:
+			if mdat == nil {
+				mdat = detail
+			}
+		}
+		if detail.Truncated {
+			status.addReason("box %s at %d is truncated", detail.Type, detail.Offset)
+		}
+	}
+	if moov == nil {
+		status.addReason("moov not found")
+	}
+	if mdat == nil {
+		return
+	}
+	if moov == nil && isSizeFieldZero(r, mdat.BoxInfo) {
+		status.addReason("mdat size is 0, file was not finalized")
+	}
+	if metadata.Manufacturer != manufacturer.SONY && metadata.Manufacturer != manufacturer.Unknown {
+		return
+	}
+	if metadata.MakerMeta.Sony != nil && metadata.MakerMeta.Sony.RTMD != nil {
+		return
+	}
+	offset, err := rtmd.Scan(r, mdat.Offset+mdat.HeaderSize, mdat.Offset+mdat.Size)
+	if err != nil {
+		return
+	}
+	RTMD, err := rtmd.ReadRTMD(r, 0, offset)
+	if err != nil {
+		return
+	}
+	if metadata.MakerMeta.Sony == nil {
+		metadata.MakerMeta.Sony = &Sony{}
+	}
+	metadata.MakerMeta.Sony.RTMD = RTMD
+	metadata.Manufacturer = manufacturer.SONY
+	status.RTMDOffset = offset
+}
+
+func isSizeFieldZero(r io.ReadSeeker, bi *box.BoxInfo) bool {
+	if _, err := r.Seek(int64(bi.Offset), io.SeekStart); err != nil {
+		return false
+	}
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return false
+	}
+	return binary.BigEndian.Uint32(size) == 0
+}
+
+func searchKeysAndItems(r io.ReadSeeker, pair *keyItemPair, boxDetails []*box.BoxDetail) error {
 	for _, detail := range boxDetails {
 		if pair.keys != nil && pair.ilst != nil {
-			return
+			return nil
 		}
-		if detail.Type == box.MetadataItemKeysAtom {
-			pair.keys = detail.Boxer.(*box.Keys)
-		} else if detail.Type == box.MetadataItemListAtom {
-			pair.ilst = detail.Boxer.(*box.Ilst)
+		if detail.Err == nil && (detail.Type == box.MetadataItemKeysAtom || detail.Type == box.MetadataItemListAtom) {
+			b, err := detail.Load(r)
+			if err != nil {
+				return err
+			}
+			if keys, ok := b.(*box.Keys); ok {
+				pair.keys = keys
+			} else if ilst, ok := b.(*box.Ilst); ok {
+				pair.ilst = ilst
+			}
 		}
 		if len(detail.Children) > 0 {
-			searchKeysAndItems(pair, detail.Children)
+			if err := searchKeysAndItems(r, pair, detail.Children); err != nil {
+				return err
+			}
 		}
 	}
+	return nil
 }
 
-func iterator(r io.ReadSeeker, metadata *Metadata, fileStructure *box.FileStructure, boxDetails []*box.BoxDetail) error {
+func iterator(r io.ReadSeeker, metadata *Metadata, fileStructure *box.FileStructure, boxDetails []*box.BoxDetail, onError func(error) error) error {
 	for _, detail := range boxDetails {
-		err := handleBoxDetail(r, metadata, detail, fileStructure)
-		if err != nil {
-			return err
+		if detail.Err != nil {
+			// box kept in recovery mode whose payload can not be decoded
+			if err := onError(fmt.Errorf("box %s at %d: %w", detail.Type, detail.Offset, detail.Err)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !detail.IsSupportedBox() {
+			// unsupported box kept in recovery mode
+			continue
+		}
+		if err := handleBoxDetail(r, metadata, detail, fileStructure); err != nil {
+			if err = onError(err); err != nil {
+				return err
+			}
 		}
 		if len(detail.Children) > 0 {
-			err := iterator(r, metadata, fileStructure, detail.Children)
+			err := iterator(r, metadata, fileStructure, detail.Children, onError)
 			if err != nil {
 				return err
 			}
@@ -83,17 +232,17 @@ func handleBoxDetail(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDeta
 			return err
 		}
 	case box.MetaBox:
-		err := handleMeta(metadata, boxDetail, fileStructure)
+		err := handleMeta(r, metadata, boxDetail, fileStructure)
 		if err != nil {
 			return err
 		}
 	case box.UUIDExtensionBox:
-		err := handleUUIDExtensionBox(metadata, boxDetail)
+		err := handleUUIDExtensionBox(r, metadata, boxDetail)
 		if err != nil {
 			return err
 		}
 	case box.CanonCNDA:
-		err := handleCanonCNDA(metadata, boxDetail, fileStructure)
+		err := handleCanonCNDA(r, metadata, boxDetail, fileStructure)
 		if err != nil {
 			return err
 		}
@@ -107,8 +256,20 @@ func handleBoxDetail(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDeta
 		if err != nil {
 			return err
 		}
+	case box.UserDataXMP:
+		b, err := boxDetail.Load(r)
+		if err != nil {
+			return err
+		}
+		mergeXMP(metadata, b.(*box.XMP).Data)
+	case box.UserDataLocation:
+		b, err := boxDetail.Load(r)
+		if err != nil {
+			return err
+		}
+		metadata.Mp4Meta.LocationISO6709 = b.(*box.Xyz).ISO6709()
 	case box.NikonNCTGBox:
-		err := handleNikonNCTGBox(metadata, boxDetail)
+		err := handleNikonNCTGBox(r, metadata, boxDetail)
 		if err != nil {
 			return err
 		}
@@ -130,9 +291,11 @@ func handleKeyAndItems(pair *keyItemPair, metadata *Metadata, fileStructure *box
 		value, _ := item.Value.Value()
 		valueMap[int(item.Type)] = value
 	}
-	for i := 0; i < int(fileStructure.QuickTimeKeysMetaEntryCount); i++ {
+	for i := 0; i < len(keySlice); i++ {
 		if keySlice[i] == "com.panasonic.Semi-Pro.metadata.xml" {
-			handlePanaMetaItemXML(metadata, (valueMap[i+1]).(string))
+			if value, ok := valueMap[i+1].(string); ok {
+				handlePanaMetaItemXML(metadata, value)
+			}
 		} else {
 			metaItemKeyValues[keySlice[i]] = valueMap[i+1]
 		}
@@ -140,42 +303,57 @@ func handleKeyAndItems(pair *keyItemPair, metadata *Metadata, fileStructure *box
 	if len(metaItemKeyValues) > 0 {
 		metadata.MetaItemKeyValues = metaItemKeyValues
 	}
+	if apple.IsApple(metaItemKeyValues) {
+		if metadata.Manufacturer == manufacturer.Unknown {
+			metadata.Manufacturer = manufacturer.APPLE
+		}
+		if metadata.MakerMeta.Apple == nil {
+			metadata.MakerMeta.Apple = &Apple{}
+		}
+		metadata.MakerMeta.Apple.QuickTime = apple.QuickTimeMetaFromKeyValues(metaItemKeyValues)
+	}
 	return nil
 }
 
 func handleMediaBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail) error {
-	handlerType := ""
-	for _, child := range boxDetail.Children {
-		if child.Type == box.HandlerReferenceBox {
-			hdlr := child.Boxer.(*box.Hdlr)
-			handlerType = string(hdlr.HandlerType[:])
-			break
-		}
+	switch boxDetail.HandlerType(r) {
+	case "vide":
+		return handleVideoTrack(r, metadata, boxDetail)
+	case "meta":
+	default:
+		return nil
 	}
-	if handlerType != "meta" {
+	stbl := boxDetail.SampleTable()
+	if stbl == nil {
 		return nil
 	}
+	if stsdDetail := stbl.FindChild(box.SampleDescriptionBox); stsdDetail != nil {
+		b, err := stsdDetail.Load(r)
+		if err != nil {
+			return err
+		}
+		if stsd, ok := b.(*box.Stsd); ok && stsd.DataFormat() == apple.MebxDataFormat {
+			return handleAppleTimedMetadata(r, metadata, boxDetail, stsd)
+		}
+	}
 	sampleSize, offset := uint32(0), uint32(0)
-	for _, c1 := range boxDetail.Children {
-		if c1.Type == box.MediaInformationBox {
-			for _, c2 := range c1.Children {
-				if c2.Type == box.SampleTableBox {
-					for _, child := range c2.Children {
-						stsz, ok := child.Boxer.(*box.Stsz)
-						if ok {
-							sampleSize = stsz.Size
-						}
-						stco, ok := child.Boxer.(*box.Stco)
-						if ok {
-							offset = stco.Offsets[0]
-						}
-					}
-				}
-			}
+	for _, child := range stbl.Children {
+		if child.Type != box.SampleSizeBox && child.Type != box.ChunkOffsetBox {
+			continue
+		}
+		b, err := child.Load(r)
+		if err != nil {
+			return err
+		}
+		if stsz, ok := b.(*box.Stsz); ok {
+			sampleSize = stsz.Size
+		}
+		if stco, ok := b.(*box.Stco); ok && len(stco.Offsets) > 0 {
+			offset = stco.Offsets[0]
 		}
 	}
 	if sampleSize > 0 && offset > 0 {
-		RTMD, err := rtmd.ReadRTMD(r, sampleSize, offset)
+		RTMD, err := rtmd.ReadRTMD(r, sampleSize, uint64(offset))
 		if err != nil {
 			return err
 		}
@@ -187,26 +365,105 @@ func handleMediaBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetai
 	return nil
 }
 
-func handleMeta(metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box.FileStructure) error {
-	if fileStructure.Mfr != manufacturer.SONY {
+// handleVideoTrack reads frame rate from sample durations of the first video track
+func handleVideoTrack(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail) error {
+	if metadata.Mp4Meta.VideoFrameRate != nil {
 		return nil
 	}
-	handlerType := ""
-	for _, child := range boxDetail.Children {
-		if child.Type == box.HandlerReferenceBox {
-			hdlr := child.Boxer.(*box.Hdlr)
-			handlerType = string(hdlr.HandlerType[:])
-			break
+	stbl := boxDetail.SampleTable()
+	if stbl == nil {
+		return nil
+	}
+	sttsDetail := stbl.FindChild(box.TimeToSampleBox)
+	timescale := boxDetail.Timescale(r)
+	if sttsDetail == nil || timescale == 0 {
+		return nil
+	}
+	b, err := sttsDetail.Load(r)
+	if err != nil {
+		return err
+	}
+	stts, ok := b.(*box.Stts)
+	if !ok {
+		return nil
+	}
+	entries := stts.Entries
+	// the last sample often has a different duration
+	if n := len(entries); n > 1 && entries[n-1].SampleCount == 1 {
+		entries = entries[:n-1]
+	}
+	var count, duration uint64
+	variable := false
+	for _, entry := range entries {
+		if entry.SampleDelta != entries[0].SampleDelta {
+			variable = true
 		}
+		count += uint64(entry.SampleCount)
+		duration += uint64(entry.SampleCount) * uint64(entry.SampleDelta)
 	}
-	if handlerType != "nrtm" {
+	if count == 0 || duration == 0 {
+		return nil
+	}
+	metadata.Mp4Meta.VideoFrameRate = &FrameRate{Fps: float64(timescale) * float64(count) / float64(duration), Variable: variable}
+	return nil
+}
+
+// handleAppleTimedMetadata reads the first sample, the other samples of the track are read by
+// Apple.readTimedSeries in raw mode
+func handleAppleTimedMetadata(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail, stsd *box.Stsd) error {
+	keys, err := apple.ParseMebxKeys(stsd.Entries)
+	if err != nil {
+		return err
+	}
+	samples, err := box.Samples(r, boxDetail.SampleTable())
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	timescale := boxDetail.Timescale(r)
+	timedSample, err := apple.ReadTimedSample(r, samples[0], timescale, keys)
+	if err != nil {
+		return err
+	}
+	if metadata.MakerMeta.Apple == nil {
+		metadata.MakerMeta.Apple = &Apple{}
+	}
+	metadata.MakerMeta.Apple.TimedMetadata = []*apple.TimedSample{timedSample}
+	metadata.MakerMeta.Apple.timedTrack = &appleTimedTrack{samples: samples, timescale: timescale, keys: keys}
+	return nil
+}
+
+// readTimedSeries reads the samples following the first one, reading stops at the first broken sample
+func (a *Apple) readTimedSeries(r io.ReadSeeker) {
+	if a.timedTrack == nil || len(a.TimedMetadata) != 1 {
+		return
+	}
+	for _, sample := range a.timedTrack.samples[1:] {
+		timedSample, err := apple.ReadTimedSample(r, sample, a.timedTrack.timescale, a.timedTrack.keys)
+		if err != nil {
+			return
+		}
+		a.TimedMetadata = append(a.TimedMetadata, timedSample)
+	}
+}
+
+func handleMeta(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box.FileStructure) error {
+	if fileStructure.Mfr != manufacturer.SONY {
+		return nil
+	}
+	if boxDetail.HandlerType(r) != "nrtm" {
 		return nil
 	}
 	for _, child := range boxDetail.Children {
 		if child.Type == box.XMLBox {
-			xmlBox := child.Boxer.(*box.XML)
+			b, err := child.Load(r)
+			if err != nil {
+				return err
+			}
 			v := &nrtmd.NonRealTimeMeta{}
-			err := xml.Unmarshal([]byte(xmlBox.Xml), v)
+			err = xml.Unmarshal([]byte(b.(*box.XML).Xml), v)
 			if err != nil {
 				return err
 			}
@@ -219,15 +476,26 @@ func handleMeta(metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box
 	return nil
 }
 
-func handleUUIDExtensionBox(metadata *Metadata, boxDetail *box.BoxDetail) error {
-	if boxDetail.Boxer.UserType() == box.TypeUUIDProf() {
-		uuidProf := boxDetail.Boxer.(*box.UUIDProf)
+func handleUUIDExtensionBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail) error {
+	boxer, err := boxDetail.Load(r)
+	if err != nil {
+		return err
+	}
+	if boxer.UserType() == box.TypeUUIDXMP() {
+		uuidXMP := boxer.(*box.UUIDXMP)
+		mergeXMP(metadata, uuidXMP.Data)
+	} else if boxer.UserType() == box.TypeUUIDProf() {
+		uuidProf := boxer.(*box.UUIDProf)
 		for _, item := range uuidProf.ProfileItems {
 			if item.Type == uint32(box.VideoProfile) {
 				videoAvgBitrateIndex, pixelAspectRatioIndex := 5, 10
+				if len(item.Data) < pixelAspectRatioIndex*4+4 {
+					break
+				}
 				videoAvgBitrate := binary.BigEndian.Uint32(item.Data[videoAvgBitrateIndex*4 : videoAvgBitrateIndex*4+4])
 				metadata.Mp4Meta.VideoProfile = &VideoProfile{
-					VideoAvgBitrate: common.ConvertBitrate(videoAvgBitrate),
+					VideoAvgBitrate:     common.ConvertBitrate(videoAvgBitrate),
+					VideoAvgBitrateKbps: videoAvgBitrate,
 					PixelAspectRatio: fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(item.Data[pixelAspectRatioIndex*4:pixelAspectRatioIndex*4+2]),
 						binary.BigEndian.Uint16(item.Data[pixelAspectRatioIndex*4+2:pixelAspectRatioIndex*4+4])),
 				}
@@ -238,9 +506,12 @@ func handleUUIDExtensionBox(metadata *Metadata, boxDetail *box.BoxDetail) error
 	return nil
 }
 
-func handleCanonCNDA(metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box.FileStructure) error {
-	cnda := boxDetail.Boxer.(*box.CNDA)
-	exifMeta, err := exif.ProcessJPEG(cnda.Data, fileStructure.Mfr)
+func handleCanonCNDA(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box.FileStructure) error {
+	b, err := boxDetail.Load(r)
+	if err != nil {
+		return err
+	}
+	exifMeta, err := exif.ProcessJPEG(b.(*box.CNDA).Data, fileStructure.Mfr)
 	if err != nil {
 		return err
 	}
@@ -250,12 +521,15 @@ func handleCanonCNDA(metadata *Metadata, boxDetail *box.BoxDetail, fileStructure
 
 func handlePanasonicPANABox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail, fileStructure *box.FileStructure) error {
 	bi := boxDetail.BoxInfo
+	if bi.Size < bi.HeaderSize+0x4080 {
+		return fmt.Errorf("invalid PANA box size: %d", bi.Size)
+	}
 	_, err := r.Seek(int64(bi.Offset+bi.HeaderSize+0x4080), io.SeekStart)
 	if err != nil {
 		return err
 	}
 	data := make([]byte, bi.Size-bi.HeaderSize-0x4080)
-	if _, err := r.Read(data); err != nil {
+	if _, err := io.ReadFull(r, data); err != nil {
 		return err
 	}
 	exifMeta, err := exif.ProcessJPEG(data, fileStructure.Mfr)
@@ -268,12 +542,15 @@ func handlePanasonicPANABox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.
 
 func handleFujiMVTGBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail) error {
 	bi := boxDetail.BoxInfo
+	if bi.Size < bi.HeaderSize+16 {
+		return fmt.Errorf("invalid MVTG box size: %d", bi.Size)
+	}
 	_, err := r.Seek(int64(bi.Offset+bi.HeaderSize+16), io.SeekStart)
 	if err != nil {
 		return err
 	}
 	data := make([]byte, bi.Size-bi.HeaderSize-16)
-	if _, err := r.Read(data); err != nil {
+	if _, err := io.ReadFull(r, data); err != nil {
 		return err
 	}
 	exifMeta, err := exif.Process(data, true, manufacturer.FUJIFILM)
@@ -284,9 +561,12 @@ func handleFujiMVTGBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDe
 	return nil
 }
 
-func handleNikonNCTGBox(metadata *Metadata, boxDetail *box.BoxDetail) error {
-	nctgBox := boxDetail.Boxer.(*box.NCTG)
-	nctg, err := nikon.ProcessNCTG(nctgBox)
+func handleNikonNCTGBox(r io.ReadSeeker, metadata *Metadata, boxDetail *box.BoxDetail) error {
+	b, err := boxDetail.Load(r)
+	if err != nil {
+		return err
+	}
+	nctg, err := nikon.ProcessNCTG(b.(*box.NCTG))
 	if err != nil {
 		return err
 	}
@@ -296,10 +576,32 @@ func handleNikonNCTGBox(metadata *Metadata, boxDetail *box.BoxDetail) error {
 	return nil
 }
 
+// mergeXMP parses the packet, invalid packet is ignored as other metadata is still usable
+func mergeXMP(metadata *Metadata, packet []byte) {
+	x, err := xmp.Parse(packet)
+	if err != nil {
+		return
+	}
+	if metadata.XMP == nil {
+		metadata.XMP = x
+	} else {
+		metadata.XMP.Merge(x)
+	}
+}
+
 func handlePanaMetaItemXML(metadata *Metadata, value string) {
 	v := &panasonic.ClipMain{}
 	if err := xml.Unmarshal([]byte(value), v); err != nil {
 		fmt.Println(err)
 	}
-	metadata.MakerMeta.Panasonic = &Panasonic{v}
+	metadata.MakerMeta.Panasonic = &Panasonic{ClipMain: v}
+}
+
+// ReadAt reads metadata from random access reader like remote file,
+// only byte ranges of the needed boxes are fetched through the block cache of rangeio.Reader
+func ReadAt(r io.ReaderAt, size int64, opts Options) (*Metadata, error) {
+	if reader, ok := r.(*rangeio.Reader); ok {
+		return ReadWithOptions(reader, opts)
+	}
+	return ReadWithOptions(rangeio.NewReader(r, size), opts)
 }
@@ -0,0 +1,43 @@
+package meta
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/card"
+	"github.com/fukco/media-metadata/internal/manufacturer"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"os"
+)
+
+// ReadSidecars reads files written by camera next to the clip, like Sony M01.XML which is lost when the clip is
+// transcoded or rewrapped, elements missing in the file are filled from the sidecar
+func (m *Metadata) ReadSidecars(path string) error {
+	clip := card.FindSonyClip(path)
+	if clip == nil {
+		return nil
+	}
+	if m.MakerMeta.Sony == nil {
+		m.MakerMeta.Sony = &Sony{}
+	}
+	m.MakerMeta.Sony.Card = clip
+	if clip.Sidecar == "" {
+		return nil
+	}
+	f, err := os.Open(clip.Sidecar)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sidecar, err := nrtmd.Read(f)
+	if err != nil {
+		return fmt.Errorf("sidecar %s: %w", clip.Sidecar, err)
+	}
+	if m.MakerMeta.Sony.NonRealTimeMeta == nil {
+		m.MakerMeta.Sony.NonRealTimeMeta = sidecar
+	} else {
+		m.MakerMeta.Sony.NonRealTimeMeta.Merge(sidecar)
+	}
+	if m.Manufacturer == manufacturer.Unknown {
+		m.Manufacturer = manufacturer.SONY
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package meta
+
+import (
+	"github.com/fukco/media-metadata/internal/manufacturer"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSidecar = `<?xml version="1.0" encoding="UTF-8"?>
+<NonRealTimeMeta xmlns="urn:schemas-professionalDisc:nonRealTimeMeta:ver.2.20" lastUpdate="2024-05-01T10:00:00+08:00">
+<CreationDate value="2024-05-01T10:00:00+08:00"/>
+<Device manufacturer="Sony" modelName="ILME-FX3" serialNo="1234567"/>
+<Lens modelName="FE 24-70mm F2.8 GM II"/>
+</NonRealTimeMeta>`
+
+func TestReadSidecars(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"C0001.MP4":    "",
+		"C0001M01.XML": testSidecar,
+		"C0002.MP4":    "",
+		"C0002M01.XML": "<NonRealTimeMeta><Device",
+		"C0003.MP4":    "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("sidecar only", func(t *testing.T) {
+		m := &Metadata{MakerMeta: &MakerMeta{}}
+		if err := m.ReadSidecars(filepath.Join(dir, "C0001.MP4")); err != nil {
+			t.Fatal(err)
+		}
+		if m.Manufacturer != manufacturer.SONY {
+			t.Errorf("Manufacturer = %v", m.Manufacturer)
+		}
+		sony := m.MakerMeta.Sony
+		if sony == nil || sony.Card == nil || sony.Card.Sidecar != filepath.Join(dir, "C0001M01.XML") {
+			t.Fatalf("Sony = %+v", sony)
+		}
+		if sony.NonRealTimeMeta == nil || sony.Device.ModelName != "ILME-FX3" || sony.CreationDate.Value != "2024-05-01T10:00:00+08:00" {
+			t.Errorf("NonRealTimeMeta = %+v", sony.NonRealTimeMeta)
+		}
+	})
+
+	t.Run("embedded XML wins", func(t *testing.T) {
+		embedded := &nrtmd.NonRealTimeMeta{Device: nrtmd.Device{Manufacturer: "Sony", ModelName: "ILCE-7SM3"}}
+		m := &Metadata{Manufacturer: manufacturer.SONY, MakerMeta: &MakerMeta{Sony: &Sony{NonRealTimeMeta: embedded}}}
+		if err := m.ReadSidecars(filepath.Join(dir, "C0001.MP4")); err != nil {
+			t.Fatal(err)
+		}
+		if m.MakerMeta.Sony.NonRealTimeMeta != embedded {
+			t.Fatal("embedded NonRealTimeMeta replaced")
+		}
+		if embedded.Device.ModelName != "ILCE-7SM3" || embedded.Lens.ModelName != "FE 24-70mm F2.8 GM II" ||
+			embedded.CreationDate.Value == "" {
+			t.Errorf("NonRealTimeMeta = %+v", embedded)
+		}
+	})
+
+	t.Run("broken sidecar", func(t *testing.T) {
+		m := &Metadata{MakerMeta: &MakerMeta{}}
+		if err := m.ReadSidecars(filepath.Join(dir, "C0002.MP4")); err == nil {
+			t.Error("want error")
+		}
+		if m.MakerMeta.Sony == nil || m.MakerMeta.Sony.NonRealTimeMeta != nil {
+			t.Errorf("Sony = %+v", m.MakerMeta.Sony)
+		}
+	})
+
+	t.Run("no sidecar", func(t *testing.T) {
+		m := &Metadata{MakerMeta: &MakerMeta{}}
+		if err := m.ReadSidecars(filepath.Join(dir, "C0003.MP4")); err != nil {
+			t.Fatal(err)
+		}
+		if m.MakerMeta.Sony != nil || m.Manufacturer != manufacturer.Unknown {
+			t.Errorf("Sony = %+v, Manufacturer = %v", m.MakerMeta.Sony, m.Manufacturer)
+		}
+	})
+}
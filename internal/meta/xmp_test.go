@@ -0,0 +1,33 @@
+package meta
+
+import (
+	"bytes"
+	"github.com/fukco/media-metadata/internal/box"
+	"testing"
+)
+
+func testXMPPacket(properties string) []byte {
+	return []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description xmlns:xmpDM="http://ns.adobe.com/xmp/1.0/DynamicMedia/" ` + properties + `/></rdf:RDF></x:xmpmeta>`)
+}
+
+// TestReadXMP packets of udta XMP_ and the XMP uuid box are merged, the packet read first wins
+func TestReadXMP(t *testing.T) {
+	userType := box.TypeUUIDXMP()
+	ftyp := testBox("ftyp", []byte("qt  \x00\x00\x02\x00qt  "))
+	moov := testBox("moov",
+		testFullBox("mvhd", make([]byte, 96)),
+		testBox("udta", testBox("XMP_", testXMPPacket(`xmpDM:scene="udta" xmpDM:shotName="7"`))))
+	uuid := testBox("uuid", userType[:], testXMPPacket(`xmpDM:scene="uuid" xmpDM:takeNumber="2"`))
+	broken := testBox("uuid", userType[:], []byte("<x:xmpmeta"))
+	m, err := Read(bytes.NewReader(bytes.Join([][]byte{ftyp, moov, uuid, broken}, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"xmpDM:scene": "udta", "xmpDM:shotName": "7", "xmpDM:takeNumber": "2"}
+	for name, value := range want {
+		if got := m.XMP.Get(name); got != value {
+			t.Errorf("%s: got %q, want %q", name, got, value)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package mxf reads header metadata of MXF files (SMPTE 377), only sets describing the clip are decoded.
+package mxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf16"
+)
+
+var ErrNotMXF = errors.New("not MXF, header partition pack not found")
+
+// maxHeaderSize header metadata larger than this is not read
+const maxHeaderSize = 16 << 20
+
+var (
+	// partitionPackPrefix key of partition packs, byte 13 is 02 for header partition
+	partitionPackPrefix = []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x05, 0x01, 0x01, 0x0d, 0x01, 0x02, 0x01, 0x01}
+	// localSetPrefix key of header metadata sets, byte 14 is the set type
+	localSetPrefix = []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x53, 0x01, 0x01, 0x0d, 0x01, 0x01, 0x01, 0x01, 0x01}
+)
+
+// set types of header metadata
+const (
+	setTimecodeComponent        = 0x14
+	setGenericPictureDescriptor = 0x27
+	setCDCIDescriptor           = 0x28
+	setRGBADescriptor           = 0x29
+	setIdentification           = 0x30
+	setMPEGVideoDescriptor      = 0x51
+)
+
+// Header clip description of header metadata, values of the first set are kept if there are many
+type Header struct {
+	CompanyName      string     `json:",omitempty"`
+	ProductName      string     `json:",omitempty"`
+	ProductVersion   string     `json:",omitempty"`
+	ModificationDate *time.Time `json:",omitempty"`
+	// StartTimecode frames since 00:00:00:00 counted at RoundedTimecodeBase
+	StartTimecode       int64
+	RoundedTimecodeBase int
+	DropFrame           bool `json:",omitempty"`
+	// EditRate sample rate of the picture descriptor
+	EditRate float64 `json:",omitempty"`
+	// Duration frames of the essence container
+	Duration int64 `json:",omitempty"`
+	Width    int   `json:",omitempty"`
+	Height   int   `json:",omitempty"`
+
+	timecode, picture, identification bool
+}
+
+// ReadHeader reads header metadata following the header partition pack at the start of file
+func ReadHeader(r io.Reader) (*Header, error) {
+	key, value, _, err := readKLV(r, maxHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(key, partitionPackPrefix) || key[13] != 0x02 || len(value) < 40 {
+		return nil, ErrNotMXF
+	}
+	headerByteCount := int64(binary.BigEndian.Uint64(value[32:40]))
+	if headerByteCount > maxHeaderSize {
+		return nil, fmt.Errorf("header metadata of %d bytes is too large", headerByteCount)
+	}
+	header := &Header{}
+	// header byte count starts from the primer pack after fill items
+	var read int64
+	started := false
+	for !started || read < headerByteCount {
+		key, value, n, err := readKLV(r, maxHeaderSize)
+		if err != nil {
+			if errors.Is(err, io.EOF) && started {
+				break
+			}
+			return nil, err
+		}
+		if !started && isFill(key) {
+			continue
+		}
+		started = true
+		read += n
+		if bytes.HasPrefix(key, localSetPrefix) {
+			header.readSet(key[14], value)
+		}
+	}
+	return header, nil
+}
+
+func isFill(key []byte) bool {
+	return bytes.HasPrefix(key, []byte{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01}) && bytes.Equal(key[8:12], []byte{0x03, 0x01, 0x02, 0x10})
+}
+
+// readKLV reads 16-byte key, BER length and value up to limit bytes, n is the size of KLV
+func readKLV(r io.Reader, limit int64) (key []byte, value []byte, n int64, err error) {
+	key = make([]byte, 16)
+	if _, err = io.ReadFull(r, key); err != nil {
+		return nil, nil, 0, err
+	}
+	length, size, err := readBER(r)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if length > uint64(limit) {
+		return nil, nil, 0, fmt.Errorf("KLV of %d bytes is too large", length)
+	}
+	value = make([]byte, length)
+	if _, err = io.ReadFull(r, value); err != nil {
+		return nil, nil, 0, err
+	}
+	return key, value, int64(len(key)+size) + int64(length), nil
+}
+
+// readBER reads BER length, size is the bytes of the length
+func readBER(r io.Reader) (length uint64, size int, err error) {
+	b := make([]byte, 1)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return 0, 0, err
+	}
+	if b[0] < 0x80 {
+		return uint64(b[0]), 1, nil
+	}
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 8 {
+		return 0, 0, fmt.Errorf("invalid BER length of %d bytes", n)
+	}
+	buf := make([]byte, n)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return 0, 0, err
+	}
+	for _, v := range buf {
+		length = length<<8 | uint64(v)
+	}
+	return length, n + 1, nil
+}
+
+// readSet reads local tags of 2-byte tag and 2-byte length, static tags of SMPTE 377 are used without the primer pack
+func (h *Header) readSet(setType byte, value []byte) {
+	tags := make(map[uint16][]byte)
+	for len(value) >= 4 {
+		tag, length := binary.BigEndian.Uint16(value), int(binary.BigEndian.Uint16(value[2:]))
+		if len(value) < 4+length {
+			break
+		}
+		tags[tag] = value[4 : 4+length]
+		value = value[4+length:]
+	}
+	switch setType {
+	case setIdentification:
+		if h.identification {
+			return
+		}
+		h.identification = true
+		h.CompanyName = utf16String(tags[0x3c01])
+		h.ProductName = utf16String(tags[0x3c02])
+		h.ProductVersion = utf16String(tags[0x3c04])
+		h.ModificationDate = timestamp(tags[0x3c06])
+	case setTimecodeComponent:
+		if h.timecode || len(tags[0x1501]) != 8 || len(tags[0x1502]) != 2 {
+			return
+		}
+		h.timecode = true
+		h.StartTimecode = int64(binary.BigEndian.Uint64(tags[0x1501]))
+		h.RoundedTimecodeBase = int(binary.BigEndian.Uint16(tags[0x1502]))
+		h.DropFrame = len(tags[0x1503]) == 1 && tags[0x1503][0] != 0
+	case setGenericPictureDescriptor, setCDCIDescriptor, setRGBADescriptor, setMPEGVideoDescriptor:
+		if h.picture {
+			return
+		}
+		h.picture = true
+		if rate := tags[0x3001]; len(rate) == 8 && binary.BigEndian.Uint32(rate[4:]) != 0 {
+			h.EditRate = float64(int32(binary.BigEndian.Uint32(rate))) / float64(int32(binary.BigEndian.Uint32(rate[4:])))
+		}
+		if duration := tags[0x3002]; len(duration) == 8 {
+			h.Duration = int64(binary.BigEndian.Uint64(duration))
+		}
+		if width := tags[0x3203]; len(width) == 4 {
+			h.Width = int(binary.BigEndian.Uint32(width))
+		}
+		if height := tags[0x3202]; len(height) == 4 {
+			h.Height = int(binary.BigEndian.Uint32(height))
+		}
+	}
+}
+
+func utf16String(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		unit := binary.BigEndian.Uint16(b[i:])
+		if unit == 0 {
+			break
+		}
+		units = append(units, unit)
+	}
+	return string(utf16.Decode(units))
+}
+
+// timestamp year, month, day, hour, minute, second and quarter milliseconds, without time zone
+func timestamp(b []byte) *time.Time {
+	if len(b) != 8 || b[2] == 0 || b[3] == 0 {
+		return nil
+	}
+	t := time.Date(int(binary.BigEndian.Uint16(b)), time.Month(b[2]), int(b[3]), int(b[4]), int(b[5]), int(b[6]), int(b[7])*4*int(time.Millisecond), time.UTC)
+	return &t
+}
+
+// Timecode splits StartTimecode into hours, minutes, seconds and frames, frame numbers skipped by drop frame timecode
+// are added back. ok is false if there is no timecode component
+func (h *Header) Timecode() (hours, minutes, seconds, frames int, ok bool) {
+	if !h.timecode || h.RoundedTimecodeBase <= 0 || h.StartTimecode < 0 {
+		return 0, 0, 0, 0, false
+	}
+	base := int64(h.RoundedTimecodeBase)
+	count := h.StartTimecode
+	if h.DropFrame {
+		// 2 frame numbers of 30 fps, 4 of 60 fps, are dropped every minute except every tenth minute
+		drop := base / 15
+		framesPer10Minutes := base*600 - drop*9
+		framesPerMinute := base*60 - drop
+		tens, remainder := count/framesPer10Minutes, count%framesPer10Minutes
+		count += drop * 9 * tens
+		if remainder > drop {
+			count += drop * ((remainder - drop) / framesPerMinute)
+		}
+	}
+	frames = int(count % base)
+	count /= base
+	seconds = int(count % 60)
+	count /= 60
+	minutes = int(count % 60)
+	hours = int(count / 60 % 24)
+	return hours, minutes, seconds, frames, true
+}
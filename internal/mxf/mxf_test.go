@@ -0,0 +1,219 @@
+package mxf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+var (
+	testFillKey   = []byte{0x06, 0x0e, 0x2b, 0x34, 0x01, 0x01, 0x01, 0x02, 0x03, 0x01, 0x02, 0x10, 0x01, 0x00, 0x00, 0x00}
+	testPrimerKey = []byte{0x06, 0x0e, 0x2b, 0x34, 0x02, 0x05, 0x01, 0x01, 0x0d, 0x01, 0x02, 0x01, 0x01, 0x05, 0x01, 0x00}
+	// testBodyKey body partition pack, header byte count ends before it
+	testBodyKey = append(append([]byte{}, partitionPackPrefix...), 0x03, 0x04, 0x00)
+)
+
+// testKLV encodes length in short form below 128 bytes and in 3-byte long form otherwise
+func testKLV(key []byte, value ...[]byte) []byte {
+	data := bytes.Join(value, nil)
+	b := append([]byte{}, key...)
+	if len(data) < 0x80 {
+		b = append(b, byte(len(data)))
+	} else {
+		b = append(b, 0x83, byte(len(data)>>16), byte(len(data)>>8), byte(len(data)))
+	}
+	return append(b, data...)
+}
+
+func testTag(tag uint16, value []byte) []byte {
+	b := binary.BigEndian.AppendUint16(nil, tag)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+func testSet(setType byte, tags ...[]byte) []byte {
+	key := append(append([]byte{}, localSetPrefix...), setType, 0x00)
+	return testKLV(key, tags...)
+}
+
+func testUTF16(s string) []byte {
+	var b []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		b = binary.BigEndian.AppendUint16(b, unit)
+	}
+	return append(b, 0, 0)
+}
+
+func testUint(size int, v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)[8-size:]
+}
+
+// testMXF header partition pack, fill item, primer pack and sets, followed by a body partition
+func testMXF(sets ...[]byte) []byte {
+	header := append(testKLV(testPrimerKey, make([]byte, 8)), bytes.Join(sets, nil)...)
+	pack := make([]byte, 88)
+	binary.BigEndian.PutUint64(pack[32:], uint64(len(header)))
+	partitionKey := append(append([]byte{}, partitionPackPrefix...), 0x02, 0x04, 0x00)
+	return bytes.Join([][]byte{testKLV(partitionKey, pack), testKLV(testFillKey, make([]byte, 200)), header,
+		testKLV(testBodyKey, make([]byte, 88))}, nil)
+}
+
+var (
+	testIdentification = testSet(setIdentification,
+		testTag(0x3c01, testUTF16("Panasonic")),
+		testTag(0x3c02, testUTF16("AU-EVA1")),
+		testTag(0x3c04, testUTF16("2.50")),
+		testTag(0x3c06, []byte{0x07, 0xe8, 5, 1, 10, 0, 2, 125}))
+	testTimecodeComponent = testSet(setTimecodeComponent,
+		testTag(0x1502, testUint(2, 30)),
+		testTag(0x1501, testUint(8, 1800)),
+		testTag(0x1503, []byte{1}))
+	testDescriptor = testSet(setCDCIDescriptor,
+		testTag(0x3001, append(testUint(4, 30000), testUint(4, 1001)...)),
+		testTag(0x3002, testUint(8, 1500)),
+		testTag(0x3203, testUint(4, 3840)),
+		testTag(0x3202, testUint(4, 2160)))
+)
+
+func TestReadHeader(t *testing.T) {
+	truncated := testMXF(testIdentification)
+	modified := time.Date(2024, 5, 1, 10, 0, 2, 500*int(time.Millisecond), time.UTC)
+	full := &Header{
+		CompanyName: "Panasonic", ProductName: "AU-EVA1", ProductVersion: "2.50", ModificationDate: &modified,
+		StartTimecode: 1800, RoundedTimecodeBase: 30, DropFrame: true,
+		EditRate: 30000.0 / 1001, Duration: 1500, Width: 3840, Height: 2160,
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want *Header
+		err  error
+	}{
+		{name: "all sets", data: testMXF(testIdentification, testTimecodeComponent, testDescriptor), want: full},
+		{
+			name: "first set is kept",
+			data: testMXF(testIdentification, testTimecodeComponent, testDescriptor,
+				testSet(setIdentification, testTag(0x3c01, testUTF16("Other"))),
+				testSet(setTimecodeComponent, testTag(0x1502, testUint(2, 25)), testTag(0x1501, testUint(8, 25))),
+				testSet(setMPEGVideoDescriptor, testTag(0x3203, testUint(4, 1920)))),
+			want: full,
+		},
+		{
+			name: "timecode component without rounded base",
+			data: testMXF(testSet(setTimecodeComponent, testTag(0x1501, testUint(8, 1800))),
+				testSet(setTimecodeComponent, testTag(0x1502, testUint(2, 25)), testTag(0x1501, testUint(8, 90000)))),
+			want: &Header{StartTimecode: 90000, RoundedTimecodeBase: 25},
+		},
+		{
+			name: "truncated tag and unknown set",
+			data: testMXF(testSet(0x2f, testTag(0x3c01, testUTF16("x"))),
+				testSet(setIdentification, testTag(0x3c01, testUTF16("Sony")), []byte{0x3c, 0x02, 0x00, 0x40, 0x00})),
+			want: &Header{CompanyName: "Sony"},
+		},
+		{name: "header without sets", data: testMXF(), want: &Header{}},
+		{name: "empty", data: nil, err: io.EOF},
+		{name: "not MXF", data: testKLV(testFillKey, make([]byte, 88)), err: ErrNotMXF},
+		{name: "body partition first", data: testKLV(testBodyKey, make([]byte, 88)), err: ErrNotMXF},
+		{name: "short partition pack", data: testMXF()[:17+16], err: io.ErrUnexpectedEOF},
+		// the body partition of 105 bytes and the end of the identification set are cut
+		{name: "truncated header", data: truncated[:len(truncated)-125], err: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := ReadHeader(bytes.NewReader(tt.data))
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, want := *header, *tt.want
+			if got.ModificationDate == nil != (want.ModificationDate == nil) ||
+				got.ModificationDate != nil && !got.ModificationDate.Equal(*want.ModificationDate) {
+				t.Errorf("ModificationDate = %v, want %v", got.ModificationDate, want.ModificationDate)
+			}
+			got.ModificationDate, want.ModificationDate = nil, nil
+			got.timecode, got.picture, got.identification = false, false, false
+			if got != want {
+				t.Errorf("ReadHeader = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadHeaderErrors(t *testing.T) {
+	pack := make([]byte, 88)
+	binary.BigEndian.PutUint64(pack[32:], maxHeaderSize+1)
+	partitionKey := append(append([]byte{}, partitionPackPrefix...), 0x02, 0x04, 0x00)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"header byte count too large", testKLV(partitionKey, pack)},
+		{"KLV too large", append(append([]byte{}, partitionKey...), 0x84, 0x7f, 0xff, 0xff, 0xff)},
+		{"BER of 9 bytes", append(append([]byte{}, partitionKey...), 0x89, 0, 0, 0, 0, 0, 0, 0, 0, 1)},
+		{"BER of 0 bytes", append(append([]byte{}, partitionKey...), 0x80)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if header, err := ReadHeader(bytes.NewReader(tt.data)); err == nil {
+				t.Errorf("ReadHeader = %+v, want error", header)
+			}
+		})
+	}
+}
+
+func TestTimecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int64
+		base      int
+		dropFrame bool
+		want      [4]int
+	}{
+		{"25 fps", 25*3600*10 + 25*61 + 3, 25, false, [4]int{10, 1, 1, 3}},
+		{"30 fps drop frame minute 1", 1800, 30, true, [4]int{0, 1, 0, 2}},
+		{"30 fps drop frame before minute 1", 1799, 30, true, [4]int{0, 0, 59, 29}},
+		{"30 fps drop frame minute 10", 17982, 30, true, [4]int{0, 10, 0, 0}},
+		{"30 fps drop frame hour 1", 107892, 30, true, [4]int{1, 0, 0, 0}},
+		{"60 fps drop frame minute 1", 3600, 60, true, [4]int{0, 1, 0, 4}},
+		{"wraps at midnight", 24 * 3600 * 24, 24, false, [4]int{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Header{StartTimecode: tt.count, RoundedTimecodeBase: tt.base, DropFrame: tt.dropFrame, timecode: true}
+			hours, minutes, seconds, frames, ok := h.Timecode()
+			if got := [4]int{hours, minutes, seconds, frames}; !ok || got != tt.want {
+				t.Errorf("Timecode = %v %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+	for _, h := range []*Header{{}, {RoundedTimecodeBase: 25}, {timecode: true}, {StartTimecode: -1, RoundedTimecodeBase: 25, timecode: true}} {
+		if _, _, _, _, ok := h.Timecode(); ok {
+			t.Errorf("Timecode of %+v is ok", h)
+		}
+	}
+}
+
+func FuzzReadHeader(f *testing.F) {
+	f.Add(testMXF(testIdentification, testTimecodeComponent, testDescriptor))
+	f.Add(testMXF(testSet(setTimecodeComponent, testTag(0x1502, testUint(2, 60)), testTag(0x1501, testUint(8, 1<<62)), testTag(0x1503, []byte{1}))))
+	f.Add(testMXF()[:60])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := ReadHeader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if hours, minutes, seconds, frames, ok := header.Timecode(); ok &&
+			(hours < 0 || hours > 23 || minutes < 0 || minutes > 59 || seconds < 0 || seconds > 59 || frames < 0 || frames >= header.RoundedTimecodeBase) {
+			t.Errorf("Timecode = %d:%d:%d:%d", hours, minutes, seconds, frames)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package normalize
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gamma transfer function of the recording
+type Gamma string
+
+const (
+	GammaRec709    Gamma = "Rec.709"
+	GammaHLG       Gamma = "HLG"
+	GammaPQ        Gamma = "PQ"
+	GammaSLog2     Gamma = "S-Log2"
+	GammaSLog3     Gamma = "S-Log3"
+	GammaSCinetone Gamma = "S-Cinetone"
+	GammaVLog      Gamma = "V-Log"
+	GammaNLog      Gamma = "N-Log"
+	GammaFLog      Gamma = "F-Log"
+	GammaFLog2     Gamma = "F-Log2"
+	GammaCLog      Gamma = "Canon Log"
+	GammaCLog2     Gamma = "Canon Log 2"
+	GammaCLog3     Gamma = "Canon Log 3"
+	GammaAppleLog  Gamma = "Apple Log"
+)
+
+// Gamut color primaries of the recording
+type Gamut string
+
+const (
+	GamutRec709      Gamut = "Rec.709"
+	GamutRec2020     Gamut = "Rec.2020"
+	GamutSGamut      Gamut = "S-Gamut"
+	GamutSGamut3     Gamut = "S-Gamut3"
+	GamutSGamut3Cine Gamut = "S-Gamut3.Cine"
+	GamutVGamut      Gamut = "V-Gamut"
+	GamutCinemaGamut Gamut = "Cinema Gamut"
+	GamutFGamut      Gamut = "F-Gamut"
+	GamutP3D65       Gamut = "P3-D65"
+)
+
+var gammaNames = map[string]Gamma{
+	"rec709":         GammaRec709,
+	"rec709xvycc":    GammaRec709,
+	"bt709":          GammaRec709,
+	"itur709":        GammaRec709,
+	"hlg":            GammaHLG,
+	"rec2100hlg":     GammaHLG,
+	"bt2100hlg":      GammaHLG,
+	"hybridloggamma": GammaHLG,
+	"pq":             GammaPQ,
+	"rec2100pq":      GammaPQ,
+	"bt2100pq":       GammaPQ,
+	"st2084":         GammaPQ,
+	"slog2":          GammaSLog2,
+	"slog3":          GammaSLog3,
+	"slog3cine":      GammaSLog3,
+	"scinetone":      GammaSCinetone,
+	"vlog":           GammaVLog,
+	"nlog":           GammaNLog,
+	"flog":           GammaFLog,
+	"flog2":          GammaFLog2,
+	"clog":           GammaCLog,
+	"canonlog":       GammaCLog,
+	"clog2":          GammaCLog2,
+	"canonlog2":      GammaCLog2,
+	"clog3":          GammaCLog3,
+	"canonlog3":      GammaCLog3,
+	"applelog":       GammaAppleLog,
+}
+
+var gamutNames = map[string]Gamut{
+	"rec709":      GamutRec709,
+	"bt709":       GamutRec709,
+	"rec2020":     GamutRec2020,
+	"rec2020ncl":  GamutRec2020,
+	"bt2020":      GamutRec2020,
+	"sgamut":      GamutSGamut,
+	"sgamut3":     GamutSGamut3,
+	"sgamut3cine": GamutSGamut3Cine,
+	"vgamut":      GamutVGamut,
+	"cinemagamut": GamutCinemaGamut,
+	"fgamut":      GamutFGamut,
+	"p3d65":       GamutP3D65,
+	"dcip3d65":    GamutP3D65,
+}
+
+// colorKey ignores case and separators, S-Log3, slog3 and S_LOG_3 are the same
+func colorKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_', '.':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// ParseGamma returns empty Gamma if name is not recognized
+func ParseGamma(name string) Gamma {
+	return gammaNames[colorKey(name)]
+}
+
+// ParseGamut returns empty Gamut if name is not recognized
+func ParseGamut(name string) Gamut {
+	return gamutNames[colorKey(name)]
+}
+
+// CDL ASC CDL of red, green and blue
+type CDL struct {
+	Slope      [3]float64
+	Offset     [3]float64
+	Power      [3]float64
+	Saturation float64
+}
+
+// String formats CDL like SOP (1.0000 1.0000 1.0000)(0.0000 0.0000 0.0000)(1.0000 1.0000 1.0000) SAT 1.0000
+func (cdl CDL) String() string {
+	return fmt.Sprintf("SOP (%.4f %.4f %.4f)(%.4f %.4f %.4f)(%.4f %.4f %.4f) SAT %.4f",
+		cdl.Slope[0], cdl.Slope[1], cdl.Slope[2], cdl.Offset[0], cdl.Offset[1], cdl.Offset[2],
+		cdl.Power[0], cdl.Power[1], cdl.Power[2], cdl.Saturation)
+}
@@ -0,0 +1,124 @@
+package normalize
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/exif"
+	"math"
+	"time"
+)
+
+// exifValues values of a group by tag ID, tags without definition are skipped for standard groups
+func exifValues(exifMeta *exif.ExifMeta, group string, definedOnly bool) map[uint16]string {
+	tags, ok := exifMeta.Tags[group]
+	if !ok {
+		return nil
+	}
+	values := make(map[uint16]string, len(tags))
+	for _, tag := range tags {
+		if !definedOnly || !tag.Undefined {
+			values[tag.ID] = tag.Value
+		}
+	}
+	return values
+}
+
+func makerGroup(maker exif.Maker, sub ...exif.GroupName) string {
+	if len(sub) > 0 {
+		return fmt.Sprintf("%s: %s/%s", exif.MakerIFD, maker, sub[0])
+	}
+	return fmt.Sprintf("%s: %s", exif.MakerIFD, maker)
+}
+
+func (c *Camera) fromExif(exifMeta *exif.ExifMeta) {
+	ifd0Group := string(exif.GroupIFD0)
+	ifd0 := exifValues(exifMeta, ifd0Group, true)
+	setExifText(&c.Manufacturer, ifd0Group, 0x010f, ifd0, SourceExif)
+	setExifText(&c.Model, ifd0Group, 0x0110, ifd0, SourceExif)
+
+	exifGroup := string(exif.GroupExif)
+	if values := exifValues(exifMeta, exifGroup, true); values != nil {
+		if values[0x9003] != "" {
+			path := ExifPath(exifGroup, 0x9003)
+			if values[0x9011] != "" {
+				if parse, err := time.Parse("2006:01:02 15:04:05-07:00", values[0x9003]+values[0x9011]); err == nil {
+					set(&c.DateRecorded, newField(Date{Time: parse}, "", SourceExif, path))
+				}
+			} else if parse, err := time.Parse("2006:01:02 15:04:05", values[0x9003]); err == nil {
+				set(&c.DateRecorded, newField(Date{Time: parse, Floating: true}, "", SourceExif, path))
+			}
+		}
+		setExifNumber(&c.ShutterSeconds, exifGroup, 0x829a, values, SourceExif)
+		setExifNumber(&c.FNumber, exifGroup, 0x829d, values, SourceExif)
+		// standard output sensitivity, recommended exposure index, then ISO speed
+		for _, id := range []uint16{0x8831, 0x8827, 0x8832} {
+			if _, ok := values[id]; ok {
+				setExifInt(&c.ISO, exifGroup, id, values, SourceExif)
+				break
+			}
+		}
+		setExifNumber(&c.FocalLength, exifGroup, 0x920a, values, SourceExif)
+		setExifText(&c.BodySerial, exifGroup, 0xa431, values, SourceExif)
+		setExifText(&c.LensModel, exifGroup, 0xa434, values, SourceExif)
+		setExifText(&c.LensSerial, exifGroup, 0xa435, values, SourceExif)
+	}
+
+	group := makerGroup(exif.Panasonic)
+	if values := exifValues(exifMeta, group, false); values != nil {
+		setExifText(&c.Firmware, group, 0x02, values, SourceExifMakerNotes)
+		setExifText(&c.BodySerial, group, 0x25, values, SourceExifMakerNotes)
+		setExifInt(&c.WhiteBalance, group, 0x44, values, SourceExifMakerNotes)
+		setExifText(&c.LensModel, group, 0x51, values, SourceExifMakerNotes)
+		setExifText(&c.LensSerial, group, 0x52, values, SourceExifMakerNotes)
+	}
+	group = makerGroup(exif.FUJIFILM)
+	if values := exifValues(exifMeta, group, false); values != nil {
+		setExifText(&c.BodySerial, group, 0x10, values, SourceExifMakerNotes)
+		if value := values[0x3803]; value != "" {
+			set(&c.Gamma, newField(ParseGamma(value), value, SourceExifMakerNotes, ExifPath(group, 0x3803)))
+		}
+		setExifNumber(&c.FormatFps, group, 0x3820, values, SourceExifMakerNotes)
+	}
+	group = makerGroup(exif.Canon)
+	if values := exifValues(exifMeta, group, false); values != nil {
+		setExifText(&c.Firmware, group, 0x7, values, SourceExifMakerNotes)
+		if value := values[0x95]; value != "" {
+			fill(&c.LensModel, newField(value, "", SourceExifMakerNotes, ExifPath(group, 0x95)))
+		}
+	}
+	group = makerGroup(exif.Canon, exif.GroupCanonProcessingInfo)
+	if values := exifValues(exifMeta, group, false); values != nil {
+		setExifInt(&c.WhiteBalance, group, 9, values, SourceExifMakerNotes)
+	}
+	group = makerGroup(exif.Canon, exif.GroupCanonLogInfo)
+	if values := exifValues(exifMeta, group, false); values != nil {
+		if value := values[9]; value != "" {
+			set(&c.Gamut, newField(ParseGamut(value), value, SourceExifMakerNotes, ExifPath(group, 9)))
+		}
+		if value := values[11]; value != "" {
+			set(&c.Gamma, newField(ParseGamma(value), value, SourceExifMakerNotes, ExifPath(group, 11)))
+		}
+	}
+}
+
+func ExifPath(group string, id uint16) string {
+	return fmt.Sprintf("EXIF/%s/0x%04x", group, id)
+}
+
+// setExifText skips empty value, setExifNumber and setExifInt skip value which is not a number
+func setExifText(current **Field[string], group string, id uint16, values map[uint16]string, source Source) {
+	if value := values[id]; value != "" {
+		set(current, newField(value, "", source, ExifPath(group, id)))
+	}
+}
+
+func setExifNumber(current **Field[float64], group string, id uint16, values map[uint16]string, source Source) {
+	if number, ok := parseNumber(values[id]); ok {
+		set(current, newField(number, rawIfNotNumber(values[id]), source, ExifPath(group, id)))
+	}
+}
+
+func setExifInt(current **Field[int], group string, id uint16, values map[uint16]string, source Source) {
+	if number, ok := parseNumber(values[id]); ok {
+		set(current, newField(int(math.Round(number)), rawIfNotNumber(values[id]), source, ExifPath(group, id)))
+	}
+}
@@ -0,0 +1,328 @@
+// Package normalize maps metadata of all vendors to a vendor-neutral camera model with typed values.
+// Output formats like DaVinci Resolve are layers on top of the model.
+package normalize
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/xmp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source metadata which a value is read from
+type Source string
+
+const (
+	SourceMP4                 Source = "MP4"
+	SourceExif                Source = "EXIF"
+	SourceExifMakerNotes      Source = "EXIF MakerNotes"
+	SourceNikonNCTG           Source = "Nikon NCTG"
+	SourcePanasonicClipMain   Source = "Panasonic ClipMain"
+	SourceSonyNonRealTimeMeta Source = "Sony NonRealTimeMeta"
+	SourceSonyRTMD            Source = "Sony RTMD"
+	SourceMetaItems           Source = "QuickTime metadata items"
+	SourceXMP                 Source = "XMP"
+	SourceAppleQuickTime      Source = "Apple QuickTime"
+	SourceMXF                 Source = "MXF"
+)
+
+// Field value with the source producing it
+type Field[T any] struct {
+	Value T
+	// Raw text as recorded by the source, empty if it is a plain number
+	Raw    string `json:",omitempty"`
+	Source Source
+	// Path box, tag or XML path of the value in the source
+	Path string
+	// Derived value is calculated from other values instead of read directly
+	Derived bool `json:",omitempty"`
+	// Discarded values of other sources, in the order of reading
+	Discarded []*Field[T] `json:",omitempty"`
+}
+
+func newField[T any](value T, raw string, source Source, path string) *Field[T] {
+	return &Field[T]{Value: value, Raw: raw, Source: source, Path: path}
+}
+
+// set replaces the current value, which is kept as discarded
+func set[T any](current **Field[T], field *Field[T]) {
+	if *current != nil {
+		previous := *current
+		field.Discarded = append(previous.Discarded, previous)
+		previous.Discarded = nil
+	}
+	*current = field
+}
+
+// fill sets the value only if there is none, otherwise field is kept as discarded
+func fill[T any](current **Field[T], field *Field[T]) {
+	if *current == nil {
+		*current = field
+		return
+	}
+	(*current).Discarded = append((*current).Discarded, field)
+}
+
+// Date recording time, Floating is true if the camera did not record the time zone and Time is in UTC
+type Date struct {
+	Time     time.Time
+	Floating bool `json:",omitempty"`
+}
+
+// Camera vendor-neutral camera metadata, nil field means no source has the value
+type Camera struct {
+	Manufacturer *Field[string]   `json:",omitempty"`
+	Model        *Field[string]   `json:",omitempty"`
+	BodySerial   *Field[string]   `json:",omitempty"`
+	Firmware     *Field[string]   `json:",omitempty"`
+	LensModel    *Field[string]   `json:",omitempty"`
+	LensSerial   *Field[string]   `json:",omitempty"`
+	DateRecorded *Field[Date]     `json:",omitempty"`
+	Timecode     *Field[Timecode] `json:",omitempty"`
+	// CaptureFps sensor frame rate, differs from FormatFps for slow and quick motion
+	CaptureFps *Field[float64] `json:",omitempty"`
+	FormatFps  *Field[float64] `json:",omitempty"`
+	// Speed capture frame rate compared with format frame rate, for slow & quick and time-lapse clips
+	Speed *Field[Speed] `json:",omitempty"`
+	// TimeLapseInterval seconds between captured frames
+	TimeLapseInterval *Field[float64] `json:",omitempty"`
+	ISO               *Field[int]     `json:",omitempty"`
+	// ExposureIndex EI of cinema cameras, ISO is the base sensitivity then
+	ExposureIndex *Field[int] `json:",omitempty"`
+	// ShutterSeconds exposure time in seconds
+	ShutterSeconds *Field[float64] `json:",omitempty"`
+	// ShutterAngle shutter angle in degrees
+	ShutterAngle *Field[float64] `json:",omitempty"`
+	FNumber      *Field[float64] `json:",omitempty"`
+	TStop        *Field[float64] `json:",omitempty"`
+	// FocalLength focal length in millimetres
+	FocalLength     *Field[float64] `json:",omitempty"`
+	FocalLength35mm *Field[float64] `json:",omitempty"`
+	// FocusDistance focus distance in metres
+	FocusDistance *Field[float64] `json:",omitempty"`
+	// WhiteBalance color temperature in Kelvin
+	WhiteBalance *Field[int]     `json:",omitempty"`
+	Tint         *Field[float64] `json:",omitempty"`
+	// Gamma Raw keeps the name used by camera, Value is empty if not recognized
+	Gamma *Field[Gamma] `json:",omitempty"`
+	Gamut *Field[Gamut] `json:",omitempty"`
+	// NDFilter attenuation of ND filter, 64 for ND 1/64 and 1 for clear
+	NDFilter *Field[float64] `json:",omitempty"`
+	// CDL ASC CDL applied in camera
+	CDL *Field[CDL] `json:",omitempty"`
+	// ShotMarks frames marked by camera operator
+	ShotMarks *Field[ShotMarks] `json:",omitempty"`
+	Audio     *Field[Audio]     `json:",omitempty"`
+	// RecordingFormat file format and encoding of the clip
+	RecordingFormat *Field[RecordingFormat] `json:",omitempty"`
+}
+
+// ShotMark frame marked in camera, Name is the mark recorded like _ShotMark1
+type ShotMark struct {
+	Frame int
+	Name  string
+}
+
+type ShotMarks []ShotMark
+
+// String formats marks like _ShotMark1@120, _ShotMark2@360
+func (marks ShotMarks) String() string {
+	texts := make([]string, len(marks))
+	for i, mark := range marks {
+		texts[i] = fmt.Sprintf("%s@%d", mark.Name, mark.Frame)
+	}
+	return strings.Join(texts, ", ")
+}
+
+// Audio audio layout of the clip
+type Audio struct {
+	Channels int
+	Codec    string `json:",omitempty"`
+	// Tracks track and its input like CH1 INPUT1
+	Tracks []string `json:",omitempty"`
+}
+
+// String formats layout like 4ch LPCM24
+func (a Audio) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%dch %s", a.Channels, a.Codec))
+}
+
+// RecordingFormat file format of the clip, Name is the format as in camera menu like XAVC HS 4K
+type RecordingFormat struct {
+	Name     string
+	Codec    string `json:",omitempty"`
+	Width    int    `json:",omitempty"`
+	Height   int    `json:",omitempty"`
+	Chroma   string `json:",omitempty"`
+	BitDepth int    `json:",omitempty"`
+	// GOP Long GOP or Intra
+	GOP string `json:",omitempty"`
+	// BitrateClass record setting like 100M or Class 300
+	BitrateClass string `json:",omitempty"`
+}
+
+// String formats format like XAVC HS 4K 4:2:2 10bit Long GOP 100M
+func (f RecordingFormat) String() string {
+	texts := []string{f.Name}
+	if f.Chroma != "" {
+		texts = append(texts, f.Chroma)
+	}
+	if f.BitDepth > 0 {
+		texts = append(texts, fmt.Sprintf("%dbit", f.BitDepth))
+	}
+	if f.GOP != "" {
+		texts = append(texts, f.GOP)
+	}
+	if f.BitrateClass != "" {
+		texts = append(texts, f.BitrateClass)
+	}
+	return strings.Join(texts, " ")
+}
+
+// Normalize reads all vendor metadata, sources are applied in the order of precedence, the later wins
+func Normalize(m *meta.Metadata) *Camera {
+	c := &Camera{}
+	if m.Mp4Meta != nil && m.Mp4Meta.CreationTime != nil {
+		set(&c.DateRecorded, newField(Date{Time: *m.Mp4Meta.CreationTime}, "", SourceMP4, "moov/mvhd/CreationTime"))
+	}
+	// average of variable frame rate is not a format frame rate
+	var trackFps *Field[float64]
+	if m.Mp4Meta != nil && m.Mp4Meta.VideoFrameRate != nil && !m.Mp4Meta.VideoFrameRate.Variable {
+		trackFps = newField(m.Mp4Meta.VideoFrameRate.Fps, "", SourceMP4, "moov/trak/mdia/minf/stbl/stts")
+		set(&c.FormatFps, trackFps)
+	}
+	if m.ExifMeta != nil {
+		c.fromExif(m.ExifMeta)
+	}
+	if m.MakerMeta.Nikon != nil && m.MakerMeta.Nikon.NCTG != nil {
+		c.fromNctg(m.MakerMeta.Nikon.NCTG)
+	}
+	if m.MakerMeta.Panasonic != nil && m.MakerMeta.Panasonic.MXF != nil {
+		c.fromMXF(m.MakerMeta.Panasonic.MXF)
+	}
+	if m.MakerMeta.Panasonic != nil && m.MakerMeta.Panasonic.ClipMain != nil {
+		c.fromPanasonicXML(m.MakerMeta.Panasonic.ClipMain)
+	}
+	if m.MakerMeta.Sony != nil {
+		if m.MakerMeta.Sony.NonRealTimeMeta != nil {
+			c.fromSonyXML(m.MakerMeta.Sony.NonRealTimeMeta, m.Mp4Meta)
+		}
+		if m.MakerMeta.Sony.RTMD != nil {
+			c.fromSonyRTMD(m.MakerMeta.Sony.RTMD)
+		}
+	}
+	if len(m.MetaItemKeyValues) > 0 {
+		c.fromMetaItems(m.MetaItemKeyValues)
+	}
+	if m.XMP != nil {
+		c.fromXMP(m.XMP)
+	}
+	if m.MakerMeta.Apple != nil && m.MakerMeta.Apple.QuickTime != nil {
+		c.fromAppleQuickTime(m.MakerMeta.Apple.QuickTime)
+	}
+	c.detectSpeed(trackFps)
+	c.deriveShutter(m.Mp4Meta != nil && m.Mp4Meta.VideoFrameRate != nil && m.Mp4Meta.VideoFrameRate.Variable)
+	return c
+}
+
+// deriveShutter calculates the missing one of shutter angle and exposure time with sensor frame rate,
+// angle = 360 × exposure time × fps. Format frame rate is used if capture frame rate is unknown,
+// except for variable frame rate recording whose sensor frame rate differs from track frame rate
+func (c *Camera) deriveShutter(variableFrameRate bool) {
+	fps := c.CaptureFps
+	if fps == nil && !variableFrameRate {
+		fps = c.FormatFps
+	}
+	if fps == nil || fps.Value <= 0 {
+		return
+	}
+	if c.ShutterAngle == nil && c.ShutterSeconds != nil && c.ShutterSeconds.Value > 0 {
+		c.ShutterAngle = derivedField(360*c.ShutterSeconds.Value*fps.Value, c.ShutterSeconds, fps)
+	} else if c.ShutterSeconds == nil && c.ShutterAngle != nil && c.ShutterAngle.Value > 0 {
+		c.ShutterSeconds = derivedField(c.ShutterAngle.Value/360/fps.Value, c.ShutterAngle, fps)
+	}
+}
+
+// derivedField Source is the source of value, Path has the paths of value and frame rate
+func derivedField(value float64, from, fps *Field[float64]) *Field[float64] {
+	field := newField(value, "", from.Source, from.Path+", "+fps.Path)
+	field.Derived = true
+	return field
+}
+
+func (c *Camera) fromMetaItems(itemsMap map[string]any) {
+	if value, ok := itemsMap["com.atomos.hdr.gamut"]; ok {
+		raw := fmt.Sprint(value)
+		set(&c.Gamut, newField(ParseGamut(raw), raw, SourceMetaItems, MetaItemPath("com.atomos.hdr.gamut")))
+	}
+	if value, ok := itemsMap["com.atomos.hdr.gamma"]; ok {
+		raw := fmt.Sprint(value)
+		set(&c.Gamma, newField(ParseGamma(raw), raw, SourceMetaItems, MetaItemPath("com.atomos.hdr.gamma")))
+	}
+	// written by Android for slow motion and time-lapse recording
+	if value, ok := itemsMap["com.android.capture.fps"]; ok {
+		if fps, ok := parseNumber(fmt.Sprint(value)); ok && fps > 0 {
+			set(&c.CaptureFps, newField(fps, "", SourceMetaItems, MetaItemPath("com.android.capture.fps")))
+		}
+	}
+	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Make"]; ok {
+		set(&c.Manufacturer, newField(fmt.Sprint(value), "", SourceMetaItems, MetaItemPath("com.apple.proapps.image.{TIFF}.Make")))
+	}
+	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Model"]; ok {
+		set(&c.Model, newField(fmt.Sprint(value), "", SourceMetaItems, MetaItemPath("com.apple.proapps.image.{TIFF}.Model")))
+	}
+	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Software"]; ok {
+		set(&c.Firmware, newField(fmt.Sprint(value), "", SourceMetaItems, MetaItemPath("com.apple.proapps.image.{TIFF}.Software")))
+	}
+}
+
+// MetaItemPath path of QuickTime metadata item, same for ExifPath and NCTGPath
+func MetaItemPath(key string) string {
+	return "moov/meta/" + key
+}
+
+// fromXMP only fills values missing in camera metadata
+func (c *Camera) fromXMP(x *xmp.XMP) {
+	if value := x.Get("tiff:Make"); value != "" {
+		fill(&c.Manufacturer, newField(value, "", SourceXMP, "XMP/tiff:Make"))
+	}
+	if value := x.Get("tiff:Model"); value != "" {
+		fill(&c.Model, newField(value, "", SourceXMP, "XMP/tiff:Model"))
+	}
+	if value := x.Get("exifEX:LensModel"); value != "" {
+		fill(&c.LensModel, newField(value, "", SourceXMP, "XMP/exifEX:LensModel"))
+	}
+}
+
+// parseNumber reads the first number of text like F/2.8, 1/50, 24.0 mm or 59.94p
+func parseNumber(s string) (float64, bool) {
+	start := strings.IndexAny(s, "0123456789")
+	if start < 0 {
+		return 0, false
+	}
+	end := start
+	for end < len(s) && strings.IndexByte("0123456789./", s[end]) >= 0 {
+		end++
+	}
+	text := strings.TrimRight(s[start:end], "./")
+	if numerator, denominator, ok := strings.Cut(text, "/"); ok {
+		n, err1 := strconv.ParseFloat(numerator, 64)
+		d, err2 := strconv.ParseFloat(denominator, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	return value, err == nil
+}
+
+// rawIfNotNumber keeps text which is more than a plain number, like Hi 0.3 or 5600K
+func rawIfNotNumber(s string) string {
+	if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return ""
+	}
+	return s
+}
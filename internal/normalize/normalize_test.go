@@ -0,0 +1,219 @@
+package normalize
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func fpsField(fps float64, source Source) *Field[float64] {
+	return newField(fps, "", source, string(source)+"/fps")
+}
+
+func TestDeriveShutter(t *testing.T) {
+	tests := []struct {
+		name          string
+		camera        Camera
+		variable      bool
+		wantAngle     float64
+		wantSeconds   float64
+		wantDerived   string
+		wantFpsInPath string
+	}{
+		{
+			name:          "180 degrees at 24 fps",
+			camera:        Camera{ShutterAngle: newField(180.0, "", SourceSonyRTMD, "rtmd/angle"), FormatFps: fpsField(24, SourceMP4)},
+			wantAngle:     180,
+			wantSeconds:   1.0 / 48,
+			wantDerived:   "ShutterSeconds",
+			wantFpsInPath: "MP4/fps",
+		},
+		{
+			name:          "exposure time at 24 fps",
+			camera:        Camera{ShutterSeconds: newField(1.0/48, "", SourceNikonNCTG, "NCTG/ExposureTime"), FormatFps: fpsField(24, SourceNikonNCTG)},
+			wantAngle:     180,
+			wantSeconds:   1.0 / 48,
+			wantDerived:   "ShutterAngle",
+			wantFpsInPath: "Nikon NCTG/fps",
+		},
+		{
+			name: "capture fps takes precedence",
+			camera: Camera{ShutterSeconds: newField(1.0/240, "", SourceSonyRTMD, "rtmd/time"),
+				CaptureFps: fpsField(120, SourceSonyRTMD), FormatFps: fpsField(24, SourceMP4)},
+			wantAngle:     180,
+			wantSeconds:   1.0 / 240,
+			wantDerived:   "ShutterAngle",
+			wantFpsInPath: "Sony RTMD/fps",
+		},
+		{
+			name: "overcranked variable frame rate",
+			camera: Camera{ShutterSeconds: newField(1.0/240, "", SourceSonyRTMD, "rtmd/time"),
+				CaptureFps: fpsField(120, SourceSonyRTMD), FormatFps: fpsField(24, SourceMP4)},
+			variable:      true,
+			wantAngle:     180,
+			wantSeconds:   1.0 / 240,
+			wantDerived:   "ShutterAngle",
+			wantFpsInPath: "Sony RTMD/fps",
+		},
+		{
+			name:        "variable frame rate without capture fps",
+			camera:      Camera{ShutterSeconds: newField(1.0/240, "", SourceExif, "EXIF/time"), FormatFps: fpsField(24, SourceMP4)},
+			variable:    true,
+			wantSeconds: 1.0 / 240,
+		},
+		{
+			name:        "missing fps",
+			camera:      Camera{ShutterSeconds: newField(1.0/50, "", SourceExif, "EXIF/time")},
+			wantSeconds: 1.0 / 50,
+		},
+		{
+			name:      "zero fps",
+			camera:    Camera{ShutterAngle: newField(180.0, "", SourceSonyRTMD, "rtmd/angle"), FormatFps: fpsField(0, SourceMP4)},
+			wantAngle: 180,
+		},
+		{
+			name: "both recorded",
+			camera: Camera{ShutterAngle: newField(172.8, "", SourceSonyRTMD, "rtmd/angle"),
+				ShutterSeconds: newField(1.0/50, "", SourceSonyRTMD, "rtmd/time"), FormatFps: fpsField(24, SourceMP4)},
+			wantAngle:   172.8,
+			wantSeconds: 1.0 / 50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.camera
+			c.deriveShutter(tt.variable)
+			check := func(name string, field *Field[float64], want float64) {
+				if want == 0 {
+					if field != nil {
+						t.Errorf("%s = %+v, want nil", name, field)
+					}
+					return
+				}
+				if field == nil || math.Abs(field.Value-want) > 1e-9 {
+					t.Fatalf("%s = %+v, want %v", name, field, want)
+				}
+				if field.Derived != (name == tt.wantDerived) {
+					t.Errorf("%s derived = %v", name, field.Derived)
+				}
+			}
+			check("ShutterAngle", c.ShutterAngle, tt.wantAngle)
+			check("ShutterSeconds", c.ShutterSeconds, tt.wantSeconds)
+			if tt.wantDerived == "ShutterAngle" && c.ShutterAngle.Source != c.ShutterSeconds.Source ||
+				tt.wantDerived == "ShutterSeconds" && c.ShutterSeconds.Source != c.ShutterAngle.Source {
+				t.Errorf("derived source differs from the source of value")
+			}
+			if tt.wantFpsInPath != "" {
+				field := c.ShutterAngle
+				if tt.wantDerived == "ShutterSeconds" {
+					field = c.ShutterSeconds
+				}
+				if !strings.HasSuffix(field.Path, ", "+tt.wantFpsInPath) {
+					t.Errorf("derived path = %q, want fps path %q", field.Path, tt.wantFpsInPath)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectSpeed(t *testing.T) {
+	tests := []struct {
+		name         string
+		capture      float64
+		format       float64
+		interval     *Field[float64]
+		wantMode     SpeedMode
+		wantFactor   float64
+		wantInterval float64
+	}{
+		{name: "normal", capture: 23.976, format: 23.98, wantMode: SpeedNormal, wantFactor: 1},
+		{name: "slow motion", capture: 120, format: 24, wantMode: SpeedOvercrank, wantFactor: 0.2},
+		{name: "quick motion", capture: 12, format: 24, wantMode: SpeedUndercrank, wantFactor: 2},
+		{name: "time-lapse", capture: 0.5, format: 24, wantMode: SpeedTimeLapse, wantFactor: 48, wantInterval: 2},
+		{name: "time-lapse of one second", capture: 1, format: 24, wantMode: SpeedTimeLapse, wantFactor: 24, wantInterval: 1},
+		{name: "time-lapse below 1 fps project", capture: 0.2, format: 0.5, wantMode: SpeedTimeLapse, wantFactor: 2.5, wantInterval: 5},
+		{
+			name: "recorded interval is kept", capture: 0.5, format: 24, wantMode: SpeedTimeLapse, wantFactor: 48, wantInterval: 10,
+			interval: newField(10.0, "", SourcePanasonicClipMain, "ClipMain/Interval"),
+		},
+		{name: "missing capture fps", format: 24},
+		{name: "missing format fps", capture: 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Camera{TimeLapseInterval: tt.interval}
+			if tt.capture > 0 {
+				c.CaptureFps = fpsField(tt.capture, SourceSonyNonRealTimeMeta)
+			}
+			if tt.format > 0 {
+				c.FormatFps = fpsField(tt.format, SourceMP4)
+			}
+			c.detectSpeed(nil)
+			if tt.wantMode == "" {
+				if c.Speed != nil {
+					t.Errorf("Speed = %+v, want nil", c.Speed.Value)
+				}
+				return
+			}
+			if c.Speed == nil {
+				t.Fatal("Speed is nil")
+			}
+			speed := c.Speed.Value
+			if speed.Mode != tt.wantMode || math.Abs(speed.Factor-tt.wantFactor) > 1e-9 || speed.Conform() != (tt.wantMode != SpeedNormal) {
+				t.Errorf("Speed = %+v, want %s with factor %v", speed, tt.wantMode, tt.wantFactor)
+			}
+			if !c.Speed.Derived || c.Speed.Source != SourceSonyNonRealTimeMeta {
+				t.Errorf("Speed field = %+v", c.Speed)
+			}
+			if tt.wantInterval == 0 {
+				if c.TimeLapseInterval != nil {
+					t.Errorf("TimeLapseInterval = %v", c.TimeLapseInterval.Value)
+				}
+			} else if c.TimeLapseInterval == nil || math.Abs(c.TimeLapseInterval.Value-tt.wantInterval) > 1e-9 {
+				t.Errorf("TimeLapseInterval = %+v, want %v", c.TimeLapseInterval, tt.wantInterval)
+			}
+		})
+	}
+}
+
+func TestDetectSpeedNikon(t *testing.T) {
+	tests := []struct {
+		name        string
+		frameRate   float64
+		track       float64
+		wantCapture float64
+		wantFormat  float64
+		wantMode    SpeedMode
+	}{
+		{name: "slow motion", frameRate: 120, track: 30, wantCapture: 120, wantFormat: 30, wantMode: SpeedOvercrank},
+		{name: "normal", frameRate: 29.97, track: 29.97, wantFormat: 29.97},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track := fpsField(tt.track, SourceMP4)
+			c := &Camera{}
+			set(&c.FormatFps, track)
+			set(&c.FormatFps, fpsField(tt.frameRate, SourceNikonNCTG))
+			c.detectSpeed(track)
+			if tt.wantCapture == 0 {
+				if c.CaptureFps != nil || c.Speed != nil || c.FormatFps.Source != SourceNikonNCTG {
+					t.Errorf("CaptureFps = %+v, Speed = %+v, FormatFps = %+v", c.CaptureFps, c.Speed, c.FormatFps)
+				}
+				return
+			}
+			if c.CaptureFps == nil || c.CaptureFps.Value != tt.wantCapture || c.CaptureFps.Source != SourceNikonNCTG {
+				t.Fatalf("CaptureFps = %+v", c.CaptureFps)
+			}
+			if c.FormatFps.Value != tt.wantFormat || c.FormatFps.Source != SourceMP4 {
+				t.Errorf("FormatFps = %+v", c.FormatFps)
+			}
+			// the frame rate of NCTG is kept, the track frame rate is not listed twice
+			if len(c.FormatFps.Discarded) != 1 || c.FormatFps.Discarded[0].Source != SourceNikonNCTG || c.FormatFps.Discarded[0].Value != tt.frameRate {
+				t.Errorf("FormatFps discarded = %+v", c.FormatFps.Discarded)
+			}
+			if c.Speed == nil || c.Speed.Value.Mode != tt.wantMode {
+				t.Errorf("Speed = %+v", c.Speed)
+			}
+		})
+	}
+}
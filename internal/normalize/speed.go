@@ -0,0 +1,79 @@
+package normalize
+
+import "math"
+
+// SpeedMode recording speed of a clip relative to its project frame rate
+type SpeedMode string
+
+const (
+	SpeedNormal SpeedMode = "normal"
+	// SpeedOvercrank sensor runs faster than the project, slow motion
+	SpeedOvercrank SpeedMode = "overcrank"
+	// SpeedUndercrank sensor runs slower than the project, quick motion
+	SpeedUndercrank SpeedMode = "undercrank"
+	// SpeedTimeLapse frames are captured at an interval of one second or longer
+	SpeedTimeLapse SpeedMode = "time-lapse"
+)
+
+// Speed sensor frame rate and project frame rate of the clip
+type Speed struct {
+	SensorFps  float64
+	ProjectFps float64
+	Mode       SpeedMode
+	// Factor playback speed at project frame rate, 0.25 for 4x slow motion
+	Factor float64
+}
+
+// Conform is true if the clip plays at a different speed than it was recorded
+func (s Speed) Conform() bool {
+	return s.Mode != SpeedNormal
+}
+
+// sameFps compares frame rates like 23.976 and 23.98
+func sameFps(a, b float64) bool {
+	return math.Abs(a-b) <= b*0.001+0.005
+}
+
+// detectSpeed compares capture frame rate with format frame rate. Nikon records the sensor frame rate
+// as FrameRate which differs from the track frame rate for slow motion, it is moved to capture frame rate
+// and kept as discarded format frame rate
+func (c *Camera) detectSpeed(track *Field[float64]) {
+	if c.CaptureFps == nil && track != nil && c.FormatFps != nil && c.FormatFps.Source == SourceNikonNCTG &&
+		!sameFps(c.FormatFps.Value, track.Value) {
+		nikon := c.FormatFps
+		c.CaptureFps = newField(nikon.Value, nikon.Raw, nikon.Source, nikon.Path)
+		c.FormatFps = newField(track.Value, "", track.Source, track.Path)
+		// the track frame rate replaced by NCTG is the value again
+		for _, discarded := range nikon.Discarded {
+			if discarded != track {
+				c.FormatFps.Discarded = append(c.FormatFps.Discarded, discarded)
+			}
+		}
+		nikon.Discarded = nil
+		c.FormatFps.Discarded = append(c.FormatFps.Discarded, nikon)
+	}
+	if c.CaptureFps == nil || c.FormatFps == nil || c.CaptureFps.Value <= 0 || c.FormatFps.Value <= 0 {
+		return
+	}
+	speed := Speed{
+		SensorFps:  c.CaptureFps.Value,
+		ProjectFps: c.FormatFps.Value,
+		Mode:       SpeedNormal,
+		Factor:     c.FormatFps.Value / c.CaptureFps.Value,
+	}
+	switch {
+	case sameFps(speed.SensorFps, speed.ProjectFps):
+		speed.Factor = 1
+	case speed.SensorFps <= 1:
+		speed.Mode = SpeedTimeLapse
+	case speed.SensorFps > speed.ProjectFps:
+		speed.Mode = SpeedOvercrank
+	default:
+		speed.Mode = SpeedUndercrank
+	}
+	c.Speed = newField(speed, "", c.CaptureFps.Source, c.CaptureFps.Path+", "+c.FormatFps.Path)
+	c.Speed.Derived = true
+	if speed.Mode == SpeedTimeLapse && c.TimeLapseInterval == nil {
+		c.TimeLapseInterval = derivedField(1/speed.SensorFps, c.CaptureFps, c.FormatFps)
+	}
+}
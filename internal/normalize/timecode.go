@@ -0,0 +1,46 @@
+package normalize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Timecode SMPTE timecode of the first frame
+type Timecode struct {
+	Hours     int
+	Minutes   int
+	Seconds   int
+	Frames    int
+	DropFrame bool `json:",omitempty"`
+}
+
+// String formats timecode like 01:00:00:00, frames of drop frame timecode are separated by ;
+func (t Timecode) String() string {
+	separator := ":"
+	if t.DropFrame {
+		separator = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", t.Hours, t.Minutes, t.Seconds, separator, t.Frames)
+}
+
+// ParseTimecode parses timecode like 01:00:00:00 or 01:00:00;00
+func ParseTimecode(s string) (Timecode, bool) {
+	s = strings.TrimSpace(s)
+	dropFrame := strings.ContainsAny(s, ";,")
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ':' || r == ';' || r == ',' || r == '.'
+	})
+	if len(parts) != 4 {
+		return Timecode{}, false
+	}
+	var values [4]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return Timecode{}, false
+		}
+		values[i] = value
+	}
+	return Timecode{Hours: values[0], Minutes: values[1], Seconds: values[2], Frames: values[3], DropFrame: dropFrame}, true
+}
@@ -0,0 +1,424 @@
+package normalize
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
+	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
+	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/mxf"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fromSonyXML mp4 has the bitrate of the record setting, nil if not read
+func (c *Camera) fromSonyXML(xml *nrtmd.NonRealTimeMeta, mp4 *meta.Mp4Meta) {
+	if xml.CreationDate.Value != "" {
+		set(&c.DateRecorded, textDate(xml.CreationDate.Value, SourceSonyNonRealTimeMeta, "NonRealTimeMeta/CreationDate@value"))
+	}
+	if xml.Device.ModelName != "" {
+		set(&c.Model, newField(xml.Device.ModelName, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/Device@modelName"))
+	}
+	if xml.Device.Manufacturer != "" {
+		set(&c.Manufacturer, newField(xml.Device.Manufacturer, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/Device@manufacturer"))
+	}
+	if xml.Device.SerialNo != "" {
+		set(&c.BodySerial, newField(xml.Device.SerialNo, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/Device@serialNo"))
+	}
+	if xml.Lens.ModelName != "" {
+		set(&c.LensModel, newField(xml.Lens.ModelName, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/Lens@modelName"))
+	}
+	if timecode, ok := xml.LtcChangeTable.StartTimecode(); ok {
+		set(&c.Timecode, newField(Timecode{
+			Hours:     timecode.Hour,
+			Minutes:   timecode.Min,
+			Seconds:   timecode.Sec,
+			Frames:    timecode.Frame,
+			DropFrame: timecode.DropFrame,
+		}, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/LtcChangeTable/LtcChange@value"))
+	}
+	if marks := xml.KlvPacketTable.ShotMarks(); len(marks) > 0 {
+		shotMarks := make(ShotMarks, len(marks))
+		for i, mark := range marks {
+			shotMarks[i] = ShotMark{Frame: mark.FrameCount, Name: mark.Name}
+		}
+		set(&c.ShotMarks, newField(shotMarks, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/KlvPacketTable/KlvPacket@lengthValue"))
+	}
+	if format := xml.AudioFormat; format.NumOfChannel > 0 {
+		audio := Audio{Channels: format.NumOfChannel}
+		for _, port := range format.AudioRecPorts {
+			if audio.Codec == "" {
+				audio.Codec = port.AudioCodec
+			}
+			audio.Tracks = append(audio.Tracks, strings.TrimSpace(port.TrackDst+" "+port.Port))
+		}
+		set(&c.Audio, newField(audio, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/AudioFormat"))
+	}
+	// frame rate like 25p or 59.94i
+	if fps, ok := parseNumber(xml.VideoFormat.VideoFrame.FormatFps); ok {
+		set(&c.FormatFps, newField(fps, xml.VideoFormat.VideoFrame.FormatFps, SourceSonyNonRealTimeMeta, "NonRealTimeMeta/VideoFormat/VideoFrame@formatFps"))
+	}
+	if fps, ok := parseNumber(xml.VideoFormat.VideoFrame.CaptureFps); ok {
+		set(&c.CaptureFps, newField(fps, xml.VideoFormat.VideoFrame.CaptureFps, SourceSonyNonRealTimeMeta, "NonRealTimeMeta/VideoFormat/VideoFrame@captureFps"))
+	}
+	if format, ok := xml.VideoFormat.XAVC(); ok {
+		recordingFormat := RecordingFormat{
+			Name:     format.Name,
+			Codec:    format.Codec,
+			Width:    format.Width,
+			Height:   format.Height,
+			Chroma:   format.Chroma,
+			BitDepth: format.BitDepth,
+			GOP:      format.GOP,
+		}
+		if fps, ok := parseNumber(xml.VideoFormat.VideoFrame.FormatFps); ok && mp4 != nil && mp4.VideoProfile != nil {
+			// field rate of interlaced formats
+			if xml.VideoFormat.VideoFrame.Interlaced() {
+				fps /= 2
+			}
+			recordingFormat.BitrateClass = format.BitrateClass(mp4.VideoProfile.VideoAvgBitrateKbps, fps)
+		}
+		set(&c.RecordingFormat, newField(recordingFormat, "", SourceSonyNonRealTimeMeta, "NonRealTimeMeta/VideoFormat/VideoFrame@videoCodec"))
+	}
+	for _, group := range xml.AcquisitionRecord.Groups {
+		if group.Name == nrtmd.CameraUnitMetadataSet {
+			for _, item := range group.Items {
+				if item.Name == nrtmd.CaptureGammaEquation {
+					set(&c.Gamma, newField(ParseGamma(item.Value), item.Value, SourceSonyNonRealTimeMeta, sonyItemPath(item.Name)))
+				} else if item.Name == nrtmd.CaptureColorPrimaries {
+					set(&c.Gamut, newField(ParseGamut(item.Value), item.Value, SourceSonyNonRealTimeMeta, sonyItemPath(item.Name)))
+				}
+			}
+			break
+		}
+	}
+}
+
+func (c *Camera) fromSonyRTMD(rtmd *rtmd.RTMD) {
+	if rtmd.Timecode != nil {
+		// the frame header does not record drop frame, keep the one of NRT XML
+		dropFrame := c.Timecode != nil && c.Timecode.Value.DropFrame
+		set(&c.Timecode, newField(Timecode{
+			Hours:     rtmd.Timecode.Hour,
+			Minutes:   rtmd.Timecode.Min,
+			Seconds:   rtmd.Timecode.Sec,
+			Frames:    rtmd.Timecode.Frame,
+			DropFrame: dropFrame,
+		}, "", SourceSonyRTMD, "rtmd/Timecode"))
+	}
+	if camera := rtmd.CameraUnitMetadata; camera != nil {
+		if camera.WhiteBalance > 0 {
+			set(&c.WhiteBalance, newField(int(camera.WhiteBalance), "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/WhiteBalance"))
+		}
+		// the fraction is kept as recorded, like 1001/48000
+		if seconds, ok := rationalValue(camera.ShutterSpeedTime); ok {
+			set(&c.ShutterSeconds, newField(seconds, camera.ShutterSpeedTime.String(), SourceSonyRTMD, "rtmd/CameraUnitMetadata/ShutterSpeedTime"))
+		}
+		if camera.ShutterSpeedAngle > 0 {
+			set(&c.ShutterAngle, newField(camera.ShutterSpeedAngle, "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/ShutterSpeedAngle"))
+		}
+		if fps, ok := rationalValue(camera.CaptureFrameRate); ok {
+			fill(&c.CaptureFps, newField(fps, "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/CaptureFrameRate"))
+		}
+		if camera.ISOSensitivity > 0 {
+			set(&c.ISO, newField(int(camera.ISOSensitivity), "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/ISOSensitivity"))
+		}
+		if camera.NDFilter > 0 {
+			set(&c.NDFilter, newField(float64(camera.NDFilter), "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/NDFilter"))
+		}
+		if cdl := camera.ASCCDL; cdl != nil {
+			set(&c.CDL, newField(CDL{Slope: cdl.Slope, Offset: cdl.Offset, Power: cdl.Power, Saturation: cdl.Saturation}, "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/ASCCDL"))
+		}
+		if camera.ExposureIndexOfPhotoMeter > 0 {
+			set(&c.ExposureIndex, newField(int(camera.ExposureIndexOfPhotoMeter), "", SourceSonyRTMD, "rtmd/CameraUnitMetadata/ExposureIndexOfPhotoMeter"))
+		}
+		// NonRealTimeMeta has the names of profile, keep them
+		if camera.ColorPrimaries != "" {
+			fill(&c.Gamut, newField(ParseGamut(camera.ColorPrimaries), camera.ColorPrimaries, SourceSonyRTMD, "rtmd/CameraUnitMetadata/ColorPrimaries"))
+		}
+		if camera.CaptureGammaEquation != "" {
+			fill(&c.Gamma, newField(ParseGamma(camera.CaptureGammaEquation), camera.CaptureGammaEquation, SourceSonyRTMD, "rtmd/CameraUnitMetadata/CaptureGammaEquation"))
+		}
+	}
+	if lens := rtmd.LensUnitMetadata; lens != nil {
+		if lens.LensAttributes != "" {
+			set(&c.LensModel, newField(lens.LensAttributes, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/LensAttributes"))
+		}
+		if lens.IrisFNumber > 0 {
+			set(&c.FNumber, newField(lens.IrisFNumber, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/IrisFNumber"))
+		}
+		if lens.IrisTNumber > 0 {
+			set(&c.TStop, newField(lens.IrisTNumber, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/IrisTNumber"))
+		}
+		// zoom position in metres
+		if lens.LensZoomPtr != 0 {
+			set(&c.FocalLength, newField(lens.LensZoomPtr*1000, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/LensZoomPtr"))
+		}
+		if lens.LensZoom35mmPtr != 0 {
+			set(&c.FocalLength35mm, newField(lens.LensZoom35mmPtr*1000, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/LensZoom35mmPtr"))
+		}
+		if lens.FocusPositionFromImagePlane > 0 {
+			set(&c.FocusDistance, newField(lens.FocusPositionFromImagePlane, "", SourceSonyRTMD, "rtmd/LensUnitMetadata/FocusPositionFromImagePlane"))
+		}
+	}
+}
+
+const (
+	panasonicClipPath   = "ClipMain/ClipContent/"
+	panasonicCameraPath = "ClipMain/UserArea/AcquisitionMetadata/CameraUnitMetadata/"
+	panasonicLensPath   = "ClipMain/UserArea/AcquisitionMetadata/LensUnitMetadata/"
+)
+
+// setPanasonicText skips empty value, setPanasonicNumber and setPanasonicInt skip value which is not a number
+func setPanasonicText(current **Field[string], value, path string) {
+	if value != "" {
+		set(current, newField(value, "", SourcePanasonicClipMain, path))
+	}
+}
+
+func setPanasonicNumber(current **Field[float64], value, path string) {
+	if number, ok := parseNumber(value); ok {
+		set(current, newField(number, rawIfNotNumber(value), SourcePanasonicClipMain, path))
+	}
+}
+
+func setPanasonicInt(current **Field[int], value, path string) {
+	if number, ok := parseNumber(value); ok {
+		set(current, newField(int(math.Round(number)), rawIfNotNumber(value), SourcePanasonicClipMain, path))
+	}
+}
+
+func (c *Camera) fromPanasonicXML(xml *panasonic.ClipMain) {
+	clip, camera, lens := xml.ClipContent.ClipMetadata, xml.CameraUnitMetadata, xml.LensUnitMetadata
+	if clip.Shoot.StartDate != "" {
+		set(&c.DateRecorded, textDate(clip.Shoot.StartDate, SourcePanasonicClipMain, panasonicClipPath+"ClipMetadata/Shoot/StartDate"))
+	} else if clip.Access.CreationDate != "" {
+		set(&c.DateRecorded, textDate(clip.Access.CreationDate, SourcePanasonicClipMain, panasonicClipPath+"ClipMetadata/Access/CreationDate"))
+	}
+	setPanasonicText(&c.Manufacturer, clip.Device.Manufacturer, panasonicClipPath+"ClipMetadata/Device/Manufacturer")
+	setPanasonicText(&c.Model, clip.Device.ModelName, panasonicClipPath+"ClipMetadata/Device/ModelName")
+	setPanasonicText(&c.BodySerial, clip.Device.SerialNo, panasonicClipPath+"ClipMetadata/Device/SerialNo.")
+	setPanasonicInt(&c.ISO, camera.ISOSensitivity, panasonicCameraPath+"ISOSensitivity")
+	if fps, ok := parseNumber(xml.ClipContent.Video.FrameRate); ok {
+		set(&c.FormatFps, newField(fps, rawIfNotNumber(xml.ClipContent.Video.FrameRate), SourcePanasonicClipMain, "ClipMain/ClipContent/EssenceList/Video/FrameRate"))
+	}
+	// capture frame rate is the format frame rate if VFR is off
+	if camera.VFR == "" || camera.IsVFR() {
+		if fps, ok := parseNumber(camera.CaptureFrameRate); ok {
+			set(&c.CaptureFps, newField(fps, rawIfNotNumber(camera.CaptureFrameRate), SourcePanasonicClipMain, panasonicCameraPath+"CaptureFrameRate"))
+		}
+	}
+	if timecode, ok := ParseTimecode(xml.ClipContent.Video.StartTimecode); ok {
+		set(&c.Timecode, newField(timecode, "", SourcePanasonicClipMain, "ClipMain/ClipContent/EssenceList/Video/StartTimecode"))
+	}
+	if gamma := camera.Gamma.CaptureGamma; gamma != "" {
+		set(&c.Gamma, newField(ParseGamma(gamma), gamma, SourcePanasonicClipMain, panasonicCameraPath+"Gamma/CaptureGamma"))
+	}
+	if gamut := camera.Gamut.CaptureGamut; gamut != "" {
+		set(&c.Gamut, newField(ParseGamut(gamut), gamut, SourcePanasonicClipMain, panasonicCameraPath+"Gamut/CaptureGamut"))
+	}
+	setPanasonicInt(&c.ExposureIndex, camera.ExposureIndex, panasonicCameraPath+"ExposureIndex")
+	setPanasonicNumber(&c.ShutterAngle, camera.ShutterSpeedAngle, panasonicCameraPath+"ShutterSpeedAngle")
+	setPanasonicNumber(&c.ShutterSeconds, camera.ShutterSpeedTime, panasonicCameraPath+"ShutterSpeedTime")
+	setPanasonicInt(&c.WhiteBalance, camera.WhiteBalance.ColorTemperature, panasonicCameraPath+"WhiteBalance/ColorTemperature")
+	if tint, err := strconv.ParseFloat(strings.TrimSpace(camera.WhiteBalance.Tint), 64); err == nil {
+		set(&c.Tint, newField(tint, "", SourcePanasonicClipMain, panasonicCameraPath+"WhiteBalance/Tint"))
+	}
+	// ND filter like 1/16
+	if nd := camera.NeutralDensityFilterWheelSetting; strings.EqualFold(nd, "CLEAR") {
+		set(&c.NDFilter, newField(1.0, nd, SourcePanasonicClipMain, panasonicCameraPath+"NeutralDensityFilterWheelSetting"))
+	} else if fraction, ok := parseNumber(nd); ok && fraction > 0 && fraction <= 1 {
+		set(&c.NDFilter, newField(1/fraction, nd, SourcePanasonicClipMain, panasonicCameraPath+"NeutralDensityFilterWheelSetting"))
+	}
+	setPanasonicText(&c.LensModel, lens.LensAttributes, panasonicLensPath+"LensAttributes")
+	setPanasonicNumber(&c.FNumber, lens.IrisFNumber, panasonicLensPath+"IrisFNumber")
+	setPanasonicNumber(&c.TStop, lens.IrisTNumber, panasonicLensPath+"IrisTNumber")
+	setPanasonicNumber(&c.FocusDistance, lens.FocusPositionFromImagePlane, panasonicLensPath+"FocusPositionFromImagePlane")
+	setPanasonicNumber(&c.FocalLength, lens.LensZoomActualFocalLength, panasonicLensPath+"LensZoomActualFocalLength")
+	setPanasonicNumber(&c.FocalLength35mm, lens.LensZoom35mmStillCameraEquivalent, panasonicLensPath+"LensZoom35mmStillCameraEquivalent")
+	// shot mark of the clip is at the first frame, text memos are marks at their frames
+	var shotMarks ShotMarks
+	if clip.ShotMark {
+		shotMarks = append(shotMarks, ShotMark{Name: "ShotMark"})
+	}
+	for _, memo := range clip.Memos {
+		name := memo.Text
+		if name == "" {
+			name = "Memo"
+		}
+		shotMarks = append(shotMarks, ShotMark{Frame: memo.Offset, Name: name})
+	}
+	if len(shotMarks) > 0 {
+		set(&c.ShotMarks, newField(shotMarks, "", SourcePanasonicClipMain, panasonicClipPath+"ClipMetadata/ShotMark"))
+	}
+	if channels := xml.ClipContent.Channels(); channels > 0 {
+		audio := Audio{Channels: channels}
+		if bits := xml.ClipContent.Audio[0].BitsPerSample; bits != "" {
+			audio.Codec = "LPCM" + bits
+		}
+		set(&c.Audio, newField(audio, "", SourcePanasonicClipMain, panasonicClipPath+"EssenceList/Audio"))
+	}
+	if format, ok := panasonicRecordingFormat(xml.ClipContent.Video); ok {
+		set(&c.RecordingFormat, newField(format, "", SourcePanasonicClipMain, panasonicClipPath+"EssenceList/Video/Codec"))
+	}
+}
+
+// panasonicRecordingFormat codec like HEVC_LongGOP, H.264_ALL-I or AVC-I_1080/59.94i with bitrate in Mbps
+func panasonicRecordingFormat(video panasonic.Video) (RecordingFormat, bool) {
+	name := strings.TrimSpace(video.Codec.Codec)
+	if name == "" {
+		return RecordingFormat{}, false
+	}
+	format := RecordingFormat{Name: name}
+	format.Codec, _, _ = strings.Cut(name, "_")
+	format.Width, _ = strconv.Atoi(video.ActivePixel)
+	format.Height, _ = strconv.Atoi(video.ActiveLine)
+	format.BitDepth, _ = strconv.Atoi(video.BitDepth)
+	upper := strings.ToUpper(name)
+	if strings.Contains(upper, "LONGGOP") || strings.Contains(upper, "LONG GOP") {
+		format.GOP = "Long GOP"
+	} else if strings.Contains(upper, "ALL-I") || strings.Contains(upper, "INTRA") || strings.HasPrefix(upper, "AVC-I") {
+		format.GOP = "Intra"
+	}
+	if video.Codec.Class != "" {
+		format.BitrateClass = "Class " + video.Codec.Class
+	} else if bitrate, ok := parseNumber(video.Codec.BitRate); ok {
+		format.BitrateClass = strconv.FormatFloat(bitrate, 'f', -1, 64) + "M"
+	}
+	return format, true
+}
+
+// fromMXF header metadata of P2 video, clip XML has the same values and takes precedence
+func (c *Camera) fromMXF(header *mxf.Header) {
+	if header.CompanyName != "" {
+		set(&c.Manufacturer, newField(header.CompanyName, "", SourceMXF, "MXF/Identification/CompanyName"))
+	}
+	if header.ProductName != "" {
+		set(&c.Model, newField(header.ProductName, "", SourceMXF, "MXF/Identification/ProductName"))
+	}
+	if header.ProductVersion != "" {
+		set(&c.Firmware, newField(header.ProductVersion, "", SourceMXF, "MXF/Identification/VersionString"))
+	}
+	if header.ModificationDate != nil {
+		set(&c.DateRecorded, newField(Date{Time: *header.ModificationDate}, "", SourceMXF, "MXF/Identification/ModificationDate"))
+	}
+	if hours, minutes, seconds, frames, ok := header.Timecode(); ok {
+		set(&c.Timecode, newField(Timecode{Hours: hours, Minutes: minutes, Seconds: seconds, Frames: frames, DropFrame: header.DropFrame},
+			"", SourceMXF, "MXF/TimecodeComponent/StartTimecode"))
+	}
+	if header.EditRate > 0 {
+		set(&c.FormatFps, newField(header.EditRate, "", SourceMXF, "MXF/PictureDescriptor/SampleRate"))
+	}
+}
+
+// fromNctg NCTG without a valid create date is ignored as a whole
+func (c *Camera) fromNctg(nctg *nikon.NCTG) {
+	parse, err := time.Parse("2006:01:02 15:04:05-07:00", nctg.CreateDate+nctg.TimeZone)
+	if err != nil {
+		return
+	}
+	set(&c.DateRecorded, newField(Date{Time: parse}, "", SourceNikonNCTG, NCTGPath("CreateDate")))
+	if nctg.Model != "" {
+		set(&c.Model, newField(nctg.Model, "", SourceNikonNCTG, NCTGPath("Model")))
+	}
+	if nctg.Make != "" {
+		set(&c.Manufacturer, newField(nctg.Make, "", SourceNikonNCTG, NCTGPath("Make")))
+	}
+	if nctg.SerialNumber != "" {
+		set(&c.BodySerial, newField(nctg.SerialNumber, "", SourceNikonNCTG, NCTGPath("SerialNumber")))
+	}
+	if nctg.Software != "" {
+		set(&c.Firmware, newField(nctg.Software, "", SourceNikonNCTG, NCTGPath("Software")))
+	}
+	if nctg.LensModel != "" {
+		set(&c.LensModel, newField(nctg.LensModel, "", SourceNikonNCTG, NCTGPath("LensModel")))
+	}
+	if nctg.LensSerialNumber != "" {
+		set(&c.LensSerial, newField(nctg.LensSerialNumber, "", SourceNikonNCTG, NCTGPath("LensSerialNumber")))
+	}
+	if fps, ok := fractionValue(nctg.FrameRate); ok {
+		set(&c.FormatFps, newField(fps, "", SourceNikonNCTG, NCTGPath("FrameRate")))
+	}
+	if seconds, ok := fractionValue(nctg.ExposureTime); ok {
+		set(&c.ShutterSeconds, newField(seconds, nctg.ExposureTime.ShutterFormat(), SourceNikonNCTG, NCTGPath("ExposureTime")))
+	}
+	if fNumber, ok := fractionValue(nctg.FNumber); ok {
+		set(&c.FNumber, newField(fNumber, "", SourceNikonNCTG, NCTGPath("FNumber")))
+	}
+	if focalLength, ok := fractionValue(nctg.FocalLength); ok {
+		set(&c.FocalLength, newField(focalLength, nctg.FocalLength.FocalLengthFormat(), SourceNikonNCTG, NCTGPath("FocalLength")))
+	}
+	if nctg.ISOInfo != nil {
+		if iso, err := strconv.Atoi(strings.TrimSpace(nctg.ISOInfo.ISO)); err == nil {
+			raw := ""
+			if nctg.ISOInfo.ISOExpansion != "Off" {
+				// like Hi 0.3 25600
+				raw = fmt.Sprintf("%s %s", nctg.ISOInfo.ISOExpansion, nctg.ISOInfo.ISO)
+			}
+			set(&c.ISO, newField(iso, raw, SourceNikonNCTG, NCTGPath("ISOInfo")))
+		}
+	}
+	if kelvin, err := strconv.Atoi(strings.TrimRight(nctg.WhiteBalance, "K")); err == nil {
+		set(&c.WhiteBalance, newField(kelvin, rawIfNotNumber(nctg.WhiteBalance), SourceNikonNCTG, NCTGPath("WhiteBalance")))
+	}
+	if nctg.PictureControlData != nil && nctg.PictureControlData.PictureControlBase == "N-LOG" {
+		set(&c.Gamma, newField(GammaNLog, nctg.PictureControlData.PictureControlBase, SourceNikonNCTG, NCTGPath("PictureControlData")))
+	}
+}
+
+func (c *Camera) fromAppleQuickTime(q *apple.QuickTimeMeta) {
+	if q.Make != "" {
+		set(&c.Manufacturer, newField(q.Make, "", SourceAppleQuickTime, MetaItemPath(apple.KeyMake)))
+	}
+	if q.Model != "" {
+		set(&c.Model, newField(q.Model, "", SourceAppleQuickTime, MetaItemPath(apple.KeyModel)))
+	}
+	if q.Software != "" {
+		set(&c.Firmware, newField(q.Software, "", SourceAppleQuickTime, MetaItemPath(apple.KeySoftware)))
+	}
+	if t := q.GetCreationTime(); t != nil {
+		set(&c.DateRecorded, newField(Date{Time: *t}, "", SourceAppleQuickTime, MetaItemPath(apple.KeyCreationDate)))
+	}
+	if q.LensModel != "" {
+		set(&c.LensModel, newField(q.LensModel, "", SourceAppleQuickTime, MetaItemPath(apple.KeyCameraLensModel)))
+	}
+	if q.FocalLength35mm > 0 {
+		set(&c.FocalLength35mm, newField(q.FocalLength35mm, "", SourceAppleQuickTime, MetaItemPath(apple.KeyCameraFocalLength35mm)))
+	}
+}
+
+func sonyItemPath(name string) string {
+	return fmt.Sprintf("NonRealTimeMeta/AcquisitionRecord/Group[@name=%q]/Item[@name=%q]", nrtmd.CameraUnitMetadataSet, name)
+}
+
+func NCTGPath(tag string) string {
+	return "moov/udta/NCDT/NCTG/" + tag
+}
+
+// textDate keeps the recorded text, Time is zero if it is not RFC 3339
+func textDate(raw string, source Source, path string) *Field[Date] {
+	date := Date{}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		date.Time = t
+	}
+	return newField(date, raw, source, path)
+}
+
+func rationalValue(r *common.Rational) (float64, bool) {
+	if r == nil || r.Numerator == 0 || r.Denominator == 0 {
+		return 0, false
+	}
+	return float64(r.Numerator) / float64(r.Denominator), true
+}
+
+func fractionValue(f *common.UFraction) (float64, bool) {
+	if f == nil || f.Numerator == 0 || f.Denominator == 0 {
+		return 0, false
+	}
+	return float64(f.Numerator) / float64(f.Denominator), true
+}
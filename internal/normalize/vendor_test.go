@@ -0,0 +1,97 @@
+package normalize
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
+	"github.com/fukco/media-metadata/internal/meta"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readClipMain(t *testing.T) *panasonic.ClipMain {
+	t.Helper()
+	f, err := os.Open("../manufacturer/panasonic/testdata/ClipMain.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	clipMain, err := panasonic.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clipMain
+}
+
+func TestFromPanasonicXML(t *testing.T) {
+	c := Normalize(&meta.Metadata{MakerMeta: &meta.MakerMeta{Panasonic: &meta.Panasonic{ClipMain: readClipMain(t)}}})
+	tests := []struct {
+		field string
+		value string
+		raw   string
+		path  string
+	}{
+		{"Manufacturer", "Panasonic", "", panasonicClipPath + "ClipMetadata/Device/Manufacturer"},
+		{"Model", "AU-EVA1", "", panasonicClipPath + "ClipMetadata/Device/ModelName"},
+		{"BodySerial", "C1TA12345", "", panasonicClipPath + "ClipMetadata/Device/SerialNo."},
+		{"DateRecorded", "{2024-05-01 10:00:00 +0900 +0900 false}", "2024-05-01T10:00:00+09:00", panasonicClipPath + "ClipMetadata/Shoot/StartDate"},
+		{"Timecode", "12:34:56:10", "", "ClipMain/ClipContent/EssenceList/Video/StartTimecode"},
+		{"FormatFps", "23.98", "23.98p", "ClipMain/ClipContent/EssenceList/Video/FrameRate"},
+		{"CaptureFps", "59.94", "", panasonicCameraPath + "CaptureFrameRate"},
+		{"ISO", "800", "", panasonicCameraPath + "ISOSensitivity"},
+		{"ExposureIndex", "2500", "", panasonicCameraPath + "ExposureIndex"},
+		{"ShutterAngle", "180", "", panasonicCameraPath + "ShutterSpeedAngle"},
+		{"WhiteBalance", "5600", "5600K", panasonicCameraPath + "WhiteBalance/ColorTemperature"},
+		{"Tint", "-3.5", "", panasonicCameraPath + "WhiteBalance/Tint"},
+		{"NDFilter", "16", "1/16", panasonicCameraPath + "NeutralDensityFilterWheelSetting"},
+		{"Gamma", string(GammaVLog), "V-Log", panasonicCameraPath + "Gamma/CaptureGamma"},
+		{"Gamut", string(GamutVGamut), "V-Gamut", panasonicCameraPath + "Gamut/CaptureGamut"},
+		{"LensModel", "LUMIX G X VARIO 12-35mm F2.8", "", panasonicLensPath + "LensAttributes"},
+		{"FNumber", "2.8", "", panasonicLensPath + "IrisFNumber"},
+		{"FocusDistance", "3.2", "", panasonicLensPath + "FocusPositionFromImagePlane"},
+		{"FocalLength", "25", "", panasonicLensPath + "LensZoomActualFocalLength"},
+		{"FocalLength35mm", "50", "", panasonicLensPath + "LensZoom35mmStillCameraEquivalent"},
+		{"ShotMarks", "ShotMark@0, laugh@240, Memo@960", "", panasonicClipPath + "ClipMetadata/ShotMark"},
+		{"Audio", "2ch LPCM24", "", panasonicClipPath + "EssenceList/Audio"},
+		{"RecordingFormat", "AVC-I_4K422/23.98p 10bit Intra Class 4K422", "", panasonicClipPath + "EssenceList/Video/Codec"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			field := reflect.ValueOf(c).Elem().FieldByName(tt.field)
+			if field.IsNil() {
+				t.Fatal("field is nil")
+			}
+			field = field.Elem()
+			value := fmt.Sprint(field.FieldByName("Value").Interface())
+			raw, source, path := field.FieldByName("Raw").String(), field.FieldByName("Source").Interface(), field.FieldByName("Path").String()
+			if value != tt.value || raw != tt.raw || source != SourcePanasonicClipMain || path != tt.path {
+				t.Errorf("got %q raw %q from %v %s, want %q raw %q from %s", value, raw, source, path, tt.value, tt.raw, tt.path)
+			}
+		})
+	}
+	// VFR clip is overcranked, exposure time is derived from the capture frame rate
+	if c.Speed == nil || c.Speed.Value.Mode != SpeedOvercrank {
+		t.Errorf("Speed = %+v", c.Speed)
+	}
+	if c.ShutterSeconds == nil || !c.ShutterSeconds.Derived || fmt.Sprintf("%.5f", c.ShutterSeconds.Value) != fmt.Sprintf("%.5f", 0.5/59.94) {
+		t.Errorf("ShutterSeconds = %+v", c.ShutterSeconds)
+	}
+	if c.TStop != nil {
+		t.Errorf("TStop = %+v, want nil", c.TStop)
+	}
+}
+
+// TestFromPanasonicXMLVFROff capture frame rate is ignored if VFR is off
+func TestFromPanasonicXMLVFROff(t *testing.T) {
+	clipMain := readClipMain(t)
+	clipMain.CameraUnitMetadata.VFR = "OFF"
+	clipMain.ClipContent.ClipMetadata.Shoot.StartDate = ""
+	c := &Camera{}
+	c.fromPanasonicXML(clipMain)
+	if c.CaptureFps != nil {
+		t.Errorf("CaptureFps = %+v, want nil", c.CaptureFps)
+	}
+	if c.DateRecorded == nil || c.DateRecorded.Path != panasonicClipPath+"ClipMetadata/Access/CreationDate" {
+		t.Errorf("DateRecorded = %+v", c.DateRecorded)
+	}
+}
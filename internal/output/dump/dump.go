@@ -0,0 +1,194 @@
+package dump
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/fukco/media-metadata/internal/box"
+	"io"
+	"strings"
+)
+
+const DefaultHexLimit = 256
+
+// Options options of box tree dump
+type Options struct {
+	// HexTypes box types whose payload is dumped in hex, like stsd or uuid
+	HexTypes []string
+	// HexLimit max bytes of each hex dump
+	HexLimit int
+}
+
+// Box box information of dump
+type Box struct {
+	Type       string
+	UserType   string `json:",omitempty"`
+	Offset     uint64
+	Size       uint64
+	HeaderSize uint64
+	Version    *uint8 `json:",omitempty"`
+	Flags      string `json:",omitempty"`
+	Supported  bool
+	Fields     box.Boxer `json:",omitempty"`
+	Error      string    `json:",omitempty"`
+	Truncated  bool      `json:",omitempty"`
+	Hex        string    `json:",omitempty"`
+	Children   []*Box    `json:",omitempty"`
+}
+
+// Result box tree of file and the parsing error if any
+type Result struct {
+	Boxes []*Box
+	Error string `json:",omitempty"`
+}
+
+// Read reads the full box tree including unsupported boxes, boxes read before a parsing error are kept
+func Read(r io.ReadSeeker, opts *Options) (*Result, error) {
+	fileStructure, err := box.ReadFileStructureWithOptions(r, box.ReadOptions{KeepUnsupported: true, KeepErrors: true, Recover: true})
+	if fileStructure == nil {
+		return nil, err
+	}
+	result := &Result{}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if opts.HexLimit <= 0 {
+		opts.HexLimit = DefaultHexLimit
+	}
+	for _, detail := range fileStructure.BoxDetails {
+		b, err := newBox(r, detail, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Boxes = append(result.Boxes, b)
+	}
+	return result, nil
+}
+
+func newBox(r io.ReadSeeker, detail *box.BoxDetail, opts *Options) (*Box, error) {
+	b := &Box{
+		Type:       detail.Type.String(),
+		Offset:     detail.Offset,
+		Size:       detail.Size,
+		HeaderSize: detail.HeaderSize,
+		Supported:  detail.IsSupportedBox(),
+		Fields:     detail.Boxer,
+		Truncated:  detail.Truncated,
+	}
+	if detail.Type == box.UUIDExtensionBox {
+		b.UserType = hex.EncodeToString(detail.BoxInfo.UserType[:])
+	}
+	if detail.FullBoxHeader != nil {
+		version := detail.Version
+		b.Version = &version
+		b.Flags = hex.EncodeToString(detail.Flags[:])
+	}
+	if detail.Err != nil {
+		b.Error = detail.Err.Error()
+	}
+	if opts.isHexType(b.Type) {
+		data, err := readPayload(r, detail.BoxInfo, opts.HexLimit)
+		if err != nil {
+			return nil, err
+		}
+		b.Hex = hex.Dump(data)
+	}
+	for _, child := range detail.Children {
+		c, err := newBox(r, child, opts)
+		if err != nil {
+			return nil, err
+		}
+		b.Children = append(b.Children, c)
+	}
+	return b, nil
+}
+
+func (opts *Options) isHexType(boxType string) bool {
+	for _, t := range opts.HexTypes {
+		if t == boxType || strings.ReplaceAll(t, "©", "(c)") == boxType {
+			return true
+		}
+	}
+	return false
+}
+
+func readPayload(r io.ReadSeeker, bi *box.BoxInfo, limit int) ([]byte, error) {
+	size := bi.Size - bi.HeaderSize
+	if size > uint64(limit) {
+		size = uint64(limit)
+	}
+	if _, err := bi.SeekToPayload(r); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	n, err := io.ReadFull(r, data)
+	if err == io.ErrUnexpectedEOF {
+		// truncated file
+		return data[:n], nil
+	}
+	return data, err
+}
+
+// WriteJSON prints box tree in JSON
+func (result *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// WriteText prints box tree in indented text
+func (result *Result) WriteText(w io.Writer) error {
+	for _, b := range result.Boxes {
+		if err := b.writeText(w, 0); err != nil {
+			return err
+		}
+	}
+	if result.Error != "" {
+		_, err := fmt.Fprintf(w, "error: %s\n", result.Error)
+		return err
+	}
+	return nil
+}
+
+func (b *Box) writeText(w io.Writer, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	line := fmt.Sprintf("%s%s offset=%d size=%d header=%d", indent, b.Type, b.Offset, b.Size, b.HeaderSize)
+	if b.UserType != "" {
+		line += " usertype=" + b.UserType
+	}
+	if b.Version != nil {
+		line += fmt.Sprintf(" version=%d flags=%s", *b.Version, b.Flags)
+	}
+	if !b.Supported {
+		line += " [unsupported]"
+	}
+	if b.Truncated {
+		line += " [truncated]"
+	}
+	if b.Error != "" {
+		line += " error=" + b.Error
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+	if b.Fields != nil && len(b.Children) == 0 {
+		if fields, err := json.Marshal(b.Fields); err == nil && string(fields) != "{}" {
+			if _, err := fmt.Fprintf(w, "%s  %s\n", indent, fields); err != nil {
+				return err
+			}
+		}
+	}
+	if b.Hex != "" {
+		for _, hexLine := range strings.Split(strings.TrimRight(b.Hex, "\n"), "\n") {
+			if _, err := fmt.Fprintf(w, "%s  | %s\n", indent, hexLine); err != nil {
+				return err
+			}
+		}
+	}
+	for _, child := range b.Children {
+		if err := child.writeText(w, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package location
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/fukco/media-metadata/internal/common"
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	GPX Format = "gpx"
+	KML Format = "kml"
+)
+
+// Placemark location of a clip
+type Placemark struct {
+	Name     string
+	FilePath string
+	*common.Location
+}
+
+type gpx struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+type gpxWaypoint struct {
+	Lat  string   `xml:"lat,attr"`
+	Lon  string   `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele,omitempty"`
+	Time string   `xml:"time,omitempty"`
+	Name string   `xml:"name"`
+	Desc string   `xml:"desc,omitempty"`
+	Src  string   `xml:"src,omitempty"`
+}
+
+type kml struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document kmlDocument
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string        `xml:"name"`
+	Description string        `xml:"description,omitempty"`
+	TimeStamp   *kmlTimeStamp `xml:"TimeStamp,omitempty"`
+	Point       kmlPoint      `xml:"Point"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlPoint struct {
+	AltitudeMode string `xml:"altitudeMode,omitempty"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+func formatCoordinate(value float64) string {
+	return fmt.Sprintf("%.7f", value)
+}
+
+// WriteGPX writes placemarks as GPX 1.1 waypoints
+func WriteGPX(w io.Writer, placemarks []*Placemark) error {
+	doc := &gpx{
+		Version: "1.1",
+		Creator: "media-metadata",
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+	}
+	for _, placemark := range placemarks {
+		waypoint := gpxWaypoint{
+			Lat:  formatCoordinate(placemark.Latitude),
+			Lon:  formatCoordinate(placemark.Longitude),
+			Ele:  placemark.Altitude,
+			Name: placemark.Name,
+			Desc: placemark.FilePath,
+			Src:  placemark.Source,
+		}
+		if placemark.Timestamp != nil {
+			waypoint.Time = placemark.Timestamp.UTC().Format(time.RFC3339)
+		}
+		doc.Waypoints = append(doc.Waypoints, waypoint)
+	}
+	return writeXML(w, doc)
+}
+
+// WriteKML writes placemarks as KML 2.2 points
+func WriteKML(w io.Writer, placemarks []*Placemark) error {
+	doc := &kml{
+		Xmlns:    "http://www.opengis.net/kml/2.2",
+		Document: kmlDocument{Name: "media-metadata"},
+	}
+	for _, placemark := range placemarks {
+		point := kmlPoint{
+			Coordinates: fmt.Sprintf("%s,%s", formatCoordinate(placemark.Longitude), formatCoordinate(placemark.Latitude)),
+		}
+		if placemark.Altitude != nil {
+			point.AltitudeMode = "absolute"
+			point.Coordinates = fmt.Sprintf("%s,%.2f", point.Coordinates, *placemark.Altitude)
+		}
+		kp := kmlPlacemark{
+			Name:        placemark.Name,
+			Description: placemark.FilePath,
+			Point:       point,
+		}
+		if placemark.Timestamp != nil {
+			kp.TimeStamp = &kmlTimeStamp{When: placemark.Timestamp.UTC().Format(time.RFC3339)}
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, kp)
+	}
+	return writeXML(w, doc)
+}
+
+// Write writes placemarks in the specified format
+func Write(w io.Writer, format Format, placemarks []*Placemark) error {
+	switch format {
+	case GPX:
+		return WriteGPX(w, placemarks)
+	case KML:
+		return WriteKML(w, placemarks)
+	default:
+		return fmt.Errorf("not supported location format: %s", format)
+	}
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"encoding/csv"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"io"
+	"strconv"
+)
+
+// Clip row of the batch report, frame rates are empty if unknown
+type Clip struct {
+	FilePath          string
+	Camera            string
+	ProjectFps        float64
+	SensorFps         float64
+	Mode              normalize.SpeedMode
+	Factor            float64
+	TimeLapseInterval float64
+	// Conform is true if editors need to conform the speed of clip
+	Conform bool
+}
+
+// NewClip reads frame rates of the normalized model
+func NewClip(filePath string, c *normalize.Camera) *Clip {
+	clip := &Clip{FilePath: filePath}
+	if c.Model != nil {
+		clip.Camera = c.Model.Value
+	}
+	if c.FormatFps != nil {
+		clip.ProjectFps = c.FormatFps.Value
+	}
+	if c.Speed != nil {
+		clip.SensorFps = c.Speed.Value.SensorFps
+		clip.Mode = c.Speed.Value.Mode
+		clip.Factor = c.Speed.Value.Factor
+		clip.Conform = c.Speed.Value.Conform()
+	}
+	if c.TimeLapseInterval != nil {
+		clip.TimeLapseInterval = c.TimeLapseInterval.Value
+	}
+	return clip
+}
+
+var header = []string{"File", "Camera", "Project FPS", "Sensor FPS", "Mode", "Speed", "Time-lapse Interval", "Conform"}
+
+func formatNumber(value float64, precision int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(value, 'f', precision, 64)
+}
+
+// WriteCSV writes one row per clip, clips to conform are marked as CONFORM
+func WriteCSV(w io.Writer, clips []*Clip) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, clip := range clips {
+		speed, conform := "", ""
+		if clip.Factor != 0 {
+			speed = formatNumber(clip.Factor*100, 0) + "%"
+		}
+		if clip.Conform {
+			conform = "CONFORM"
+		}
+		row := []string{
+			clip.FilePath,
+			clip.Camera,
+			formatNumber(clip.ProjectFps, 3),
+			formatNumber(clip.SensorFps, 3),
+			string(clip.Mode),
+			speed,
+			formatNumber(clip.TimeLapseInterval, -1),
+			conform,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,173 @@
+package resolve
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// fieldValue value of normalize.Field without type parameter
+type fieldValue struct {
+	Value   any
+	Raw     string
+	Source  normalize.Source
+	Path    string
+	Derived bool
+}
+
+// cameraField returns the field of model by name and its discarded values, nil if there is no value
+func cameraField(c *normalize.Camera, name string) (*fieldValue, []*fieldValue) {
+	field := reflect.ValueOf(c).Elem().FieldByName(name)
+	if !field.IsValid() || field.Kind() != reflect.Pointer || field.IsNil() {
+		return nil, nil
+	}
+	convert := func(v reflect.Value) *fieldValue {
+		v = v.Elem()
+		return &fieldValue{
+			Value:   v.FieldByName("Value").Interface(),
+			Raw:     v.FieldByName("Raw").String(),
+			Source:  normalize.Source(v.FieldByName("Source").String()),
+			Path:    v.FieldByName("Path").String(),
+			Derived: v.FieldByName("Derived").Bool(),
+		}
+	}
+	discarded := field.Elem().FieldByName("Discarded")
+	alternatives := make([]*fieldValue, discarded.Len())
+	for i := range alternatives {
+		alternatives[i] = convert(discarded.Index(i))
+	}
+	return convert(field), alternatives
+}
+
+// isCameraField checks whether name is a field of the normalized model
+func isCameraField(name string) bool {
+	field, ok := reflect.TypeOf(normalize.Camera{}).FieldByName(name)
+	return ok && field.Type.Kind() == reflect.Pointer
+}
+
+// formatContext values shared by formatters
+type formatContext struct {
+	camera *normalize.Camera
+	// location of DateRecorded, nil keeps the time zone recorded by camera
+	location *time.Location
+}
+
+// display formats the value, text recorded by camera is kept as it is
+func (ctx *formatContext) display(name string, value *fieldValue) string {
+	if value.Raw != "" {
+		return value.Raw
+	}
+	if format, ok := cameraFormats[name]; ok {
+		return format(ctx, value.Value)
+	}
+	return fmt.Sprint(value.Value)
+}
+
+func decimal(precision int, unit string) func(*formatContext, any) string {
+	return func(_ *formatContext, value any) string {
+		return strconv.FormatFloat(value.(float64), 'f', precision, 64) + unit
+	}
+}
+
+// cameraFormats default formats of the model fields by field name, others are formatted by fmt.Sprint
+var cameraFormats = map[string]func(*formatContext, any) string{
+	"DateRecorded": func(ctx *formatContext, value any) string {
+		date := value.(normalize.Date)
+		if date.Floating {
+			return date.Time.Format(time.DateTime)
+		}
+		if ctx.location != nil {
+			return date.Time.In(ctx.location).Format(time.RFC3339)
+		}
+		return date.Time.Format(time.RFC3339)
+	},
+	"FormatFps":  decimal(2, ""),
+	"CaptureFps": decimal(2, ""),
+	"ShutterSeconds": func(_ *formatContext, value any) string {
+		return common.ExposureTimeFormat(value.(float64))
+	},
+	"Speed": func(_ *formatContext, value any) string {
+		speed := value.(normalize.Speed)
+		return fmt.Sprintf("%s %.2f fps for %.2f fps", speed.Mode, speed.SensorFps, speed.ProjectFps)
+	},
+	"TimeLapseInterval": decimal(-1, " s"),
+	"ShutterAngle":      decimal(1, "°"),
+	"ISO": func(ctx *formatContext, value any) string {
+		iso := value.(int)
+		if ctx.camera.ExposureIndex != nil && ctx.camera.ExposureIndex.Value != iso {
+			return fmt.Sprintf("%d EI:%d", iso, ctx.camera.ExposureIndex.Value)
+		}
+		return strconv.Itoa(iso)
+	},
+	"FNumber":         decimal(1, ""),
+	"TStop":           decimal(1, ""),
+	"FocalLength":     decimal(0, ""),
+	"FocalLength35mm": decimal(0, ""),
+	"FocusDistance":   decimal(2, " m"),
+	"Tint":            decimal(0, ""),
+	"NDFilter": func(_ *formatContext, value any) string {
+		attenuation := value.(float64)
+		if attenuation <= 1 {
+			return "Clear"
+		}
+		return fmt.Sprintf("1/%.0f (ND%.1f)", attenuation, math.Log10(attenuation))
+	},
+	"CDL": func(_ *formatContext, value any) string {
+		return "ASC CDL " + value.(normalize.CDL).String()
+	},
+}
+
+// cameraMappings Resolve fields read from the normalized model, by Resolve field name
+var cameraMappings = []struct {
+	name  string
+	field string
+}{
+	{"DateRecorded", "DateRecorded"},
+	{"CameraManufacturer", "Manufacturer"},
+	{"CameraType", "Model"},
+	{"CameraSerial", "BodySerial"},
+	{"CameraFirmware", "Firmware"},
+	{"LensType", "LensModel"},
+	{"LensNumber", "LensSerial"},
+	{"CameraFormat", "RecordingFormat"},
+	{"CameraFps", "FormatFps"},
+	{"TimeLapseInterval", "TimeLapseInterval"},
+	{"Shutter", "ShutterSeconds"},
+	{"ShutterAngle", "ShutterAngle"},
+	{"ISO", "ISO"},
+	{"WhitePoint", "WhiteBalance"},
+	{"WhiteBalanceTint", "Tint"},
+	{"CameraAperture", "FNumber"},
+	{"CameraAperture", "TStop"},
+	{"FocalPoint", "FocalLength"},
+	{"Distance", "FocusDistance"},
+	{"GammaNotes", "Gamma"},
+	{"ColorSpaceNotes", "Gamut"},
+	{"NDFilter", "NDFilter"},
+	{"CameraNotes", "CDL"},
+}
+
+// parseFromCamera discarded values of the model are formatted in the same way
+func (drMetadata *DRMetadata) parseFromCamera(ctx *formatContext) {
+	for _, mapping := range cameraMappings {
+		value, discarded := cameraField(ctx.camera, mapping.field)
+		if value == nil {
+			continue
+		}
+		for _, alternative := range discarded {
+			drMetadata.set(mapping.name, ctx.display(mapping.field, alternative), alternative.Source, alternative.Path)
+		}
+		drMetadata.set(mapping.name, ctx.display(mapping.field, value), value.Source, value.Path)
+		if provenance, ok := drMetadata.Provenance[mapping.name]; ok && value.Derived {
+			provenance.Derived = true
+		}
+	}
+	// T-stop of cine lenses takes precedence over F-number
+	if tStop := ctx.camera.TStop; tStop != nil {
+		drMetadata.set("CameraApertureType", "T-Stop", tStop.Source, tStop.Path)
+	}
+}
@@ -0,0 +1,303 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const DefaultProfile = "default"
+
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile mapping of Resolve fields, fields not in the profile keep the default mapping
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Base name of the built-in profile inherited by this profile
+	Base string `json:"base,omitempty"`
+	// TimeZone of DateRecorded, Local, UTC or IANA name like Asia/Shanghai, empty keeps the time zone recorded by camera
+	TimeZone string                   `json:"timeZone,omitempty"`
+	Fields   map[string]*FieldMapping `json:"fields,omitempty"`
+	location *time.Location
+}
+
+// FieldMapping mapping of a Resolve field
+type FieldMapping struct {
+	// Sources source expressions in the order of precedence, the first value found is used.
+	// camera.<Field> reads the normalized model, resolve.<Field> reads the default mapping, default is resolve.<field name>
+	Sources []string `json:"sources,omitempty"`
+	// Prefer metadata sources preferred to the order of Sources, like ["EXIF", "MP4"]
+	Prefer []normalize.Source `json:"prefer,omitempty"`
+	// Template text/template executed with TemplateData if any value is found, default is {{.Text}}
+	Template string `json:"template,omitempty"`
+	template *template.Template
+}
+
+// TemplateData data of FieldMapping.Template
+type TemplateData struct {
+	// Value typed value of camera.<Field> like float64 of FocalLength, text of resolve.<Field>
+	Value any
+	// Text value formatted by the default mapping
+	Text   string
+	Source normalize.Source
+	Path   string
+	// Derived value is calculated from other fields of the model
+	Derived bool
+	Camera  *normalize.Camera
+}
+
+var templateFuncs = template.FuncMap{
+	// shutter formats exposure time in seconds like 1/50
+	"shutter": common.ExposureTimeFormat,
+	// date formats DateRecorded with Go time layout
+	"date": func(layout string, date normalize.Date) string {
+		return date.Time.Format(layout)
+	},
+}
+
+// builtinProfiles profiles of Resolve versions
+var builtinProfiles = map[string]*Profile{
+	DefaultProfile: {
+		Name:        DefaultProfile,
+		Description: "mapping of DaVinci Resolve 18.5 and later, Shutter is shown as Shutter Speed",
+	},
+	"resolve-18": {
+		Name:        "resolve-18",
+		Description: "DaVinci Resolve before 18.5, the only Shutter field prefers shutter angle of cinema cameras",
+		Base:        DefaultProfile,
+		Fields: map[string]*FieldMapping{
+			"Shutter": {Sources: []string{"resolve.ShutterAngle", "resolve.Shutter"}},
+		},
+	},
+}
+
+func init() {
+	for _, profile := range builtinProfiles {
+		if err := profile.compile(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// BuiltinProfiles names of built-in profiles
+func BuiltinProfiles() []string {
+	names := make([]string, 0, len(builtinProfiles))
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindProfile returns built-in profile by name or loads profile file, nil for empty name
+func FindProfile(nameOrPath string) (*Profile, error) {
+	if nameOrPath == "" {
+		return nil, nil
+	}
+	if profile, ok := builtinProfiles[nameOrPath]; ok {
+		return profile, nil
+	}
+	if _, err := os.Stat(nameOrPath); err != nil {
+		return nil, fmt.Errorf("%w %q, built-in profiles: %s", ErrUnknownProfile, nameOrPath, strings.Join(BuiltinProfiles(), ", "))
+	}
+	return LoadProfile(nameOrPath)
+}
+
+// LoadProfile loads profile from JSON file, or YAML file with .yaml or .yml extension
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		value, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", path, err)
+		}
+		// decoded as JSON for the same checks of unknown fields and types
+		if data, err = json.Marshal(value); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", path, err)
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	profile := &Profile{}
+	if err := decoder.Decode(profile); err != nil {
+		return nil, fmt.Errorf("profile %s: %w", path, err)
+	}
+	if profile.Name == "" {
+		profile.Name = path
+	}
+	if err := profile.compile(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// compile merges fields of base profile, then checks fields and parses templates
+func (p *Profile) compile() error {
+	if p.Base != "" {
+		base, ok := builtinProfiles[p.Base]
+		if !ok || base == p {
+			return fmt.Errorf("profile %s: %w %q as base", p.Name, ErrUnknownProfile, p.Base)
+		}
+		fields := make(map[string]*FieldMapping, len(base.Fields)+len(p.Fields))
+		for name, mapping := range base.Fields {
+			fields[name] = mapping
+		}
+		for name, mapping := range p.Fields {
+			fields[name] = mapping
+		}
+		p.Fields = fields
+		if p.TimeZone == "" {
+			p.TimeZone = base.TimeZone
+		}
+	}
+	switch p.TimeZone {
+	case "":
+	case "Local":
+		p.location = time.Local
+	default:
+		location, err := time.LoadLocation(p.TimeZone)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		p.location = location
+	}
+	for name, mapping := range p.Fields {
+		if !IsField(name) {
+			return fmt.Errorf("profile %s: unknown field %s", p.Name, name)
+		}
+		if mapping == nil {
+			return fmt.Errorf("profile %s: field %s: empty mapping", p.Name, name)
+		}
+		for _, source := range mapping.Sources {
+			if !isSourceExpression(source) {
+				return fmt.Errorf("profile %s: field %s: unknown source %q", p.Name, name, source)
+			}
+		}
+		if mapping.Template != "" && mapping.template == nil {
+			t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(mapping.Template)
+			if err != nil {
+				return fmt.Errorf("profile %s: field %s: %w", p.Name, name, err)
+			}
+			mapping.template = t
+		}
+	}
+	return nil
+}
+
+func isSourceExpression(source string) bool {
+	if name, ok := strings.CutPrefix(source, "camera."); ok {
+		return isCameraField(name)
+	}
+	if name, ok := strings.CutPrefix(source, "resolve."); ok {
+		return IsField(name)
+	}
+	return false
+}
+
+// candidates values of source expression, the value used by default mapping is the first
+func candidates(source string, ctx *formatContext, defaults *DRMetadata) []*TemplateData {
+	var result []*TemplateData
+	if name, ok := strings.CutPrefix(source, "camera."); ok {
+		value, discarded := cameraField(ctx.camera, name)
+		if value == nil {
+			return nil
+		}
+		for _, v := range append([]*fieldValue{value}, discarded...) {
+			result = append(result, &TemplateData{Value: v.Value, Text: ctx.display(name, v), Source: v.Source, Path: v.Path, Derived: v.Derived, Camera: ctx.camera})
+		}
+		return result
+	}
+	name := strings.TrimPrefix(source, "resolve.")
+	text := reflect.ValueOf(defaults).Elem().FieldByName(name).String()
+	provenance, ok := defaults.Provenance[name]
+	if text == "" || !ok {
+		return nil
+	}
+	result = append(result, &TemplateData{Value: text, Text: text, Source: provenance.Source, Path: provenance.Path, Derived: provenance.Derived, Camera: ctx.camera})
+	for _, alternative := range provenance.Discarded {
+		result = append(result, &TemplateData{Value: alternative.Value, Text: alternative.Value, Source: alternative.Source, Path: alternative.Path, Camera: ctx.camera})
+	}
+	return result
+}
+
+// apply maps fields of profile, values of the default mapping are kept for the other fields
+func (p *Profile) apply(drMetadata *DRMetadata, ctx *formatContext) {
+	defaults := *drMetadata
+	defaults.Provenance = make(map[string]*Provenance, len(drMetadata.Provenance))
+	for name, provenance := range drMetadata.Provenance {
+		defaults.Provenance[name] = provenance
+	}
+	names := make([]string, 0, len(p.Fields))
+	for name := range p.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		mapping := p.Fields[name]
+		sources := mapping.Sources
+		if len(sources) == 0 {
+			sources = []string{"resolve." + name}
+		}
+		var values []*TemplateData
+		for _, source := range sources {
+			values = append(values, candidates(source, ctx, &defaults)...)
+		}
+		chosen := 0
+	prefer:
+		for _, source := range mapping.Prefer {
+			for i, value := range values {
+				if value.Source == source {
+					chosen = i
+					break prefer
+				}
+			}
+		}
+		if len(values) == 0 {
+			reflect.ValueOf(drMetadata).Elem().FieldByName(name).SetString("")
+			delete(drMetadata.Provenance, name)
+			continue
+		}
+		data := values[chosen]
+		text := data.Text
+		if mapping.template != nil {
+			var buf bytes.Buffer
+			if err := mapping.template.Execute(&buf, data); err == nil {
+				text = buf.String()
+			}
+		}
+
+		reflect.ValueOf(drMetadata).Elem().FieldByName(name).SetString(text)
+		if text == "" {
+			delete(drMetadata.Provenance, name)
+			continue
+		}
+		provenance := &Provenance{Source: data.Source, Path: data.Path, Derived: data.Derived}
+		for i, value := range values {
+			if i != chosen {
+				provenance.Discarded = append(provenance.Discarded, &Alternative{Value: value.Text, Source: value.Source, Path: value.Path})
+				if value.Text != data.Text {
+					provenance.Conflict = true
+				}
+			}
+		}
+		if drMetadata.Provenance == nil {
+			drMetadata.Provenance = make(map[string]*Provenance)
+		}
+		drMetadata.Provenance[name] = provenance
+	}
+}
@@ -0,0 +1,143 @@
+package resolve
+
+import (
+	"errors"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const jsonProfile = `{
+  "name": "facility",
+  "base": "resolve-18",
+  "timeZone": "UTC",
+  "fields": {
+    "FocalPoint": {"sources": ["camera.FocalLength"], "template": "{{printf \"%.0f mm\" .Value}}"},
+    "ISO": {"sources": ["camera.ISO"], "prefer": ["Sony RTMD", "EXIF"]}
+  }
+}`
+
+const yamlProfile = `# same as jsonProfile
+name: facility
+base: resolve-18
+timeZone: UTC
+fields:
+  FocalPoint:
+    sources:
+      - camera.FocalLength
+    template: '{{printf "%.0f mm" .Value}}'
+  ISO:
+    sources: [camera.ISO]  # flow sequence
+    prefer:
+    - Sony RTMD
+    - "EXIF"
+`
+
+func writeProfile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindProfile(t *testing.T) {
+	if profile, err := FindProfile(""); profile != nil || err != nil {
+		t.Errorf("empty name = %v, %v, want nil", profile, err)
+	}
+	for _, name := range BuiltinProfiles() {
+		profile, err := FindProfile(name)
+		if err != nil || profile.Name != name {
+			t.Errorf("FindProfile(%q) = %v, %v", name, profile, err)
+		}
+	}
+	if _, err := FindProfile("resolve-17"); !errors.Is(err, ErrUnknownProfile) {
+		t.Errorf("unknown profile error = %v", err)
+	}
+
+	jsonPath := writeProfile(t, "facility.json", jsonProfile)
+	yamlPath := writeProfile(t, "facility.yaml", yamlProfile)
+	fromJSON, err := FindProfile(jsonPath)
+	if err != nil {
+		t.Fatalf("JSON profile: %v", err)
+	}
+	fromYAML, err := FindProfile(yamlPath)
+	if err != nil {
+		t.Fatalf("YAML profile: %v", err)
+	}
+	if fromJSON.Name != "facility" || fromJSON.location == nil || len(fromJSON.Fields) != 3 {
+		t.Errorf("JSON profile = %+v", fromJSON)
+	}
+	for _, profile := range []*Profile{fromJSON, fromYAML} {
+		for _, mapping := range profile.Fields {
+			mapping.template = nil
+		}
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("YAML profile = %+v, want %+v", fromYAML, fromJSON)
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown.json", `{"fields": {"Shutter Speed": {}}}`},
+		{"source.yaml", "fields:\n  Shutter:\n    sources: [exif.ExposureTime]\n"},
+		{"property.yml", "name: x\nsource: camera.ISO\n"},
+		{"base.yaml", "base: resolve-17\n"},
+		{"zone.yaml", "timeZone: Mars/Olympus\n"},
+		{"template.yaml", "fields:\n  ISO:\n    template: '{{.Text'\n"},
+		{"indent.yaml", "name: x\n  base: default\n"},
+		{"type.yaml", "fields:\n  - ISO\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if profile, err := FindProfile(writeProfile(t, tt.name, tt.content)); err == nil {
+				t.Errorf("profile = %+v, want error", profile)
+			}
+		})
+	}
+}
+
+// TestBuiltinProfiles every built-in profile differs from the others
+func TestBuiltinProfiles(t *testing.T) {
+	names := BuiltinProfiles()
+	for i, name := range names {
+		for _, other := range names[i+1:] {
+			a, b := builtinProfiles[name], builtinProfiles[other]
+			if reflect.DeepEqual(a.Fields, b.Fields) && a.TimeZone == b.TimeZone {
+				t.Errorf("profiles %s and %s have the same mapping", name, other)
+			}
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	m := sonyRTMDMeta(&rtmd.CameraUnitMetadata{
+		ShutterSpeedTime:          &common.Rational{Numerator: 1, Denominator: 50},
+		ShutterSpeedAngle:         180,
+		ISOSensitivity:            800,
+		ExposureIndexOfPhotoMeter: 3200,
+	}, &rtmd.LensUnitMetadata{LensZoomPtr: 0.035})
+	defaults := GetDRMetadataFromMeta(m, nil)
+	checkFields(t, defaults, map[string]string{"Shutter": "1/50", "ShutterAngle": "180.0°", "FocalPoint": "35"})
+
+	resolve18, _ := FindProfile("resolve-18")
+	checkFields(t, GetDRMetadataFromMeta(m, resolve18), map[string]string{"Shutter": "180.0°"})
+
+	facility, err := FindProfile(writeProfile(t, "facility.yaml", yamlProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drMetadata := GetDRMetadataFromMeta(m, facility)
+	checkFields(t, drMetadata, map[string]string{"Shutter": "180.0°", "FocalPoint": "35 mm", "ISO": "800 EI:3200"})
+	if provenance := drMetadata.Provenance["FocalPoint"]; provenance == nil || provenance.Path != "rtmd/LensUnitMetadata/LensZoomPtr" {
+		t.Errorf("FocalPoint provenance = %+v", provenance)
+	}
+}
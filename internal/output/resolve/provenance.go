@@ -0,0 +1,49 @@
+package resolve
+
+import (
+	"github.com/fukco/media-metadata/internal/normalize"
+	"reflect"
+)
+
+// sourceWrittenFields fields written back by this tool
+const sourceWrittenFields normalize.Source = "media-metadata"
+
+// Provenance where the value of a field comes from, and values of other sources replaced by it
+type Provenance struct {
+	Source normalize.Source
+	Path   string
+	// Derived value is calculated from other fields, like shutter angle from exposure time and frame rate
+	Derived   bool           `json:",omitempty"`
+	Discarded []*Alternative `json:",omitempty"`
+	// Conflict is true if any discarded value differs from the value of field
+	Conflict bool `json:",omitempty"`
+}
+
+// Alternative discarded value of a field
+type Alternative struct {
+	Value  string
+	Source normalize.Source
+	Path   string
+}
+
+// set sets field with its source, the previous value is kept as discarded, empty value is ignored
+func (drMetadata *DRMetadata) set(name string, value string, source normalize.Source, path string) {
+	if value == "" || !IsField(name) {
+		return
+	}
+	field := reflect.ValueOf(drMetadata).Elem().FieldByName(name)
+	provenance := &Provenance{Source: source, Path: path}
+	if previous, ok := drMetadata.Provenance[name]; ok {
+		provenance.Discarded = append(previous.Discarded, &Alternative{Value: field.String(), Source: previous.Source, Path: previous.Path})
+		for _, alternative := range provenance.Discarded {
+			if alternative.Value != value {
+				provenance.Conflict = true
+			}
+		}
+	}
+	field.SetString(value)
+	if drMetadata.Provenance == nil {
+		drMetadata.Provenance = make(map[string]*Provenance)
+	}
+	drMetadata.Provenance[name] = provenance
+}
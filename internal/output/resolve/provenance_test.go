@@ -0,0 +1,92 @@
+package resolve
+
+import (
+	"encoding/json"
+	"github.com/fukco/media-metadata/internal/exif"
+	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	drMetadata := &DRMetadata{}
+	drMetadata.set("ISO", "800", normalize.SourceExif, "EXIF/ExifIFD/0x8827")
+	drMetadata.set("ISO", "", normalize.SourceMetaItems, "ignored")
+	drMetadata.set("NotAField", "1", normalize.SourceMetaItems, "ignored")
+	drMetadata.set("ISO", "800", normalize.SourceNikonNCTG, "NCTG/ISOInfo")
+	provenance := drMetadata.Provenance["ISO"]
+	if provenance.Source != normalize.SourceNikonNCTG || provenance.Conflict || len(provenance.Discarded) != 1 {
+		t.Fatalf("same value: %+v", provenance)
+	}
+	drMetadata.set("ISO", "1600", normalize.SourceSonyRTMD, "rtmd/CameraUnitMetadata/ISOSensitivity")
+	provenance = drMetadata.Provenance["ISO"]
+	if drMetadata.ISO != "1600" || !provenance.Conflict || len(provenance.Discarded) != 2 {
+		t.Fatalf("different value: %+v", provenance)
+	}
+	if first := provenance.Discarded[0]; first.Value != "800" || first.Source != normalize.SourceExif {
+		t.Errorf("first discarded = %+v", first)
+	}
+	if len(drMetadata.Provenance) != 1 {
+		t.Errorf("provenance of %d fields, want 1", len(drMetadata.Provenance))
+	}
+}
+
+func TestProvenanceNotEncoded(t *testing.T) {
+	drMetadata := &DRMetadata{}
+	drMetadata.set("ISO", "800", normalize.SourceExif, "EXIF/ExifIFD/0x8827")
+	data, err := json.Marshal(drMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Provenance") {
+		t.Errorf("Provenance is encoded: %s", data)
+	}
+}
+
+func TestGetDRMetadataProvenance(t *testing.T) {
+	creationTime := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	m := &meta.Metadata{
+		Mp4Meta: &meta.Mp4Meta{CreationTime: &creationTime},
+		ExifMeta: &exif.ExifMeta{Tags: map[string][]*exif.ExifTag{string(exif.GroupExif): {
+			{ID: 0x9003, Value: "2024:05:01 10:00:00"},
+			{ID: 0x9011, Value: "+08:00"},
+		}}},
+		MetaItemKeyValues: map[string]any{
+			"com.atomos.hdr.monitormode": "Native",
+			"com.atomos.hdr.range":       "Full",
+		},
+		MakerMeta: &meta.MakerMeta{},
+	}
+	drMetadata := GetDRMetadataFromMeta(m, nil)
+
+	date := drMetadata.Provenance["DateRecorded"]
+	if date == nil || date.Source != normalize.SourceExif || date.Path != "EXIF/ExifIFD/0x9003" {
+		t.Fatalf("DateRecorded provenance = %+v", date)
+	}
+	if !date.Conflict || len(date.Discarded) != 1 || date.Discarded[0].Source != normalize.SourceMP4 ||
+		date.Discarded[0].Value != "2024-05-01T02:00:00Z" {
+		t.Errorf("DateRecorded discarded = %+v", date.Discarded)
+	}
+
+	notes := drMetadata.Provenance["CameraNotes"]
+	if drMetadata.CameraNotes != "MonitorMode: Native\nRange: Full" || notes == nil || notes.Source != normalize.SourceMetaItems ||
+		notes.Path != normalize.MetaItemPath("com.atomos.hdr.monitormode")+", "+normalize.MetaItemPath("com.atomos.hdr.range") {
+		t.Errorf("CameraNotes = %q, provenance = %+v", drMetadata.CameraNotes, notes)
+	}
+	for name, provenance := range drMetadata.Provenance {
+		if provenance.Source == "" || provenance.Path == "" {
+			t.Errorf("%s has incomplete provenance %+v", name, provenance)
+		}
+	}
+}
+
+func TestMetaItemsWithoutNotes(t *testing.T) {
+	drMetadata := &DRMetadata{}
+	drMetadata.set("CameraNotes", "Gain: 12dB", normalize.SourcePanasonicClipMain, "ClipMain/CameraMasterGainAdjustment")
+	drMetadata.parseFromMetaItems(map[string]any{"com.atomos.hdr.camera": "Sony"})
+	if drMetadata.CameraNotes != "Gain: 12dB" || drMetadata.Provenance["CameraNotes"].Source != normalize.SourcePanasonicClipMain {
+		t.Errorf("CameraNotes = %q, provenance = %+v", drMetadata.CameraNotes, drMetadata.Provenance["CameraNotes"])
+	}
+}
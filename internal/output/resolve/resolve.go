@@ -2,15 +2,18 @@ package resolve
 
 import (
 	"fmt"
+	"github.com/fukco/media-metadata/internal/common"
 	"github.com/fukco/media-metadata/internal/exif"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
 	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
 	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
 	"github.com/fukco/media-metadata/internal/meta"
-	"strconv"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"github.com/fukco/media-metadata/internal/xmp"
+	"reflect"
 	"strings"
-	"time"
 )
 
 type DRMetadata struct {
@@ -48,278 +51,188 @@ type DRMetadata struct {
 	AspectRatioNotes   string
 	GammaNotes         string
 	ColorSpaceNotes    string
+	ReelNumber         string
+	Scene              string
+	Shot               string
+	Take               string
+	GoodTake           string
+	Comments           string
+	// Provenance source of each field by field name, not encoded as JSON, the command line outputs it with -provenance
+	Provenance map[string]*Provenance `json:"-"`
 }
 
 func (drMetadata *DRMetadata) parseFromSonyXML(xml *nrtmd.NonRealTimeMeta) {
-	drMetadata.DateRecorded = xml.CreationDate.Value
-	drMetadata.CameraType = xml.Device.ModelName
-	drMetadata.CameraFps = xml.VideoFormat.VideoFrame.FormatFps
-	drMetadata.CameraManufacturer = xml.Device.Manufacturer
-	drMetadata.CameraSerial = xml.Device.SerialNo
-	drMetadata.AspectRatioNotes = xml.VideoFormat.VideoLayout.AspectRatio
-	for _, group := range xml.AcquisitionRecord.Groups {
-		if group.Name == nrtmd.CameraUnitMetadataSet {
-			for _, item := range group.Items {
-				if item.Name == nrtmd.CaptureGammaEquation {
-					drMetadata.GammaNotes = item.Value
-				} else if item.Name == nrtmd.CaptureColorPrimaries {
-					drMetadata.ColorSpaceNotes = item.Value
-				}
-			}
-			break
-		}
-	}
+	drMetadata.set("AspectRatioNotes", xml.VideoFormat.VideoLayout.AspectRatio, normalize.SourceSonyNonRealTimeMeta, "NonRealTimeMeta/VideoFormat/VideoLayout@aspectRatio")
 	for _, relatedTo := range xml.RelevantFiles.RelatedTo {
 		if relatedTo.Rel == "LUT" {
-			drMetadata.LUTUsed = relatedTo.File
+			drMetadata.set("LUTUsed", relatedTo.File, normalize.SourceSonyNonRealTimeMeta, "NonRealTimeMeta/RelevantFiles/RelatedTo@file")
 			break
 		}
 	}
 }
 
 func (drMetadata *DRMetadata) parseFromSonyRTMD(rtmd *rtmd.RTMD) {
-	if rtmd.CameraUnitMetadata.WhiteBalance > 0 {
-		drMetadata.WhitePoint = strconv.Itoa(int(rtmd.CameraUnitMetadata.WhiteBalance))
-	}
-	if rtmd.CameraUnitMetadata.ShutterSpeedTime != nil {
-		drMetadata.Shutter = rtmd.CameraUnitMetadata.ShutterSpeedTime.String()
-	}
-	if rtmd.CameraUnitMetadata.ShutterSpeedAngle > 0 {
-		drMetadata.ShutterAngle = fmt.Sprintf("%.1f°", rtmd.CameraUnitMetadata.ShutterSpeedAngle)
-	}
-	if rtmd.CameraUnitMetadata.ISOSensitivity > 0 {
-		if rtmd.CameraUnitMetadata.ISOSensitivity == rtmd.CameraUnitMetadata.ExposureIndexOfPhotoMeter {
-			drMetadata.ISO = strconv.Itoa(int(rtmd.CameraUnitMetadata.ISOSensitivity))
-		} else {
-			drMetadata.ISO = fmt.Sprintf("%d EI:%d", rtmd.CameraUnitMetadata.ISOSensitivity, rtmd.CameraUnitMetadata.ExposureIndexOfPhotoMeter)
-		}
-	}
 	if rtmd.CameraUnitMetadata.ImagerDimensionWidth != 0 && rtmd.CameraUnitMetadata.ImagerDimensionHeight != 0 {
-		drMetadata.SensorAreaCaptured = fmt.Sprintf("%dμm * %dμm", rtmd.CameraUnitMetadata.ImagerDimensionWidth, rtmd.CameraUnitMetadata.ImagerDimensionHeight)
-	}
-	if rtmd.CameraUnitMetadata.ColorPrimaries != "" && drMetadata.ColorSpaceNotes == "" {
-		drMetadata.ColorSpaceNotes = rtmd.CameraUnitMetadata.ColorPrimaries
-	}
-	if rtmd.CameraUnitMetadata.CaptureGammaEquation != "" && drMetadata.GammaNotes == "" {
-		drMetadata.GammaNotes = rtmd.CameraUnitMetadata.CaptureGammaEquation
-	}
-	if rtmd.LensUnitMetadata.IrisFNumber > 0 {
-		drMetadata.CameraAperture = fmt.Sprintf("%.1f", rtmd.LensUnitMetadata.IrisFNumber)
-	}
-	if rtmd.LensUnitMetadata.LensZoomPtr != 0 {
-		drMetadata.FocalPoint = fmt.Sprintf("%.0f", rtmd.LensUnitMetadata.LensZoomPtr*1000)
-	}
-	if rtmd.LensUnitMetadata.FocusPositionFromImagePlane > 0 {
-		drMetadata.Distance = fmt.Sprintf("%.2f m", rtmd.LensUnitMetadata.FocusPositionFromImagePlane)
+		drMetadata.set("SensorAreaCaptured", fmt.Sprintf("%dμm * %dμm", rtmd.CameraUnitMetadata.ImagerDimensionWidth, rtmd.CameraUnitMetadata.ImagerDimensionHeight),
+			normalize.SourceSonyRTMD, "rtmd/CameraUnitMetadata/ImagerDimension")
 	}
 }
 
 func (drMetadata *DRMetadata) parseFromPanasonicXML(xml *panasonic.ClipMain) {
-	drMetadata.DateRecorded = xml.ClipContent.ClipMetadata.Shoot.StartDate
-	drMetadata.ISO = xml.CameraUnitMetadata.ISOSensitivity
-	drMetadata.CameraFps = xml.ClipContent.Video.FrameRate
-	drMetadata.GammaNotes = xml.CameraUnitMetadata.Gamma.CaptureGamma
-	drMetadata.ColorSpaceNotes = xml.CameraUnitMetadata.Gamut.CaptureGamut
-	drMetadata.CodecBitrate = xml.ClipContent.Video.Codec.Codec
+	drMetadata.set("CodecBitrate", xml.ClipContent.Video.Codec.Codec, normalize.SourcePanasonicClipMain, "ClipMain/ClipContent/EssenceList/Video/Codec")
+	clip, camera := xml.ClipContent.ClipMetadata, xml.CameraUnitMetadata
+	drMetadata.set("Shot", clip.UserClipName, normalize.SourcePanasonicClipMain, "ClipMain/ClipContent/ClipMetadata/UserClipName")
+	drMetadata.set("Scene", clip.Scenario.SceneNo, normalize.SourcePanasonicClipMain, "ClipMain/ClipContent/ClipMetadata/Scenario/SceneNo.")
+	drMetadata.set("Take", clip.Scenario.TakeNo, normalize.SourcePanasonicClipMain, "ClipMain/ClipContent/ClipMetadata/Scenario/TakeNo.")
+	// shot mark flags good takes
+	if clip.ShotMark {
+		drMetadata.set("GoodTake", "1", normalize.SourcePanasonicClipMain, "ClipMain/ClipContent/ClipMetadata/ShotMark")
+	}
+	drMetadata.set("LUTUsed", camera.LUT.LUTName, normalize.SourcePanasonicClipMain, "ClipMain/UserArea/AcquisitionMetadata/CameraUnitMetadata/LUT/LUTName")
+	if gain := camera.CameraMasterGainAdjustment; gain != "" {
+		drMetadata.set("CameraNotes", "Gain: "+gain, normalize.SourcePanasonicClipMain, "ClipMain/UserArea/AcquisitionMetadata/CameraUnitMetadata/CameraMasterGainAdjustment")
+	}
 }
 
 func (drMetadata *DRMetadata) parseFromExif(exifMeta *exif.ExifMeta) {
-	if ifd0Tags, ok := exifMeta.Tags[string(exif.GroupIFD0)]; ok {
-		for i := range ifd0Tags {
-			tag := ifd0Tags[i]
-			if !tag.Undefined {
-				if tag.ID == 0x010f {
-					drMetadata.CameraManufacturer = tag.Value
-				} else if tag.ID == 0x0110 {
-					drMetadata.CameraType = tag.Value
-				}
-			}
-		}
-	}
-	if exifTags, ok := exifMeta.Tags[string(exif.GroupExif)]; ok {
-		values := make(map[uint16]string, len(exifTags))
+	group := string(exif.GroupExif)
+	if exifTags, ok := exifMeta.Tags[group]; ok {
 		for i := range exifTags {
 			tag := exifTags[i]
-			if !tag.Undefined {
-				values[tag.ID] = tag.Value
+			if !tag.Undefined && tag.ID == 0xa432 {
+				drMetadata.set("LensNotes", tag.Value, normalize.SourceExif, normalize.ExifPath(group, tag.ID))
 			}
 		}
-
-		if values[0x9003] != "" {
-			if values[0x9011] != "" {
-				parse, err := time.Parse("2006:01:02 15:04:05-07:00", values[0x9003]+values[0x9011])
-				if err != nil {
-					fmt.Println("not valid time format")
-				} else {
-					drMetadata.DateRecorded = parse.Format(time.RFC3339)
-				}
-			} else {
-				parse, err := time.Parse("2006:01:02 15:04:05", values[0x9003])
-				if err != nil {
-					fmt.Println("not valid time format")
-				} else {
-					drMetadata.DateRecorded = parse.Format(time.DateTime)
-				}
-			}
-		}
-		drMetadata.Shutter = values[0x829a]
-		drMetadata.CameraAperture = values[0x829d]
-		if value, ok := values[0x8831]; ok {
-			drMetadata.ISO = value
-		} else if value, ok := values[0x8827]; ok {
-			drMetadata.ISO = value
-		} else if value, ok := values[0x8832]; ok {
-			drMetadata.ISO = value
-		}
-		drMetadata.FocalPoint = values[0x920a]
-		drMetadata.CameraSerial = values[0xa431]
-		drMetadata.LensNotes = values[0xa432]
-		drMetadata.LensType = values[0xa434]
-		drMetadata.LensNumber = values[0xa435]
 	}
-	if makerTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s", exif.MakerIFD, exif.Panasonic)]; ok {
+	group = fmt.Sprintf("%s: %s", exif.MakerIFD, exif.Panasonic)
+	if makerTags, ok := exifMeta.Tags[group]; ok {
 		for i := range makerTags {
 			tag := makerTags[i]
 			switch tag.ID {
-			case 0x02:
-				drMetadata.CameraFirmware = tag.Value
-			case 0x25:
-				drMetadata.CameraSerial = tag.Value
-			case 0x44:
-				drMetadata.WhitePoint = tag.Value
-			case 0x51:
-				drMetadata.LensType = tag.Value
-			case 0x52:
-				drMetadata.LensNumber = tag.Value
 			case 0x9d:
-				drMetadata.NDFilter = tag.Value
+				drMetadata.set("NDFilter", tag.Value, normalize.SourceExifMakerNotes, normalize.ExifPath(group, tag.ID))
 			case 0x9f:
-				drMetadata.ShutterType = tag.Value
-			}
-		}
-	}
-	if makerTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s", exif.MakerIFD, exif.FUJIFILM)]; ok {
-		for i := range makerTags {
-			tag := makerTags[i]
-			if tag.ID == 0x10 {
-				drMetadata.CameraSerial = tag.Value
-			} else if tag.ID == 0x3803 {
-				drMetadata.GammaNotes = tag.Value
-			} else if tag.ID == 0x3820 {
-				drMetadata.CameraFps = tag.Value
-			}
-		}
-	}
-	if makerTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s", exif.MakerIFD, exif.Canon)]; ok {
-		for i := range makerTags {
-			tag := makerTags[i]
-			if tag.ID == 0x7 {
-				drMetadata.CameraFirmware = tag.Value
-			} else if tag.ID == 0x95 && drMetadata.LensType == "" {
-				drMetadata.LensType = tag.Value
+				drMetadata.set("ShutterType", tag.Value, normalize.SourceExifMakerNotes, normalize.ExifPath(group, tag.ID))
 			}
 		}
 	}
-	if makerSubTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s/%s", exif.MakerIFD, exif.Canon, exif.GroupCanonShotInfo)]; ok {
-		values := make(map[uint16]string, len(makerSubTags))
+	group = fmt.Sprintf("%s: %s/%s", exif.MakerIFD, exif.Canon, exif.GroupCanonShotInfo)
+	if makerSubTags, ok := exifMeta.Tags[group]; ok {
 		for i := range makerSubTags {
 			tag := makerSubTags[i]
-			values[tag.ID] = tag.Value
-		}
-		drMetadata.NDFilter = values[28]
-	}
-	if makerSubTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s/%s", exif.MakerIFD, exif.Canon, exif.GroupCanonProcessingInfo)]; ok {
-		for i := range makerSubTags {
-			tag := makerSubTags[i]
-			if tag.ID == 9 {
-				drMetadata.WhitePoint = tag.Value
-			}
-		}
-	}
-	if makerSubTags, ok := exifMeta.Tags[fmt.Sprintf("%s: %s/%s", exif.MakerIFD, exif.Canon, exif.GroupCanonLogInfo)]; ok {
-		for i := range makerSubTags {
-			tag := makerSubTags[i]
-			if tag.ID == 9 {
-				drMetadata.ColorSpaceNotes = tag.Value
-			} else if tag.ID == 11 {
-				drMetadata.GammaNotes = tag.Value
+			if tag.ID == 28 {
+				drMetadata.set("NDFilter", tag.Value, normalize.SourceExifMakerNotes, normalize.ExifPath(group, tag.ID))
 			}
 		}
 	}
 }
 
 func (drMetadata *DRMetadata) parseFromMetaItems(itemsMap map[string]any) {
-	if value, ok := itemsMap["com.atomos.hdr.gamut"]; ok {
-		drMetadata.ColorSpaceNotes = value.(string)
-	}
-	if value, ok := itemsMap["com.atomos.hdr.gamma"]; ok {
-		drMetadata.GammaNotes = value.(string)
-	}
 	if value, ok := itemsMap["com.atomos.hdr.camera"]; ok {
-		drMetadata.CameraFormat = value.(string)
+		drMetadata.set("CameraFormat", fmt.Sprint(value), normalize.SourceMetaItems, normalize.MetaItemPath("com.atomos.hdr.camera"))
 	}
+	notes := make([]string, 0, 2)
+	paths := make([]string, 0, 2)
 	if value, ok := itemsMap["com.atomos.hdr.monitormode"]; ok {
-		drMetadata.CameraNotes = fmt.Sprintf("MonitorMode: %s", value)
+		notes = append(notes, fmt.Sprintf("MonitorMode: %s", value))
+		paths = append(paths, normalize.MetaItemPath("com.atomos.hdr.monitormode"))
 	}
-	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Make"]; ok {
-		drMetadata.CameraManufacturer = value.(string)
-	}
-	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Model"]; ok {
-		drMetadata.CameraType = value.(string)
+	if value, ok := itemsMap["com.atomos.hdr.range"]; ok {
+		notes = append(notes, fmt.Sprintf("Range: %s", value))
+		paths = append(paths, normalize.MetaItemPath("com.atomos.hdr.range"))
 	}
-	if value, ok := itemsMap["com.apple.proapps.image.{TIFF}.Software"]; ok {
-		drMetadata.CameraFirmware = value.(string)
+	if len(notes) > 0 {
+		drMetadata.set("CameraNotes", strings.Join(notes, "\n"), normalize.SourceMetaItems, strings.Join(paths, ", "))
 	}
-	if value, ok := itemsMap["com.atomos.hdr.range"]; ok {
-		if drMetadata.CameraNotes != "" {
-			drMetadata.CameraNotes = fmt.Sprintf("%s\nRange: %s", drMetadata.CameraNotes, value)
+}
+
+// parseFromWrittenFields reads fields written back by this tool, which take precedence over camera metadata
+func (drMetadata *DRMetadata) parseFromWrittenFields(itemsMap map[string]any) {
+	for key, value := range itemsMap {
+		if name, ok := strings.CutPrefix(key, common.MetadataKeyPrefix); ok {
+			if s, ok := value.(string); ok && IsField(name) {
+				drMetadata.set(name, s, sourceWrittenFields, normalize.MetaItemPath(key))
+			}
 		}
-		drMetadata.CameraNotes = fmt.Sprintf("Range: %s", value)
 	}
 }
 
-func (drMetadata *DRMetadata) parseFromNctg(nctg *nikon.NCTG) {
-	parse, err := time.Parse("2006:01:02 15:04:05-07:00", nctg.CreateDate+nctg.TimeZone)
-	if err != nil {
-		return
+// IsField checks whether name is a field of DRMetadata
+func IsField(name string) bool {
+	field, ok := reflect.TypeOf(DRMetadata{}).FieldByName(name)
+	return ok && field.Type.Kind() == reflect.String
+}
+
+// SetField sets field by name, returns false if there is no such field
+func (drMetadata *DRMetadata) SetField(name string, value string) bool {
+	if !IsField(name) {
+		return false
+	}
+	reflect.ValueOf(drMetadata).Elem().FieldByName(name).SetString(value)
+	return true
+}
+
+func (drMetadata *DRMetadata) parseFromAppleQuickTime(q *apple.QuickTimeMeta) {
+	drMetadata.set("CameraId", q.CameraIdentifier, normalize.SourceAppleQuickTime, normalize.MetaItemPath(apple.KeyCameraIdentifier))
+	if q.FocalLength35mm > 0 {
+		drMetadata.set("LensNotes", fmt.Sprintf("35mm equivalent focal length: %.0fmm", q.FocalLength35mm),
+			normalize.SourceAppleQuickTime, normalize.MetaItemPath(apple.KeyCameraFocalLength35mm))
 	}
-	drMetadata.DateRecorded = parse.Format(time.RFC3339)
-	drMetadata.CameraType = nctg.Model
-	drMetadata.CameraManufacturer = nctg.Make
-	drMetadata.CameraSerial = nctg.SerialNumber
-	drMetadata.CameraFps = fmt.Sprintf("%.2f", float32(nctg.FrameRate.Numerator)/float32(nctg.FrameRate.Denominator))
-	drMetadata.Shutter = nctg.ExposureTime.ShutterFormat()
-	if nctg.ISOInfo.ISOExpansion == "Off" {
-		drMetadata.ISO = nctg.ISOInfo.ISO
-	} else {
-		drMetadata.ISO = fmt.Sprintf("%s %s", nctg.ISOInfo.ISOExpansion, nctg.ISOInfo.ISO)
+	if q.FullFrameRatePlayback {
+		drMetadata.set("CameraNotes", "Full Frame Rate Playback", normalize.SourceAppleQuickTime, normalize.MetaItemPath(apple.KeyFullFrameRatePlayback))
 	}
-	if _, err := strconv.Atoi(strings.TrimRight(nctg.WhiteBalance, "K")); err == nil {
-		drMetadata.WhitePoint = nctg.WhiteBalance
+}
+
+// parseFromXMP the first property found of each field is used
+func (drMetadata *DRMetadata) parseFromXMP(x *xmp.XMP) {
+	fields := []struct {
+		name       string
+		properties []string
+	}{
+		{"ReelNumber", []string{"xmpDM:tapeName", "xmpDM:reelName"}},
+		{"Scene", []string{"xmpDM:scene"}},
+		{"Shot", []string{"xmpDM:shotName"}},
+		{"Take", []string{"xmpDM:takeNumber"}},
+		{"GoodTake", []string{"xmpDM:good"}},
+		{"Comments", []string{"xmpDM:logComment", "dc:description"}},
+	}
+	for _, field := range fields {
+		for _, property := range field.properties {
+			value := x.Get(property)
+			if value == "" {
+				continue
+			}
+			if property == "xmpDM:good" {
+				if strings.EqualFold(value, "true") {
+					value = "1"
+				} else {
+					value = "0"
+				}
+			}
+			drMetadata.set(field.name, value, normalize.SourceXMP, "XMP/"+property)
+			break
+		}
 	}
-	drMetadata.CameraFirmware = nctg.Software
-	drMetadata.LensType = nctg.LensModel
-	drMetadata.LensNotes = nctg.LensInfo
-	drMetadata.LensNumber = nctg.LensSerialNumber
-	drMetadata.CameraAperture = nctg.FNumber.FNumberFormat()
-	drMetadata.FocalPoint = nctg.FocalLength.FocalLengthFormat()
+}
+
+func (drMetadata *DRMetadata) parseFromNctg(nctg *nikon.NCTG) {
+	drMetadata.set("LensNotes", nctg.LensInfo, normalize.SourceNikonNCTG, normalize.NCTGPath("LensInfo"))
 	//drMetadata.Distance =
 	//drMetadata.AspectRatioNotes = nctg.CropHiSpeed
-	if nctg.PictureControlData.PictureControlBase == "N-LOG" {
-		drMetadata.GammaNotes = nctg.PictureControlData.PictureControlBase
-	}
 }
 
-func GetDRMetadataFromMeta(m *meta.Metadata) *DRMetadata {
+// GetDRMetadataFromMeta formats the normalized camera model and adds fields only used by Resolve,
+// profile changes the mapping of fields, nil is the default mapping. Fields written back by this tool take precedence over profile
+func GetDRMetadataFromMeta(m *meta.Metadata, profile *Profile) *DRMetadata {
 	drMetadata := &DRMetadata{}
-	if m.Mp4Meta != nil {
-		if m.Mp4Meta.CreationTime != nil {
-			drMetadata.DateRecorded = m.Mp4Meta.CreationTime.Format(time.RFC3339)
-		}
-		if m.Mp4Meta.VideoProfile != nil {
-			drMetadata.CodecBitrate = m.Mp4Meta.VideoProfile.VideoAvgBitrate
-			drMetadata.PARNotes = m.Mp4Meta.VideoProfile.PixelAspectRatio
-		}
+	ctx := &formatContext{camera: normalize.Normalize(m)}
+	if profile != nil {
+		ctx.location = profile.location
+	}
+	drMetadata.parseFromCamera(ctx)
+	if m.Mp4Meta != nil && m.Mp4Meta.VideoProfile != nil {
+		drMetadata.set("CodecBitrate", m.Mp4Meta.VideoProfile.VideoAvgBitrate, normalize.SourceMP4, "uuid/PROF/vprf/VideoAvgBitrate")
+		drMetadata.set("PARNotes", m.Mp4Meta.VideoProfile.PixelAspectRatio, normalize.SourceMP4, "uuid/PROF/vprf/PixelAspectRatio")
 	}
 	if m.ExifMeta != nil {
 		drMetadata.parseFromExif(m.ExifMeta)
@@ -341,5 +254,17 @@ func GetDRMetadataFromMeta(m *meta.Metadata) *DRMetadata {
 	if len(m.MetaItemKeyValues) > 0 {
 		drMetadata.parseFromMetaItems(m.MetaItemKeyValues)
 	}
+	if m.XMP != nil {
+		drMetadata.parseFromXMP(m.XMP)
+	}
+	if m.MakerMeta.Apple != nil && m.MakerMeta.Apple.QuickTime != nil {
+		drMetadata.parseFromAppleQuickTime(m.MakerMeta.Apple.QuickTime)
+	}
+	if profile != nil {
+		profile.apply(drMetadata, ctx)
+	}
+	if len(m.MetaItemKeyValues) > 0 {
+		drMetadata.parseFromWrittenFields(m.MetaItemKeyValues)
+	}
 	return drMetadata
 }
@@ -0,0 +1,228 @@
+package resolve
+
+import (
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/exif"
+	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
+	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"github.com/fukco/media-metadata/internal/meta"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// checkFields compares the named fields of Resolve metadata, empty string expects an empty field
+func checkFields(t *testing.T, drMetadata *DRMetadata, want map[string]string) {
+	t.Helper()
+	for name, value := range want {
+		if got := reflect.ValueOf(drMetadata).Elem().FieldByName(name).String(); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func sonyRTMDMeta(camera *rtmd.CameraUnitMetadata, lens *rtmd.LensUnitMetadata) *meta.Metadata {
+	return &meta.Metadata{MakerMeta: &meta.MakerMeta{Sony: &meta.Sony{RTMD: &rtmd.RTMD{CameraUnitMetadata: camera, LensUnitMetadata: lens}}}}
+}
+
+func TestSonyRTMDFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		camera *rtmd.CameraUnitMetadata
+		lens   *rtmd.LensUnitMetadata
+		want   map[string]string
+	}{
+		{
+			name:   "shutter fraction",
+			camera: &rtmd.CameraUnitMetadata{ShutterSpeedTime: &common.Rational{Numerator: 1, Denominator: 50}},
+			want:   map[string]string{"Shutter": "1/50"},
+		},
+		{
+			name:   "shutter fraction is kept as recorded",
+			camera: &rtmd.CameraUnitMetadata{ShutterSpeedTime: &common.Rational{Numerator: 1001, Denominator: 48000}},
+			want:   map[string]string{"Shutter": "1001/48000"},
+		},
+		{
+			name:   "long shutter",
+			camera: &rtmd.CameraUnitMetadata{ShutterSpeedTime: &common.Rational{Numerator: 1, Denominator: 2}},
+			want:   map[string]string{"Shutter": "0.5"},
+		},
+		{
+			name:   "iso equals exposure index",
+			camera: &rtmd.CameraUnitMetadata{ISOSensitivity: 800, ExposureIndexOfPhotoMeter: 800},
+			want:   map[string]string{"ISO": "800"},
+		},
+		{
+			name:   "iso with exposure index",
+			camera: &rtmd.CameraUnitMetadata{ISOSensitivity: 800, ExposureIndexOfPhotoMeter: 3200},
+			want:   map[string]string{"ISO": "800 EI:3200"},
+		},
+		{
+			name:   "focal length in millimetres",
+			camera: &rtmd.CameraUnitMetadata{},
+			lens:   &rtmd.LensUnitMetadata{LensZoomPtr: 0.035},
+			want:   map[string]string{"FocalPoint": "35"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkFields(t, GetDRMetadataFromMeta(sonyRTMDMeta(tt.camera, tt.lens), nil), tt.want)
+		})
+	}
+}
+
+func TestNikonNCTGFormats(t *testing.T) {
+	creationTime := time.Date(2024, 5, 1, 1, 0, 0, 0, time.UTC)
+	valid := func() *nikon.NCTG {
+		return &nikon.NCTG{
+			CreateDate:   "2024:05:01 10:00:00",
+			TimeZone:     "+08:00",
+			Model:        "NIKON Z 8",
+			ExposureTime: &common.UFraction{Numerator: 1, Denominator: 50},
+			FNumber:      &common.UFraction{Numerator: 28, Denominator: 10},
+			FocalLength:  &common.UFraction{Numerator: 245, Denominator: 10},
+			ISOInfo:      &nikon.ISOInfo{ISO: "800", ISOExpansion: "Off"},
+		}
+	}
+	tests := []struct {
+		name string
+		nctg func() *nikon.NCTG
+		want map[string]string
+	}{
+		{
+			name: "baseline formats",
+			nctg: valid,
+			want: map[string]string{
+				"DateRecorded":   "2024-05-01T10:00:00+08:00",
+				"CameraType":     "NIKON Z 8",
+				"Shutter":        "1/50",
+				"CameraAperture": "2.8",
+				"FocalPoint":     "24",
+				"ISO":            "800",
+			},
+		},
+		{
+			name: "long exposure",
+			nctg: func() *nikon.NCTG {
+				nctg := valid()
+				nctg.ExposureTime = &common.UFraction{Numerator: 1, Denominator: 2}
+				return nctg
+			},
+			want: map[string]string{"Shutter": "0.5"},
+		},
+		{
+			name: "iso expansion",
+			nctg: func() *nikon.NCTG {
+				nctg := valid()
+				nctg.ISOInfo = &nikon.ISOInfo{ISO: "25600", ISOExpansion: "Hi 0.3"}
+				return nctg
+			},
+			want: map[string]string{"ISO": "Hi 0.3 25600"},
+		},
+		{
+			name: "invalid create date ignores nctg",
+			nctg: func() *nikon.NCTG {
+				nctg := valid()
+				nctg.CreateDate = ""
+				return nctg
+			},
+			want: map[string]string{
+				"DateRecorded": "2024-05-01T01:00:00Z",
+				"CameraType":   "",
+				"Shutter":      "",
+				"ISO":          "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &meta.Metadata{
+				Mp4Meta:   &meta.Mp4Meta{CreationTime: &creationTime},
+				MakerMeta: &meta.MakerMeta{Nikon: &meta.Nikon{NCTG: tt.nctg()}},
+			}
+			checkFields(t, GetDRMetadataFromMeta(m, nil), tt.want)
+		})
+	}
+}
+
+func TestExifFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []*exif.ExifTag
+		want map[string]string
+	}{
+		{
+			name: "text of tags is kept",
+			tags: []*exif.ExifTag{
+				{ID: 0x829a, Value: "1/50"},
+				{ID: 0x829d, Value: "F/2.8"},
+				{ID: 0x920a, Value: "24.5 mm"},
+				{ID: 0x8827, Value: "800"},
+			},
+			want: map[string]string{"Shutter": "1/50", "CameraAperture": "F/2.8", "FocalPoint": "24.5 mm", "ISO": "800"},
+		},
+		{
+			name: "date with offset",
+			tags: []*exif.ExifTag{{ID: 0x9003, Value: "2024:05:01 10:00:00"}, {ID: 0x9011, Value: "+08:00"}},
+			want: map[string]string{"DateRecorded": "2024-05-01T10:00:00+08:00"},
+		},
+		{
+			name: "floating date",
+			tags: []*exif.ExifTag{{ID: 0x9003, Value: "2024:05:01 10:00:00"}},
+			want: map[string]string{"DateRecorded": "2024-05-01 10:00:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &meta.Metadata{
+				ExifMeta:  &exif.ExifMeta{Tags: map[string][]*exif.ExifTag{string(exif.GroupExif): tt.tags}},
+				MakerMeta: &meta.MakerMeta{},
+			}
+			checkFields(t, GetDRMetadataFromMeta(m, nil), tt.want)
+		})
+	}
+}
+
+func TestPanasonicClipMain(t *testing.T) {
+	f, err := os.Open("../../manufacturer/panasonic/testdata/ClipMain.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	clipMain, err := panasonic.Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drMetadata := GetDRMetadataFromMeta(&meta.Metadata{MakerMeta: &meta.MakerMeta{Panasonic: &meta.Panasonic{ClipMain: clipMain}}}, nil)
+	checkFields(t, drMetadata, map[string]string{
+		"DateRecorded":       "2024-05-01T10:00:00+09:00",
+		"CameraManufacturer": "Panasonic",
+		"CameraType":         "AU-EVA1",
+		"CameraSerial":       "C1TA12345",
+		"CameraNotes":        "Gain: 6dB",
+		"CameraFormat":       "AVC-I_4K422/23.98p 10bit Intra Class 4K422",
+		"CameraFps":          "23.98p",
+		"ShutterAngle":       "180.0°",
+		"ISO":                "800 EI:2500",
+		"WhitePoint":         "5600K",
+		"WhiteBalanceTint":   "-4",
+		"NDFilter":           "1/16",
+		"GammaNotes":         "V-Log",
+		"ColorSpaceNotes":    "V-Gamut",
+		"LUTUsed":            "VLOG_709",
+		"LensType":           "LUMIX G X VARIO 12-35mm F2.8",
+		"CameraAperture":     "2.8",
+		"FocalPoint":         "25",
+		"Distance":           "3.20 m",
+		"CodecBitrate":       "AVC-I_4K422/23.98p",
+		"Scene":              "12",
+		"Shot":               "Interview",
+		"Take":               "3",
+		"GoodTake":           "1",
+	})
+	if provenance := drMetadata.Provenance["GoodTake"]; provenance == nil || provenance.Path != "ClipMain/ClipContent/ClipMetadata/ShotMark" {
+		t.Errorf("GoodTake provenance = %+v", provenance)
+	}
+}
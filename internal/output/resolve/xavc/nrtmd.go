@@ -41,40 +41,10 @@ func (nrtmdDisp *NrtmdDisp) parseFromSonyXML(nonRealTimeMeta *nrtmd.NonRealTimeM
 	nrtmdDisp.CaptureFPS = nonRealTimeMeta.VideoFormat.VideoFrame.CaptureFps
 	nrtmdDisp.FormatFPS = nonRealTimeMeta.VideoFormat.VideoFrame.FormatFps
 	nrtmdDisp.RecordingMode = nonRealTimeMeta.RecordingMode.Type
-	var resolution, fileFormat string
-	if nonRealTimeMeta.VideoFormat.VideoLayout.Pixel == "3840" {
-		resolution = "4K"
-	} else if nonRealTimeMeta.VideoFormat.VideoLayout.Pixel == "1080" {
-		resolution = "HD"
+	if format, ok := nonRealTimeMeta.VideoFormat.XAVC(); ok {
+		nrtmdDisp.FileFormatAndRecFrameRate = strings.TrimSpace(format.Name + " " + nrtmdDisp.FormatFPS)
+		nrtmdDisp.Profile = fmt.Sprintf("%s %d", format.Chroma, format.BitDepth)
 	}
-	codec := nonRealTimeMeta.VideoFormat.VideoFrame.VideoCodec
-	codecSplitStrs := strings.Split(codec[:strings.Index(codec, "@")], "_")
-	//https://en.wikipedia.org/wiki/Advanced_Video_Coding#Profiles
-	//https://en.wikipedia.org/wiki/High_Efficiency_Video_Coding#Profiles
-	if strings.HasPrefix(codecSplitStrs[0], "HEVC") {
-		fileFormat = "HS"
-		if codecSplitStrs[3] == "M10P" {
-			// Main 10
-			nrtmdDisp.Profile = "4:2:0 10"
-		} else if codecSplitStrs[3] == "M42210P" {
-			// Main 4:2:2 10
-			nrtmdDisp.Profile = "4:2:2 10"
-		}
-	} else if strings.HasPrefix(codecSplitStrs[0], "AVC") {
-		fileFormat = "S"
-		if codecSplitStrs[3] == "HP" {
-			// High Profile
-			nrtmdDisp.Profile = "4:2:0 8"
-		} else if codecSplitStrs[3] == "H422P" {
-			// High 4:2:2 Profile
-			nrtmdDisp.Profile = "4:2:2 10"
-		} else if codecSplitStrs[3] == "H422IP" {
-			// High 4:2:2 Intra Profile
-			fileFormat = "S-I"
-			nrtmdDisp.Profile = "4:2:2 10"
-		}
-	}
-	nrtmdDisp.FileFormatAndRecFrameRate = fmt.Sprintf("XAVC %s %s %s", fileFormat, resolution, nrtmdDisp.FormatFPS)
 	if len(nonRealTimeMeta.SubStream.Codec) > 0 {
 		nrtmdDisp.IsProxyOn = true
 	} else {
@@ -82,6 +52,11 @@ func (nrtmdDisp *NrtmdDisp) parseFromSonyXML(nonRealTimeMeta *nrtmd.NonRealTimeM
 	}
 	t, _ := time.Parse(time.RFC3339, nonRealTimeMeta.CreationDate.Value)
 	nrtmdDisp.CreationTimestamp = t.Unix()
+	// RTMD of the first frame overrides
+	if timecode, ok := nonRealTimeMeta.LtcChangeTable.StartTimecode(); ok {
+		nrtmdDisp.TimecodeSecs = timecode.Sec + timecode.Min*60 + timecode.Hour*3600
+		nrtmdDisp.TimecodeFrame = timecode.Frame
+	}
 }
 
 func (nrtmdDisp *NrtmdDisp) parseFromSonyRtmd(rtmd *rtmd.RTMD) {
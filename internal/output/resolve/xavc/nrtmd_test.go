@@ -0,0 +1,95 @@
+package xavc
+
+import (
+	"fmt"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"strings"
+	"testing"
+)
+
+// baselineSonyXML file format and profile as parseFromSonyXML derived them before the XAVC profile table
+func baselineSonyXML(format nrtmd.VideoFormat) (string, string) {
+	var resolution, fileFormat, profile string
+	if format.VideoLayout.Pixel == "3840" {
+		resolution = "4K"
+	} else if format.VideoLayout.Pixel == "1080" {
+		resolution = "HD"
+	}
+	codec := format.VideoFrame.VideoCodec
+	codecSplitStrs := strings.Split(codec[:strings.Index(codec, "@")], "_")
+	if strings.HasPrefix(codecSplitStrs[0], "HEVC") {
+		fileFormat = "HS"
+		if codecSplitStrs[3] == "M10P" {
+			profile = "4:2:0 10"
+		} else if codecSplitStrs[3] == "M42210P" {
+			profile = "4:2:2 10"
+		}
+	} else if strings.HasPrefix(codecSplitStrs[0], "AVC") {
+		fileFormat = "S"
+		if codecSplitStrs[3] == "HP" {
+			profile = "4:2:0 8"
+		} else if codecSplitStrs[3] == "H422P" {
+			profile = "4:2:2 10"
+		} else if codecSplitStrs[3] == "H422IP" {
+			fileFormat = "S-I"
+			profile = "4:2:2 10"
+		}
+	}
+	return fmt.Sprintf("XAVC %s %s %s", fileFormat, resolution, format.VideoFrame.FormatFps), profile
+}
+
+func TestParseFromSonyXML(t *testing.T) {
+	tests := []struct {
+		codec       string
+		pixel       string
+		lines       string
+		fps         string
+		wantFormat  string
+		wantProfile string
+		// baseline is true for formats the baseline decoded correctly
+		baseline bool
+	}{
+		{"AVC_3840_2160_HP@L51", "3840", "2160", "25p", "XAVC S 4K 25p", "4:2:0 8", true},
+		{"AVC_3840_2160_H422P@L51", "3840", "2160", "29.97p", "XAVC S 4K 29.97p", "4:2:2 10", true},
+		{"AVC_3840_2160_H422IP@L51", "3840", "2160", "23.98p", "XAVC S-I 4K 23.98p", "4:2:2 10", true},
+		{"HEVC_3840_2160_M10P@L51", "3840", "2160", "50p", "XAVC HS 4K 50p", "4:2:0 10", true},
+		{"HEVC_3840_2160_M42210P@L51", "3840", "2160", "119.88p", "XAVC HS 4K 119.88p", "4:2:2 10", true},
+		// the baseline compared the width of HD with 1080
+		{"AVC_1920_1080_HP@L42", "1920", "1080", "50p", "XAVC S HD 50p", "4:2:0 8", false},
+		{"AVC_1920_1080_H422IP@L42", "1920", "1080", "50i", "XAVC S-I HD 50i", "4:2:2 10", false},
+		{"HEVC_4096_2160_M42210P@L52", "4096", "2160", "24p", "XAVC HS DCI 4K 24p", "4:2:2 10", false},
+		{"HEVC_7680_4320_M42210P@L61", "7680", "4320", "23.98p", "XAVC HS 8K 23.98p", "4:2:2 10", false},
+		{"HEVC_2160_3840_M10P@L51", "2160", "3840", "29.97p", "XAVC HS 4K 29.97p", "4:2:0 10", false},
+		{"AVC_3840_2160_H10IP@L51", "3840", "2160", "25p", "XAVC S-I 4K 25p", "4:2:0 10", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.codec, func(t *testing.T) {
+			xml := &nrtmd.NonRealTimeMeta{VideoFormat: nrtmd.VideoFormat{
+				VideoFrame:  nrtmd.VideoFrame{VideoCodec: tt.codec, FormatFps: tt.fps},
+				VideoLayout: nrtmd.VideoLayout{Pixel: tt.pixel, NumOfVerticalLine: tt.lines},
+			}}
+			nrtmdDisp := &NrtmdDisp{}
+			nrtmdDisp.parseFromSonyXML(xml)
+			if nrtmdDisp.FileFormatAndRecFrameRate != tt.wantFormat || nrtmdDisp.Profile != tt.wantProfile {
+				t.Errorf("got %q %q, want %q %q", nrtmdDisp.FileFormatAndRecFrameRate, nrtmdDisp.Profile, tt.wantFormat, tt.wantProfile)
+			}
+			baselineFormat, baselineProfile := baselineSonyXML(xml.VideoFormat)
+			same := baselineFormat == nrtmdDisp.FileFormatAndRecFrameRate && baselineProfile == nrtmdDisp.Profile
+			if same != tt.baseline {
+				t.Errorf("baseline %q %q, same = %v", baselineFormat, baselineProfile, same)
+			}
+		})
+	}
+}
+
+// TestParseFromSonyXMLUnknown formats not in the profile table leave the fields empty, the baseline panicked on
+// codecs without level or size
+func TestParseFromSonyXMLUnknown(t *testing.T) {
+	for _, codec := range []string{"", "AVC", "MPEG2HD422_1920_1080_422P@HL", "HEVC_3840_2160_X@L51"} {
+		nrtmdDisp := &NrtmdDisp{}
+		nrtmdDisp.parseFromSonyXML(&nrtmd.NonRealTimeMeta{VideoFormat: nrtmd.VideoFormat{VideoFrame: nrtmd.VideoFrame{VideoCodec: codec}}})
+		if nrtmdDisp.FileFormatAndRecFrameRate != "" || nrtmdDisp.Profile != "" {
+			t.Errorf("%q: got %q %q", codec, nrtmdDisp.FileFormatAndRecFrameRate, nrtmdDisp.Profile)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package xavc
 
 import (
+	"errors"
 	"fmt"
 	"github.com/fukco/media-metadata/internal/box"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
@@ -69,13 +70,13 @@ func rtmd2rtmdDisp(rtmd *rtmd.RTMD) *RtmdDisp {
 	return rtmdDisp
 }
 
-func searchMetaTrackMedia(boxDetails []*box.BoxDetail) *box.BoxDetail {
+func searchMetaTrackMedia(r io.ReadSeeker, boxDetails []*box.BoxDetail) *box.BoxDetail {
 	for _, detail := range boxDetails {
-		if isMetaTrackMedia(detail) {
+		if isMetaTrackMedia(r, detail) {
 			return detail
 		}
 		if len(detail.Children) > 0 {
-			result := searchMetaTrackMedia(detail.Children)
+			result := searchMetaTrackMedia(r, detail.Children)
 			if result != nil {
 				return result
 			}
@@ -85,18 +86,8 @@ func searchMetaTrackMedia(boxDetails []*box.BoxDetail) *box.BoxDetail {
 	return nil
 }
 
-func isMetaTrackMedia(boxDetail *box.BoxDetail) bool {
-	if boxDetail.Type == box.MediaBox {
-		for _, detail := range boxDetail.Children {
-			if detail.Type == box.HandlerReferenceBox {
-				hdlr := detail.Boxer.(*box.Hdlr)
-				if string(hdlr.HandlerType[:]) == "meta" {
-					return true
-				}
-			}
-		}
-	}
-	return false
+func isMetaTrackMedia(r io.ReadSeeker, boxDetail *box.BoxDetail) bool {
+	return boxDetail.Type == box.MediaBox && boxDetail.HandlerType(r) == "meta"
 }
 
 type sampleInfo struct {
@@ -120,8 +111,8 @@ func (s *sampleInfo) getSampleOffset(input int) uint32 {
 	return 0
 }
 
-func getSampleInfoFromMetaTrackMediaBox(boxDetail *box.BoxDetail) (*sampleInfo, error) {
-	if boxDetail.Type != box.MediaBox {
+func getSampleInfoFromMetaTrackMediaBox(r io.ReadSeeker, boxDetail *box.BoxDetail) (*sampleInfo, error) {
+	if boxDetail == nil || boxDetail.Type != box.MediaBox {
 		return nil, fmt.Errorf("not mdia box")
 	}
 	info := &sampleInfo{}
@@ -132,33 +123,40 @@ func getSampleInfoFromMetaTrackMediaBox(boxDetail *box.BoxDetail) (*sampleInfo,
 			for _, c2 := range c1.Children {
 				if c2.Type == box.SampleTableBox {
 					for _, child := range c2.Children {
-						result, ok := child.Boxer.(*box.Stsc)
+						if child.Type != box.SampleToChunkBox && child.Type != box.SampleSizeBox && child.Type != box.ChunkOffsetBox {
+							continue
+						}
+						boxer, err := child.Load(r)
+						if err != nil {
+							return nil, err
+						}
+						result, ok := boxer.(*box.Stsc)
 						if ok {
 							stsc = result
 						}
-						stsz, ok := child.Boxer.(*box.Stsz)
+						stsz, ok := boxer.(*box.Stsz)
 						if ok {
 							info.Size = stsz.Size
 							info.SampleCount = stsz.Count
 						}
-						stco, ok := child.Boxer.(*box.Stco)
+						stco, ok := boxer.(*box.Stco)
 						if ok {
 							info.ChunkOffsets = stco.Offsets
-							chunkCount = int(stco.Count)
+							chunkCount = len(stco.Offsets)
 						}
 					}
 				}
 			}
 		}
 	}
-	if stsc == nil {
+	if stsc == nil || len(stsc.Entries) == 0 {
 		return nil, fmt.Errorf("not found stsc")
 	}
 	info.SamplesCountSlice = make([]uint32, chunkCount)
 	var x uint32 = 0
 	for i, j := 0, 0; i < chunkCount; i++ {
 		samplesPerChunk := stsc.Entries[j].SamplesPerChunk
-		if j+1 < int(stsc.EntryCount) && i+2 >= int(stsc.Entries[j+1].FirstChunk) {
+		if j+1 < len(stsc.Entries) && i+2 >= int(stsc.Entries[j+1].FirstChunk) {
 			j++
 		}
 		x += samplesPerChunk
@@ -167,10 +165,39 @@ func getSampleInfoFromMetaTrackMediaBox(boxDetail *box.BoxDetail) (*sampleInfo,
 	return info, nil
 }
 
-func getMetaSampleInfo(r io.ReadSeeker) (*sampleInfo, error) {
-	fileStructure, err := box.ReadFileStructure(r)
+func getMetaSampleInfo(r io.ReadSeeker, fileStructure *box.FileStructure) (*sampleInfo, error) {
+	return getSampleInfoFromMetaTrackMediaBox(r, searchMetaTrackMedia(r, fileStructure.BoxDetails))
+}
+
+var ErrNoRTMDTrack = errors.New("RTMD track not found")
+
+// metaTrackSamples RTMD samples with decode time in seconds and frame rate of the meta track
+type metaTrackSamples struct {
+	Samples   []*box.Sample
+	Timescale uint32
+	FrameRate float64
+}
+
+func (s *metaTrackSamples) time(sample *box.Sample) float64 {
+	return float64(sample.Time) / float64(s.Timescale)
+}
+
+func readMetaTrackSamples(r io.ReadSeeker, fileStructure *box.FileStructure) (*metaTrackSamples, error) {
+	mdia := searchMetaTrackMedia(r, fileStructure.BoxDetails)
+	if mdia == nil {
+		return nil, ErrNoRTMDTrack
+	}
+	timescale := mdia.Timescale(r)
+	samples, err := box.Samples(r, mdia.SampleTable())
 	if err != nil {
 		return nil, err
 	}
-	return getSampleInfoFromMetaTrackMediaBox(searchMetaTrackMedia(fileStructure.BoxDetails))
+	if timescale == 0 || len(samples) == 0 {
+		return nil, ErrNoRTMDTrack
+	}
+	track := &metaTrackSamples{Samples: samples, Timescale: timescale}
+	if n := len(samples); n > 1 && samples[n-1].Time > 0 {
+		track.FrameRate = float64(timescale) * float64(n-1) / float64(samples[n-1].Time)
+	}
+	return track, nil
 }
@@ -3,6 +3,7 @@ package xavc
 import (
 	"errors"
 	"fmt"
+	"github.com/fukco/media-metadata/internal/box"
 	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
 	"io"
 )
@@ -32,6 +33,8 @@ type RtmdCollection struct {
 	CameraMasterGainAdjustmentSlice []*FrameData
 	// ImageStabilizer
 	ImageStabilizerSlice []*FrameData
+	// ND，FX6/FX9可变ND逐帧变化
+	NDFilterSlice []*FrameData
 }
 
 type FrameData struct {
@@ -126,10 +129,29 @@ func RtmdCollectionAppend(collection *RtmdCollection, index int, rtmd *rtmd.RTMD
 		Frame: index,
 		Data:  imageStabilizer,
 	})
+	var ndFilter string
+	if rtmd.CameraUnitMetadata.NDFilter == 1 {
+		ndFilter = "Clear"
+	} else if rtmd.CameraUnitMetadata.NDFilter > 1 {
+		ndFilter = fmt.Sprintf("ND1/%d", rtmd.CameraUnitMetadata.NDFilter)
+	}
+	collection.NDFilterSlice = appendFrameData(collection.NDFilterSlice, &FrameData{
+		Frame: index,
+		Data:  ndFilter,
+	})
 }
 
 func ReadRtmdSlice(r io.ReadSeeker, start int, count int) (*RtmdCollection, error) {
-	info, err := getMetaSampleInfo(r)
+	fileStructure, err := box.ReadFileStructureWithOptions(r, box.ReadOptions{Lazy: true})
+	if err != nil {
+		return nil, err
+	}
+	return ReadRtmdSliceWithStructure(r, fileStructure, start, count)
+}
+
+// ReadRtmdSliceWithStructure reads RTMD of samples with the file structure already read, like one from box.StructureCache
+func ReadRtmdSliceWithStructure(r io.ReadSeeker, fileStructure *box.FileStructure, start int, count int) (*RtmdCollection, error) {
+	info, err := getMetaSampleInfo(r, fileStructure)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +165,7 @@ func ReadRtmdSlice(r io.ReadSeeker, start int, count int) (*RtmdCollection, erro
 	rtmdCollection := &RtmdCollection{}
 	for i := start; i < start+count; i++ {
 		offset := info.getSampleOffset(i)
-		if rtmd, err := rtmd.ReadRTMD(r, info.Size, offset); err != nil {
+		if rtmd, err := rtmd.ReadRTMD(r, info.Size, uint64(offset)); err != nil {
 			return nil, err
 		} else {
 			RtmdCollectionAppend(rtmdCollection, i, rtmd)
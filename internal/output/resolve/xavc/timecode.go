@@ -0,0 +1,180 @@
+package xavc
+
+import (
+	"github.com/fukco/media-metadata/internal/box"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// FrameTimecode timecode and camera clock of a frame
+type FrameTimecode struct {
+	Frame    int
+	Timecode string
+	// WallClock camera clock of the frame, interpolated from the frame where record date and time turns to the next second
+	WallClock *time.Time `json:",omitempty"`
+}
+
+// TimecodeDiscontinuity timecode not following the previous frame, like free-run timecode across a break
+type TimecodeDiscontinuity struct {
+	Frame    int
+	Previous string
+	Timecode string
+	// Gap frames missing between the two frames, negative if timecode goes backwards
+	Gap int
+}
+
+// TimecodeTrack timecode of every RTMD frame
+type TimecodeTrack struct {
+	FrameRate       float64
+	DropFrame       bool
+	Start           string
+	End             string
+	Frames          []*FrameTimecode
+	Discontinuities []*TimecodeDiscontinuity `json:",omitempty"`
+}
+
+func ReadTimecodes(r io.ReadSeeker) (*TimecodeTrack, error) {
+	fileStructure, err := box.ReadFileStructureWithOptions(r, box.ReadOptions{Lazy: true})
+	if err != nil {
+		return nil, err
+	}
+	return ReadTimecodesWithStructure(r, fileStructure)
+}
+
+// ReadTimecodesWithStructure reads timecode and record date and time of every RTMD sample, drop frame of the track is
+// taken from the start timecode of NRT XML as the frame header does not record it
+func ReadTimecodesWithStructure(r io.ReadSeeker, fileStructure *box.FileStructure) (*TimecodeTrack, error) {
+	track, err := readMetaTrackSamples(r, fileStructure)
+	if err != nil {
+		return nil, err
+	}
+	dropFrame := trackDropFrame(r, fileStructure.BoxDetails)
+	timecodes := make([]*rtmd.Timecode, len(track.Samples))
+	dates := make([]*time.Time, len(track.Samples))
+	for i, sample := range track.Samples {
+		frame, err := rtmd.ReadRTMD(r, sample.Size, sample.Offset)
+		if err != nil {
+			return nil, err
+		}
+		frame.Timecode.DropFrame = dropFrame
+		timecodes[i] = frame.Timecode
+		dates[i] = frame.UserDefinedAcquisitionMetadata.CurrentRecordDateTime
+	}
+
+	result := &TimecodeTrack{
+		FrameRate: track.FrameRate,
+		DropFrame: dropFrame,
+		Start:     timecodes[0].String(),
+		End:       timecodes[len(timecodes)-1].String(),
+		Frames:    make([]*FrameTimecode, len(timecodes)),
+	}
+	wallClock := wallClocks(track, dates)
+	for i, timecode := range timecodes {
+		result.Frames[i] = &FrameTimecode{Frame: i, Timecode: timecode.String(), WallClock: wallClock[i]}
+	}
+	result.Discontinuities = discontinuities(timecodes, track.FrameRate)
+	return result, nil
+}
+
+// trackDropFrame drop frame of the start timecode of NRT XML, only the XML box of the nrtm meta box is read.
+// Timecode is non drop frame if there is no XML
+func trackDropFrame(r io.ReadSeeker, boxDetails []*box.BoxDetail) bool {
+	for _, detail := range boxDetails {
+		if detail.Type == box.MetaBox && detail.HandlerType(r) == "nrtm" {
+			xmlDetail := detail.FindChild(box.XMLBox)
+			if xmlDetail == nil {
+				return false
+			}
+			b, err := xmlDetail.Load(r)
+			xmlBox, ok := b.(*box.XML)
+			if err != nil || !ok {
+				return false
+			}
+			nonRealTimeMeta, err := nrtmd.Read(strings.NewReader(xmlBox.Xml))
+			if err != nil {
+				return false
+			}
+			start, ok := nonRealTimeMeta.LtcChangeTable.StartTimecode()
+			return ok && start.DropFrame
+		}
+		if trackDropFrame(r, detail.Children) {
+			return true
+		}
+	}
+	return false
+}
+
+// frameNumbers frame numbers of timecodes since 00:00:00:00 and frames of a day
+func frameNumbers(timecodes []*rtmd.Timecode, fps float64) ([]int, int) {
+	base := int(math.Round(fps))
+	day := rtmd.Timecode{Hour: 24, DropFrame: timecodes[0].DropFrame}
+	numbers := make([]int, len(timecodes))
+	for i, timecode := range timecodes {
+		numbers[i] = timecode.FrameCount(base)
+	}
+	return numbers, day.FrameCount(base)
+}
+
+func discontinuities(timecodes []*rtmd.Timecode, fps float64) []*TimecodeDiscontinuity {
+	if fps <= 0 {
+		return nil
+	}
+	numbers, day := frameNumbers(timecodes, fps)
+	var result []*TimecodeDiscontinuity
+	for i := 1; i < len(numbers); i++ {
+		expected := (numbers[i-1] + 1) % day
+		if numbers[i] == expected {
+			continue
+		}
+		gap := numbers[i] - expected
+		// timecode passing midnight
+		if gap < -day/2 {
+			gap += day
+		}
+		result = append(result, &TimecodeDiscontinuity{
+			Frame:    i,
+			Previous: timecodes[i-1].String(),
+			Timecode: timecodes[i].String(),
+			Gap:      gap,
+		})
+	}
+	return result
+}
+
+// wallClocks anchors record date and time to frames where it turns to the next second, or to the first frame with date
+// if it never changes, other frames are offset from the previous anchor, or the first one, by decode time of the sample
+func wallClocks(track *metaTrackSamples, dates []*time.Time) []*time.Time {
+	var anchors []int
+	for i := 1; i < len(dates); i++ {
+		if dates[i] != nil && dates[i-1] != nil && !dates[i].Equal(*dates[i-1]) {
+			anchors = append(anchors, i)
+		}
+	}
+	if len(anchors) == 0 {
+		for i, date := range dates {
+			if date != nil {
+				anchors = append(anchors, i)
+				break
+			}
+		}
+	}
+	result := make([]*time.Time, len(dates))
+	if len(anchors) == 0 {
+		return result
+	}
+	j := 0
+	for i := range dates {
+		for j+1 < len(anchors) && anchors[j+1] <= i {
+			j++
+		}
+		anchor := anchors[j]
+		offset := track.time(track.Samples[i]) - track.time(track.Samples[anchor])
+		t := dates[anchor].Add(time.Duration(offset * float64(time.Second)))
+		result[i] = &t
+	}
+	return result
+}
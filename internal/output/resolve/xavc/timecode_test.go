@@ -0,0 +1,137 @@
+package xavc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"testing"
+)
+
+func TestDiscontinuities(t *testing.T) {
+	timecodes := []*rtmd.Timecode{
+		{Min: 0, Sec: 59, Frame: 28, DropFrame: true},
+		{Min: 0, Sec: 59, Frame: 29, DropFrame: true},
+		// 00 and 01 are dropped at minute 1
+		{Min: 1, Sec: 0, Frame: 2, DropFrame: true},
+		{Min: 1, Sec: 0, Frame: 3, DropFrame: true},
+		{Min: 5, Sec: 0, Frame: 0, DropFrame: true},
+	}
+	result := discontinuities(timecodes, 30000.0/1001)
+	if len(result) != 1 {
+		t.Fatalf("got %d discontinuities, want 1", len(result))
+	}
+	if result[0].Frame != 4 || result[0].Previous != "00:01:00;03" || result[0].Timecode != "00:05:00;00" {
+		t.Errorf("discontinuity: %+v", result[0])
+	}
+	// frame 8990 of 00:05:00;00 follows frame 1801 of 00:01:00;03
+	if gap := 8990 - 1802; result[0].Gap != gap {
+		t.Errorf("gap %d, want %d", result[0].Gap, gap)
+	}
+}
+
+func TestDiscontinuitiesMidnight(t *testing.T) {
+	timecodes := []*rtmd.Timecode{{Hour: 23, Min: 59, Sec: 59, Frame: 24}, {}}
+	if result := discontinuities(timecodes, 25); len(result) != 0 {
+		t.Errorf("midnight reported as discontinuity: %+v", result[0])
+	}
+}
+
+func testBox(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, boxType...), data...)
+}
+
+func testFullBox(boxType string, payload ...[]byte) []byte {
+	return testBox(boxType, append([][]byte{{0, 0, 0, 0}}, payload...)...)
+}
+
+func uint32s(values ...uint32) []byte {
+	var b []byte
+	for _, v := range values {
+		b = binary.BigEndian.AppendUint32(b, v)
+	}
+	return b
+}
+
+// frameHeader RTMD frame header with binary hours, minutes, seconds and 16-bit frames
+func frameHeader(hour, minute, second byte, frame uint16) []byte {
+	header := append([]byte{0x00, 0x1c, 0x01, 0x00}, make([]byte, 24)...)
+	header[13], header[14], header[15] = hour, minute, second
+	binary.BigEndian.PutUint16(header[16:], frame)
+	return header
+}
+
+// testClip clip of 29.97 fps with an RTMD track of the frame headers and NRT XML if xml is not empty
+func testClip(xml string, headers ...[]byte) []byte {
+	ftyp := testBox("ftyp", []byte("XAVC\x00\x00\x00\x00XAVC"))
+	build := func(offset uint32) []byte {
+		count := uint32(len(headers))
+		stbl := testBox("stbl",
+			testFullBox("stts", uint32s(1, count, 1001)),
+			testFullBox("stsc", uint32s(1, 1, count, 1)),
+			testFullBox("stsz", uint32s(28, count)),
+			testFullBox("stco", uint32s(1, offset)))
+		trak := testBox("trak", testBox("mdia",
+			testFullBox("mdhd", uint32s(0, 0, 30000, count*1001, 0)),
+			testFullBox("hdlr", make([]byte, 4), []byte("meta"), make([]byte, 13)),
+			testBox("minf", stbl)))
+		children := [][]byte{testFullBox("mvhd", make([]byte, 96)), trak}
+		if xml != "" {
+			children = append(children, testFullBox("meta",
+				testFullBox("hdlr", make([]byte, 4), []byte("nrtm"), make([]byte, 13)),
+				testFullBox("xml ", []byte(xml), []byte{0})))
+		}
+		return testBox("moov", children...)
+	}
+	moov := build(0)
+	moov = build(uint32(len(ftyp) + len(moov) + 8))
+	return bytes.Join([][]byte{ftyp, moov, testBox("mdat", headers...)}, nil)
+}
+
+func nrtXML(start string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?><NonRealTimeMeta><LtcChangeTable tcFps="30">` +
+		`<LtcChange frameCount="0" value="` + start + `" status="increment"/></LtcChangeTable></NonRealTimeMeta>`
+}
+
+func TestReadTimecodes(t *testing.T) {
+	headers := [][]byte{frameHeader(0, 0, 59, 28), frameHeader(0, 0, 59, 29), frameHeader(0, 1, 0, 2)}
+	tests := []struct {
+		name      string
+		xml       string
+		dropFrame bool
+		start     string
+		breaks    int
+	}{
+		// 28 00 59 00 is 00:00:59;28 with the drop frame bit 6 of frames
+		{name: "drop frame of NRT XML", xml: nrtXML("68590000"), dropFrame: true, start: "00:00:59;28"},
+		{name: "non drop frame of NRT XML", xml: nrtXML("28590000"), start: "00:00:59:28", breaks: 1},
+		{name: "without NRT XML", start: "00:00:59:28", breaks: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track, err := ReadTimecodes(bytes.NewReader(testClip(tt.xml, headers...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if track.DropFrame != tt.dropFrame || track.Start != tt.start || len(track.Frames) != 3 {
+				t.Errorf("track = %+v", track)
+			}
+			if len(track.Discontinuities) != tt.breaks {
+				t.Errorf("got %d discontinuities, want %d", len(track.Discontinuities), tt.breaks)
+			}
+		})
+	}
+}
+
+// TestFrameHeaderFlags the frame header is binary and has no flag bits, frames with the bits of SMPTE 12M BCD flags
+// set are frame numbers, drop frame only comes from NRT XML
+func TestFrameHeaderFlags(t *testing.T) {
+	track, err := ReadTimecodes(bytes.NewReader(testClip("", frameHeader(0x01, 0x02, 0x83, 0x00c5))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if track.DropFrame || track.Start != "01:02:131:197" {
+		t.Errorf("track = %+v", track)
+	}
+}
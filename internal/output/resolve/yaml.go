@@ -0,0 +1,345 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errYAML = errors.New("yaml")
+
+// yamlParser parses the subset of YAML used by profile files: block mappings and sequences, flow sequences,
+// plain and quoted scalars, literal block scalars and comments. Anchors, tags, flow mappings and multiple documents
+// are not supported. Scalars are strings as all values of profile are strings
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+// parseYAML returns map[string]any, []any, string or nil
+func parseYAML(data []byte) (any, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")}
+	indent, ok, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return map[string]any{}, nil
+	}
+	value, err := p.parseBlock(indent)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok, _ := p.peek(); ok {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return value, nil
+}
+
+func (p *yamlParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("%w: line %d: %s", errYAML, p.pos+1, fmt.Sprintf(format, a...))
+}
+
+// peek skips blank and comment lines, returns indentation of the next line
+func (p *yamlParser) peek() (int, bool, error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		text := strings.TrimLeft(line, " ")
+		if trimmed := strings.TrimSpace(text); trimmed == "" || trimmed[0] == '#' || trimmed == "---" {
+			continue
+		}
+		if text[0] == '\t' {
+			return 0, false, p.errorf("tab in indentation")
+		}
+		return len(line) - len(text), true, nil
+	}
+	return 0, false, nil
+}
+
+// text current line without indentation and comment
+func (p *yamlParser) text() string {
+	return strings.TrimSpace(stripYAMLComment(p.lines[p.pos]))
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if isYAMLSequenceItem(p.text()) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	result := make(map[string]any)
+	for {
+		current, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || current < indent {
+			return result, nil
+		}
+		text := p.text()
+		if current > indent || isYAMLSequenceItem(text) {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, value, err := splitYAMLKey(text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if _, ok := result[key]; ok {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+		switch {
+		case value == "|" || value == "|-":
+			result[key] = p.parseLiteral(indent, value == "|-")
+		case value != "":
+			if result[key], err = parseYAMLScalar(value); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+		default:
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			// items of a sequence may have the same indentation as its key
+			if ok && (next > indent || next == indent && isYAMLSequenceItem(p.text())) {
+				if result[key], err = p.parseBlock(next); err != nil {
+					return nil, err
+				}
+			} else {
+				result[key] = nil
+			}
+		}
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	result := make([]any, 0)
+	for {
+		current, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || current < indent {
+			return result, nil
+		}
+		text := p.text()
+		if current == indent && !isYAMLSequenceItem(text) {
+			return result, nil
+		}
+		if current > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		rest := strings.TrimSpace(text[1:])
+		switch {
+		case rest == "":
+			p.pos++
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			var value any
+			if ok && next > indent {
+				if value, err = p.parseBlock(next); err != nil {
+					return nil, err
+				}
+			}
+			result = append(result, value)
+		case isYAMLMappingItem(rest):
+			// mapping of the item starts after "- ", the line is parsed again with the key as indentation
+			line := strings.TrimLeft(p.lines[p.pos], " ")[1:]
+			offset := current + 1 + len(line) - len(strings.TrimLeft(line, " "))
+			p.lines[p.pos] = strings.Repeat(" ", offset) + strings.TrimLeft(line, " ")
+			value, err := p.parseMapping(offset)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		default:
+			value, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			result = append(result, value)
+			p.pos++
+		}
+	}
+}
+
+// parseLiteral lines of literal block scalar more indented than its key, | keeps the final line break, |- strips it
+func (p *yamlParser) parseLiteral(indent int, strip bool) string {
+	var lines []string
+	content := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := strings.TrimRight(p.lines[p.pos], " \r")
+		text := strings.TrimLeft(line, " ")
+		if text == "" {
+			lines = append(lines, "")
+			continue
+		}
+		current := len(line) - len(text)
+		if current <= indent || content >= 0 && current < content {
+			break
+		}
+		if content < 0 {
+			content = current
+		}
+		lines = append(lines, line[content:])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	text := strings.Join(lines, "\n")
+	if !strip && text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isYAMLMappingItem(text string) bool {
+	if strings.HasPrefix(text, "[") {
+		return false
+	}
+	_, _, err := splitYAMLKey(text)
+	return err == nil
+}
+
+// splitYAMLKey splits key: value, value is empty if the line ends with the key
+func splitYAMLKey(text string) (string, string, error) {
+	end := 0
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		end = quoteEnd(text)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quote in %q", text)
+		}
+	}
+	index := strings.Index(text[end:], ": ")
+	if index < 0 {
+		if !strings.HasSuffix(text, ":") || len(text)-1 < end {
+			return "", "", fmt.Errorf("expected key: value, got %q", text)
+		}
+		index = len(text) - 1 - end
+	}
+	key, err := parseYAMLText(strings.TrimSpace(text[:end+index]))
+	if err != nil {
+		return "", "", err
+	}
+	return key, strings.TrimSpace(text[end+index+1:]), nil
+}
+
+func parseYAMLScalar(s string) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %q", s)
+		}
+		result := make([]any, 0)
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		if inner == "" {
+			return result, nil
+		}
+		items, err := splitYAMLFlow(inner)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			value, err := parseYAMLText(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	case s == "{}":
+		return map[string]any{}, nil
+	case strings.HasPrefix(s, "{"):
+		return nil, fmt.Errorf("flow mapping is not supported: %q", s)
+	}
+	return parseYAMLText(s)
+}
+
+// parseYAMLText plain or quoted scalar, double quoted scalar uses the escapes of Go
+func parseYAMLText(s string) (string, error) {
+	if s == "" || s[0] != '"' && s[0] != '\'' {
+		return s, nil
+	}
+	if quoteEnd(s) != len(s) {
+		return "", fmt.Errorf("invalid quoted scalar %q", s)
+	}
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// quoteEnd index after the closing quote of quoted scalar at the start of s, -1 if not closed
+func quoteEnd(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// splitYAMLFlow splits items of flow sequence by commas outside quotes
+func splitYAMLFlow(s string) ([]string, error) {
+	var items []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			end := quoteEnd(s[i:])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			i += end - 1
+		case ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		case '[', '{':
+			return nil, fmt.Errorf("nested flow collection is not supported: %q", s)
+		}
+	}
+	// trailing comma does not add an item
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items, nil
+}
+
+// stripYAMLComment removes comment starting with # at the start of line or after a space, outside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\', quote == '\'' && c == '\'' && i+1 < len(line) && line[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// quotes only start a scalar at its first character
+			if i == 0 || strings.IndexByte(" [,:-", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,82 @@
+package resolve
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want any
+	}{
+		{"empty", "# comment only\n", map[string]any{}},
+		{"scalars", "a: plain text\nb: 'it''s # not a comment'\nc: \"tab\\tescaped\"\nd:\n", map[string]any{
+			"a": "plain text", "b": "it's # not a comment", "c": "tab\tescaped", "d": nil,
+		}},
+		{"comments", "--- \na: 1 # comment\n\n# line\nb: x#y\n", map[string]any{"a": "1", "b": "x#y"}},
+		{"nested", "a:\n  b:\n    c: d\n  e: f\n", map[string]any{"a": map[string]any{"b": map[string]any{"c": "d"}, "e": "f"}}},
+		{"sequences", "a:\n  - x\n  - 'y'\nb:\n- z\nc: [p, \"q, r\", ]\nd: []\n", map[string]any{
+			"a": []any{"x", "y"}, "b": []any{"z"}, "c": []any{"p", "q, r"}, "d": []any{},
+		}},
+		{"sequence of mappings", "- name: a\n  value: 1\n-\n  name: b\n", []any{
+			map[string]any{"name": "a", "value": "1"}, map[string]any{"name": "b"},
+		}},
+		{"quoted key", "\"Shutter: Speed\": x\n'ISO':\n", map[string]any{"Shutter: Speed": "x", "ISO": nil}},
+		{"literal", "a: |\n  line 1\n\n    indented\nb: |-\n  {{.Text}}\n  mm\nc: x\n", map[string]any{
+			"a": "line 1\n\n  indented\n", "b": "{{.Text}}\nmm", "c": "x",
+		}},
+		{"crlf", "a: x\r\nb: y\r\n", map[string]any{"a": "x", "b": "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"indentation", "a: x\n  b: y\n"},
+		{"tab", "a:\n\tb: y\n"},
+		{"duplicate key", "a: x\na: y\n"},
+		{"not a mapping", "a: x\njust text\n"},
+		{"sequence in mapping", "a: x\n- y\n"},
+		{"unterminated quote", "a: 'x\n"},
+		{"unterminated flow", "a: [x, y\n"},
+		{"flow mapping", "a: {{.Text}}\n"},
+		{"nested flow", "a: [[x]]\n"},
+		{"invalid escape", "a: \"\\q\"\n"},
+		{"dedent in sequence", "a:\n    - x\n  - y\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.yaml))
+			if err == nil {
+				t.Fatalf("parseYAML = %#v, want error", got)
+			}
+			if !errors.Is(err, errYAML) {
+				t.Errorf("error = %v", err)
+			}
+		})
+	}
+}
+
+func FuzzParseYAML(f *testing.F) {
+	f.Add([]byte(yamlProfile))
+	f.Add([]byte("- a: |\n    x\n  b: [c, 'd''e']\n-\n  - f\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = parseYAML(data)
+	})
+}
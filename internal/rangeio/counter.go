@@ -0,0 +1,23 @@
+package rangeio
+
+import "io"
+
+// Counter counts bytes read through the io.ReadSeeker, like a local file on NAS
+type Counter struct {
+	io.ReadSeeker
+	bytesRead int64
+}
+
+func NewCounter(r io.ReadSeeker) *Counter {
+	return &Counter{ReadSeeker: r}
+}
+
+func (c *Counter) Read(p []byte) (int, error) {
+	n, err := c.ReadSeeker.Read(p)
+	c.bytesRead += int64(n)
+	return n, err
+}
+
+func (c *Counter) BytesRead() int64 {
+	return c.bytesRead
+}
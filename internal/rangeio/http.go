@@ -0,0 +1,109 @@
+package rangeio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrRangeNotSupported = errors.New("server does not support range requests")
+
+// HTTPReaderAt reads byte ranges of a remote file with HTTP range requests,
+// works with S3 compatible object storage through presigned URLs
+type HTTPReaderAt struct {
+	client *http.Client
+	url    string
+	size   int64
+}
+
+// NewHTTPReaderAt gets the file size with a one byte range request,
+// GET is used instead of HEAD as presigned URLs are only valid for the signed method
+func NewHTTPReaderAt(client *http.Client, url string) (*HTTPReaderAt, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	h := &HTTPReaderAt{client: client, url: url}
+	resp, err := h.request(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// empty file, Content-Range: bytes */0
+	case http.StatusOK:
+		return nil, ErrRangeNotSupported
+	default:
+		return nil, fmt.Errorf("range request failed: %s", resp.Status)
+	}
+	// Content-Range: bytes 0-0/12345
+	contentRange := resp.Header.Get("Content-Range")
+	i := strings.LastIndexByte(contentRange, '/')
+	if i < 0 {
+		return nil, fmt.Errorf("%w: invalid Content-Range %q", ErrRangeNotSupported, contentRange)
+	}
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("%w: unknown size in Content-Range %q", ErrRangeNotSupported, contentRange)
+	}
+	h.size = size
+	return h, nil
+}
+
+func (h *HTTPReaderAt) Size() int64 {
+	return h.size
+}
+
+func (h *HTTPReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= h.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	end := off + int64(len(p)) - 1
+	if end >= h.size {
+		end = h.size - 1
+	}
+	resp, err := h.get(off, end)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	n, err := io.ReadFull(resp.Body, p[:end-off+1])
+	if err != nil {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (h *HTTPReaderAt) get(start, end int64) (*http.Response, error) {
+	resp, err := h.request(start, end)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil, ErrRangeNotSupported
+		}
+		return nil, fmt.Errorf("range request failed: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+func (h *HTTPReaderAt) request(start, end int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	return h.client.Do(req)
+}
@@ -0,0 +1,124 @@
+package rangeio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+// rangeServer serves data with range support of http.ServeContent and counts requests
+func rangeServer(t *testing.T, data []byte, requests *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			requests.Add(1)
+		}
+		http.ServeContent(w, r, "clip.mp4", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPReaderAtPartialContent(t *testing.T) {
+	data := testData(1000)
+	server := rangeServer(t, data, nil)
+	h, err := NewHTTPReaderAt(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Size() != int64(len(data)) {
+		t.Fatalf("size %d, want %d", h.Size(), len(data))
+	}
+	p := make([]byte, 100)
+	n, err := h.ReadAt(p, 300)
+	if err != nil || n != len(p) {
+		t.Fatalf("ReadAt = %d, %v", n, err)
+	}
+	if !bytes.Equal(p, data[300:400]) {
+		t.Fatal("data of range 300-399 does not match")
+	}
+}
+
+func TestHTTPReaderAtRangeIgnored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(testData(1000))
+	}))
+	defer server.Close()
+	if _, err := NewHTTPReaderAt(server.Client(), server.URL); !errors.Is(err, ErrRangeNotSupported) {
+		t.Fatalf("err = %v, want ErrRangeNotSupported", err)
+	}
+}
+
+func TestHTTPReaderAtEOF(t *testing.T) {
+	data := testData(1000)
+	server := rangeServer(t, data, nil)
+	h, err := NewHTTPReaderAt(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 100)
+	n, err := h.ReadAt(p, 950)
+	if n != 50 || err != io.EOF {
+		t.Fatalf("ReadAt across the end = %d, %v, want 50, EOF", n, err)
+	}
+	if !bytes.Equal(p[:n], data[950:]) {
+		t.Fatal("data of the last 50 bytes does not match")
+	}
+	if n, err = h.ReadAt(p, 1000); n != 0 || err != io.EOF {
+		t.Fatalf("ReadAt at the end = %d, %v, want 0, EOF", n, err)
+	}
+}
+
+func TestHTTPReaderAtShortBody(t *testing.T) {
+	data := testData(1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "bytes=0-0" {
+			http.ServeContent(w, r, "clip.mp4", time.Time{}, bytes.NewReader(data))
+			return
+		}
+		// connection closed before the range is sent completely
+		w.Header().Set("Content-Range", "bytes 0-99/1000")
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data[:40])
+	}))
+	defer server.Close()
+	h, err := NewHTTPReaderAt(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := h.ReadAt(make([]byte, 100), 0)
+	if n != 40 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("ReadAt = %d, %v, want 40, ErrUnexpectedEOF", n, err)
+	}
+}
+
+func TestHTTPReaderAtEmptyFile(t *testing.T) {
+	// S3 answers range requests of an empty object with 416
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes */0")
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer server.Close()
+	h, err := NewHTTPReaderAt(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Size() != 0 {
+		t.Fatalf("size %d, want 0", h.Size())
+	}
+	if n, err := h.ReadAt(make([]byte, 8), 0); n != 0 || err != io.EOF {
+		t.Fatalf("ReadAt = %d, %v, want 0, EOF", n, err)
+	}
+}
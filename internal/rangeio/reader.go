@@ -0,0 +1,203 @@
+package rangeio
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	// DefaultBlockSize size of each fetch, small reads of box headers are served from the same block
+	DefaultBlockSize = 64 << 10
+	// DefaultMaxBlocks blocks kept in cache, 16 MiB with default block size
+	DefaultMaxBlocks = 256
+)
+
+var ErrInvalidSeek = errors.New("invalid seek position")
+
+// Stats statistics of the underlying reader
+type Stats struct {
+	// BytesRead bytes fetched from the underlying reader
+	BytesRead int64
+	// Requests number of ReadAt calls to the underlying reader
+	Requests int
+	// CacheHits reads served from cache only
+	CacheHits int
+}
+
+// Reader adapts io.ReaderAt to io.ReadSeeker, data is fetched in aligned blocks and cached,
+// adjacent missing blocks are fetched with one request. Reader is not safe for concurrent use.
+type Reader struct {
+	r         io.ReaderAt
+	size      int64
+	offset    int64
+	blockSize int64
+	maxBlocks int
+	blocks    map[int64][]byte
+	// order blocks in the order of fetching, the oldest is evicted first
+	order []int64
+	stats Stats
+}
+
+// NewReader creates reader of the specified size with default block size
+func NewReader(r io.ReaderAt, size int64) *Reader {
+	return NewReaderSize(r, size, DefaultBlockSize, DefaultMaxBlocks)
+}
+
+// NewReaderSize creates reader with the block size and the max number of cached blocks
+func NewReaderSize(r io.ReaderAt, size int64, blockSize int, maxBlocks int) *Reader {
+	if blockSize <= 0 {
+		blockSize = DefaultBlockSize
+	}
+	if maxBlocks <= 0 {
+		maxBlocks = DefaultMaxBlocks
+	}
+	return &Reader{
+		r:         r,
+		size:      size,
+		blockSize: int64(blockSize),
+		maxBlocks: maxBlocks,
+		blocks:    make(map[int64][]byte, maxBlocks),
+	}
+}
+
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+func (r *Reader) Stats() Stats {
+	return r.stats
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, ErrInvalidSeek
+	}
+	if offset < 0 {
+		return 0, ErrInvalidSeek
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, ErrInvalidSeek
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > r.size {
+		end = r.size
+	}
+	first, last := off/r.blockSize, (end-1)/r.blockSize
+	if last-first+1 > int64(r.maxBlocks) {
+		// larger than the cache, like payload of a big moov
+		return r.readDirect(p, off, end)
+	}
+	if err := r.fetch(first, last); err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := first; i <= last; i++ {
+		block := r.blocks[i]
+		start := off + int64(n) - i*r.blockSize
+		n += copy(p[n:], block[start:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *Reader) readDirect(p []byte, off, end int64) (int, error) {
+	n, err := r.r.ReadAt(p[:end-off], off)
+	r.stats.Requests++
+	r.stats.BytesRead += int64(n)
+	if n == int(end-off) {
+		err = nil
+		if n < len(p) {
+			err = io.EOF
+		}
+	} else if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// fetch reads missing blocks between first and last, each run of adjacent missing blocks is one request
+func (r *Reader) fetch(first, last int64) error {
+	hit := true
+	for i := first; i <= last; {
+		if _, ok := r.blocks[i]; ok {
+			i++
+			continue
+		}
+		hit = false
+		j := i
+		for j+1 <= last {
+			if _, ok := r.blocks[j+1]; ok {
+				break
+			}
+			j++
+		}
+		start, end := i*r.blockSize, (j+1)*r.blockSize
+		if end > r.size {
+			end = r.size
+		}
+		data := make([]byte, end-start)
+		n, err := r.r.ReadAt(data, start)
+		r.stats.Requests++
+		r.stats.BytesRead += int64(n)
+		if n < len(data) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		for k := i; k <= j; k++ {
+			blockEnd := (k - i + 1) * r.blockSize
+			if blockEnd > int64(len(data)) {
+				blockEnd = int64(len(data))
+			}
+			r.put(k, data[(k-i)*r.blockSize:blockEnd], first, last)
+		}
+		i = j + 1
+	}
+	if hit {
+		r.stats.CacheHits++
+	}
+	return nil
+}
+
+// put caches the block, blocks between first and last are being read and never evicted
+func (r *Reader) put(index int64, block []byte, first, last int64) {
+	for len(r.order) >= r.maxBlocks {
+		k := 0
+		for k < len(r.order) && r.order[k] >= first && r.order[k] <= last {
+			k++
+		}
+		if k == len(r.order) {
+			break
+		}
+		delete(r.blocks, r.order[k])
+		r.order = append(r.order[:k], r.order[k+1:]...)
+	}
+	r.blocks[index] = block
+	r.order = append(r.order, index)
+}
@@ -0,0 +1,87 @@
+package rangeio
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReaderBlockCache(t *testing.T) {
+	data := testData(1000)
+	var requests atomic.Int64
+	server := rangeServer(t, data, &requests)
+	h, err := NewHTTPReaderAt(server.Client(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewReaderSize(h, h.Size(), 64, 4)
+	header := make([]byte, 8)
+	// box headers of the same block are read with one request
+	for _, off := range []int64{0, 8, 56} {
+		if _, err := r.ReadAt(header, off); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(header, data[off:off+8]) {
+			t.Fatalf("data at %d does not match", off)
+		}
+	}
+	stats := r.Stats()
+	if stats.Requests != 1 || stats.CacheHits != 2 || stats.BytesRead != 64 {
+		t.Fatalf("stats %+v, want 1 request, 2 cache hits and 64 bytes", stats)
+	}
+	// one request to get the size and one for the block
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("server got %d requests, want 2", n)
+	}
+	// adjacent missing blocks 1 to 3 are fetched with one request
+	if _, err := r.ReadAt(make([]byte, 100), 100); err != nil {
+		t.Fatal(err)
+	}
+	if stats = r.Stats(); stats.Requests != 2 || stats.BytesRead != 64+192 {
+		t.Fatalf("stats %+v, want 2 requests and 256 bytes", stats)
+	}
+}
+
+func TestReaderEviction(t *testing.T) {
+	data := testData(1000)
+	r := NewReaderSize(bytes.NewReader(data), int64(len(data)), 64, 2)
+	p := make([]byte, 8)
+	for _, off := range []int64{0, 64, 128, 0} {
+		if _, err := r.ReadAt(p, off); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// block 0 is evicted by block 2 and fetched again
+	if stats := r.Stats(); stats.Requests != 4 || stats.CacheHits != 0 {
+		t.Fatalf("stats %+v, want 4 requests without cache hits", stats)
+	}
+	// reads larger than the cache bypass it
+	if n, err := r.ReadAt(make([]byte, 500), 200); n != 500 || err != nil {
+		t.Fatalf("ReadAt = %d, %v", n, err)
+	}
+	if len(r.blocks) > 2 {
+		t.Fatalf("%d blocks cached, want at most 2", len(r.blocks))
+	}
+}
+
+func TestReaderReadSeek(t *testing.T) {
+	data := testData(1000)
+	r := NewReaderSize(bytes.NewReader(data), int64(len(data)), 64, 4)
+	if _, err := r.Seek(-100, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	tail, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tail, data[900:]) {
+		t.Fatal("data of the last 100 bytes does not match")
+	}
+	if n, err := r.ReadAt(make([]byte, 10), 995); n != 5 || err != io.EOF {
+		t.Fatalf("ReadAt across the end = %d, %v, want 5, EOF", n, err)
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err != ErrInvalidSeek {
+		t.Fatalf("err = %v, want ErrInvalidSeek", err)
+	}
+}
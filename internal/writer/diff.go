@@ -0,0 +1,44 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteDiff prints changes and layout of the plan in unified diff like format
+func (p *Plan) WriteDiff(w io.Writer) error {
+	for _, change := range p.Changes {
+		if _, err := fmt.Fprintf(w, "@@ %s %s\n", change.Path, change.Key); err != nil {
+			return err
+		}
+		if change.Old != nil {
+			if *change.Old == change.New {
+				if _, err := fmt.Fprintf(w, "  %q\n", change.New); err != nil {
+					return err
+				}
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "- %q\n", *change.Old); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "+ %q\n", change.New); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "moov at %d: %d -> %d bytes, %s", p.MoovOffset, p.OldMoovSize, p.NewMoovSize, p.Strategy)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p.Strategy == InPlace && p.FreeSize >= 0:
+		_, err = fmt.Fprintf(w, ", free padding %d -> %d bytes\n", p.FreeSize, int64(p.OldMoovSize)+p.FreeSize-int64(p.NewMoovSize))
+	case p.Strategy == InPlace && p.NewMoovSize < p.OldMoovSize:
+		_, err = fmt.Fprintf(w, ", free padding 0 -> %d bytes\n", p.OldMoovSize-p.NewMoovSize)
+	case p.Strategy == InPlace:
+		_, err = fmt.Fprintln(w)
+	default:
+		_, err = fmt.Fprintf(w, ", %d chunk offsets shifted\n", p.ShiftedChunkOffsets)
+	}
+	return err
+}
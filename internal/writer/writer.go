@@ -0,0 +1,516 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/fukco/media-metadata/internal/box"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrMovieBoxNotFound = errors.New("moov box not found")
+
+const (
+	keyNamespaceMdta = "mdta"
+	// languageUndetermined packed ISO 639-2/T code "und"
+	languageUndetermined = 0x55C4
+)
+
+// Strategy how the rewritten moov box is placed in file
+type Strategy string
+
+const (
+	// InPlace moov size is unchanged or the size difference is absorbed by free box following it.
+	// Writing back to the same file overwrites the moov region directly and is not atomic,
+	// a write interrupted by a crash or power loss can leave a corrupted moov.
+	InPlace Strategy = "in-place"
+	// Rewrite file is rewritten, chunk offsets are shifted if media data follows moov
+	Rewrite Strategy = "rewrite"
+)
+
+// Edits metadata to add or replace
+type Edits struct {
+	// Items mdta keys and utf-8 values of moov/meta
+	Items map[string]string
+	// UserData QuickTime text atoms of moov/udta, like ©cmt
+	UserData map[box.BoxType]string
+}
+
+// IsEmpty checks whether there are no edits
+func (e *Edits) IsEmpty() bool {
+	return len(e.Items) == 0 && len(e.UserData) == 0
+}
+
+// Change a single edit of box
+type Change struct {
+	Path string
+	Key  string
+	Old  *string
+	New  string
+}
+
+// Plan changes and layout of the write-back
+type Plan struct {
+	Changes     []*Change
+	Strategy    Strategy
+	MoovOffset  uint64
+	OldMoovSize uint64
+	NewMoovSize uint64
+	// FreeSize size of free box after moov, -1 if there is no free box
+	FreeSize int64
+	// ShiftedChunkOffsets number of stco/co64 entries shifted
+	ShiftedChunkOffsets int
+
+	moov      *box.Node
+	replace   uint64
+	insertion []byte
+}
+
+// Apply writes edits to the media file, the result is written to output, or back to the file if output is empty.
+// Nothing is written if dryRun is true.
+//
+// A rewrite goes to a temporary file renamed over output. An in-place write back to the file overwrites the moov
+// region without a temporary copy, which avoids copying media data but is not atomic, specify output to keep
+// the original file untouched.
+func Apply(path string, edits *Edits, output string, dryRun bool) (*Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := NewPlan(f, edits)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || dryRun {
+		return plan, err
+	}
+	if output == "" && plan.Strategy == InPlace {
+		return plan, plan.writeInPlace(path)
+	}
+	if output == "" {
+		output = path
+	}
+	return plan, plan.writeFile(path, output)
+}
+
+// NewPlan reads moov box and applies edits to it in memory
+func NewPlan(r io.ReadSeeker, edits *Edits) (*Plan, error) {
+	infos, err := readTopLevelBoxInfos(r)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, info := range infos {
+		if info.Type == box.MovieBox {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrMovieBoxNotFound
+	}
+	moovInfo := infos[index]
+	if _, err := moovInfo.SeekToStart(r); err != nil {
+		return nil, err
+	}
+	moov, err := box.ReadNode(r)
+	if err != nil {
+		return nil, err
+	}
+	if moov.Children == nil {
+		return nil, fmt.Errorf("unable to parse children of moov box at %d", moovInfo.Offset)
+	}
+
+	plan := &Plan{
+		MoovOffset:  moovInfo.Offset,
+		OldMoovSize: moovInfo.Size,
+		FreeSize:    -1,
+		moov:        moov,
+	}
+	if err := plan.applyItems(moov, edits.Items); err != nil {
+		return nil, err
+	}
+	if err := plan.applyUserData(moov, edits.UserData); err != nil {
+		return nil, err
+	}
+	plan.NewMoovSize = moov.Size()
+
+	var free *box.BoxInfo
+	if index+1 < len(infos) && (infos[index+1].Type == box.FreeSpaceBox || infos[index+1].Type == box.SkipBox) {
+		free = infos[index+1]
+		plan.FreeSize = int64(free.Size)
+	}
+	plan.layout(free)
+	if plan.Strategy == Rewrite {
+		// media data after moov moves with the size difference
+		delta := int64(plan.NewMoovSize) - int64(plan.OldMoovSize)
+		plan.ShiftedChunkOffsets, err = moov.ShiftChunkOffsets(moovInfo.Offset+moovInfo.Size, delta)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func readTopLevelBoxInfos(r io.ReadSeeker) ([]*box.BoxInfo, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	infos := make([]*box.BoxInfo, 0, 8)
+	for offset := int64(0); offset < end; {
+		bi, err := box.ReadBoxInfo(r)
+		if err != nil {
+			return nil, err
+		}
+		if bi.Size < 8 || bi.Offset+bi.Size > uint64(end) {
+			return nil, fmt.Errorf("invalid size %d of box %s at %d", bi.Size, bi.Type, bi.Offset)
+		}
+		infos = append(infos, bi)
+		if offset, err = bi.SeekToEnd(r); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// layout decides where the new moov goes, free box following moov is used as padding if possible
+func (p *Plan) layout(free *box.BoxInfo) {
+	delta := int64(p.NewMoovSize) - int64(p.OldMoovSize)
+	p.replace = p.OldMoovSize
+	switch {
+	case delta == 0:
+		p.Strategy = InPlace
+		return
+	case free != nil:
+		left := int64(free.Size) - delta
+		if left == 0 || left >= 8 {
+			p.Strategy = InPlace
+			p.replace += free.Size
+			if left > 0 {
+				p.insertion = freeBox(uint64(left))
+			} else {
+				p.insertion = []byte{}
+			}
+			return
+		}
+	case delta <= -8:
+		p.Strategy = InPlace
+		p.insertion = freeBox(uint64(-delta))
+		return
+	}
+	p.Strategy = Rewrite
+}
+
+func freeBox(size uint64) []byte {
+	b := make([]byte, size)
+	binary.BigEndian.PutUint32(b, uint32(size))
+	binary.BigEndian.PutUint32(b[4:], uint32(box.FreeSpaceBox))
+	return b
+}
+
+func (p *Plan) regionBytes() []byte {
+	return append(p.moov.Bytes(), p.insertion...)
+}
+
+// writeInPlace overwrites moov and the free box following it, the size of file is unchanged
+func (p *Plan) writeInPlace(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(p.regionBytes(), int64(p.MoovOffset))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeFile copies media file with the replaced moov region to a temporary file, then renames it to output
+func (p *Plan) writeFile(path string, output string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	fileInfo, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".*")
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = p.copyTo(f, tmp)
+	if err == nil {
+		err = tmp.Chmod(fileInfo.Mode().Perm())
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), output)
+}
+
+func (p *Plan) copyTo(r io.ReadSeeker, w io.Writer) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(w, r, int64(p.MoovOffset)); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.regionBytes()); err != nil {
+		return err
+	}
+	if _, err := r.Seek(int64(p.MoovOffset+p.replace), io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, r)
+	return err
+}
+
+// applyItems adds or replaces keys and ilst entries of moov/meta with mdta handler
+func (p *Plan) applyItems(moov *box.Node, items map[string]string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	metaNode := findMdtaMeta(moov)
+	if metaNode == nil {
+		// QuickTime meta atom has no version and flags
+		metaNode = &box.Node{
+			Type:     box.MetaBox,
+			Children: []*box.Node{box.NewFullNode(box.HandlerReferenceBox, mdtaHandlerPayload())},
+		}
+		moov.Children = append(moov.Children, metaNode)
+	}
+	keysNode := metaNode.FindChild(box.MetadataItemKeysAtom)
+	if keysNode == nil {
+		keysNode = box.NewFullNode(box.MetadataItemKeysAtom, []byte{0, 0, 0, 0})
+		metaNode.Children = append(metaNode.Children, keysNode)
+	}
+	ilstNode := metaNode.FindChild(box.MetadataItemListAtom)
+	if ilstNode == nil {
+		ilstNode = &box.Node{Type: box.MetadataItemListAtom, Children: []*box.Node{}}
+		metaNode.Children = append(metaNode.Children, ilstNode)
+	}
+	if err := ilstNode.ParseChildren(); err != nil {
+		return err
+	}
+	keys, err := parseKeys(keysNode.Payload)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(items) {
+		value := items[key]
+		change := &Change{Path: "moov/meta/ilst", Key: key, New: value}
+		index := indexOf(keys, key)
+		if index < 0 {
+			keys = append(keys, key)
+			index = len(keys) - 1
+		}
+		itemType := box.BoxType(index + 1)
+		item := ilstNode.FindChild(itemType)
+		if item == nil {
+			item = box.NewNode(itemType, nil)
+			ilstNode.Children = append(ilstNode.Children, item)
+		} else if old, ok := decodeItem(item.Payload); ok {
+			change.Old = &old
+		}
+		item.Payload = dataAtom(value)
+		p.Changes = append(p.Changes, change)
+	}
+	keysNode.Payload = encodeKeys(keys)
+	return nil
+}
+
+// applyUserData adds or replaces text atoms of moov/udta
+func (p *Plan) applyUserData(moov *box.Node, userData map[box.BoxType]string) error {
+	if len(userData) == 0 {
+		return nil
+	}
+	udta := moov.FindChild(box.UserDataBox)
+	if udta == nil {
+		udta = &box.Node{Type: box.UserDataBox, Children: []*box.Node{}}
+		moov.Children = append(moov.Children, udta)
+	}
+	if udta.Children == nil {
+		if err := udta.ParseChildren(); err != nil {
+			return err
+		}
+	}
+	types := make([]box.BoxType, 0, len(userData))
+	for boxType := range userData {
+		types = append(types, boxType)
+	}
+	sortBoxTypes(types)
+	for _, boxType := range types {
+		value := userData[boxType]
+		change := &Change{Path: "moov/udta", Key: boxType.String(), New: value}
+		atom := udta.FindChild(boxType)
+		if atom == nil {
+			atom = box.NewNode(boxType, nil)
+			udta.Children = append(udta.Children, atom)
+		} else if old, ok := decodeText(atom.Payload); ok {
+			change.Old = &old
+		}
+		atom.Payload = textAtom(value)
+		p.Changes = append(p.Changes, change)
+	}
+	return nil
+}
+
+func findMdtaMeta(moov *box.Node) *box.Node {
+	for _, child := range moov.Children {
+		if child.Type != box.MetaBox {
+			continue
+		}
+		if hdlr := child.FindChild(box.HandlerReferenceBox); hdlr != nil &&
+			len(hdlr.Payload) >= 8 && string(hdlr.Payload[4:8]) == keyNamespaceMdta {
+			return child
+		}
+	}
+	return nil
+}
+
+// mdtaHandlerPayload pre_defined(4) handler_type(4) reserved(12) name(1)
+func mdtaHandlerPayload() []byte {
+	payload := make([]byte, 21)
+	copy(payload[4:8], keyNamespaceMdta)
+	return payload
+}
+
+func parseKeys(payload []byte) ([]string, error) {
+	if len(payload) < 4 {
+		return nil, errors.New("invalid keys box")
+	}
+	count := int(binary.BigEndian.Uint32(payload))
+	keys := make([]string, 0, count+8)
+	for i, offset := 0, 4; i < count; i++ {
+		if offset+8 > len(payload) {
+			return nil, errors.New("invalid keys box")
+		}
+		size := int(binary.BigEndian.Uint32(payload[offset:]))
+		if size < 8 || offset+size > len(payload) {
+			return nil, errors.New("invalid keys box")
+		}
+		keys = append(keys, string(payload[offset+8:offset+size]))
+		offset += size
+	}
+	return keys, nil
+}
+
+// encodeKeys entry_count(4) [key_size(4) key_namespace(4) key_value]...
+func encodeKeys(keys []string) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, 64*len(keys)))
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(keys)))
+	for _, key := range keys {
+		_ = binary.Write(buf, binary.BigEndian, uint32(8+len(key)))
+		buf.WriteString(keyNamespaceMdta)
+		buf.WriteString(key)
+	}
+	return buf.Bytes()
+}
+
+// dataAtom data atom of utf-8 string: size(4) 'data' type(4) locale(4) value
+func dataAtom(value string) []byte {
+	b := make([]byte, 16+len(value))
+	binary.BigEndian.PutUint32(b, uint32(len(b)))
+	copy(b[4:8], "data")
+	binary.BigEndian.PutUint32(b[8:12], box.DataTypeStringUTF8)
+	copy(b[16:], value)
+	return b
+}
+
+func decodeItem(payload []byte) (string, bool) {
+	if len(payload) < 16 || string(payload[4:8]) != "data" {
+		return "", false
+	}
+	size := int(binary.BigEndian.Uint32(payload))
+	if size < 16 || size > len(payload) {
+		return "", false
+	}
+	value, err := box.DecodeWellKnownType(binary.BigEndian.Uint32(payload[8:12]), payload[16:size])
+	if err != nil {
+		return fmt.Sprintf("%X", payload[16:size]), true
+	}
+	return fmt.Sprintf("%v", value), true
+}
+
+// textAtom QuickTime user data text: length(2) language(2) text
+func textAtom(value string) []byte {
+	b := make([]byte, 4+len(value))
+	binary.BigEndian.PutUint16(b, uint16(len(value)))
+	binary.BigEndian.PutUint16(b[2:], languageUndetermined)
+	copy(b[4:], value)
+	return b
+}
+
+func decodeText(payload []byte) (string, bool) {
+	if len(payload) < 4 {
+		return "", false
+	}
+	length := int(binary.BigEndian.Uint16(payload))
+	if 4+length > len(payload) {
+		return "", false
+	}
+	return string(payload[4 : 4+length]), true
+}
+
+func sortedKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortBoxTypes(types []box.BoxType) {
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+}
+
+func indexOf(keys []string, key string) int {
+	for i, k := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseUserDataType converts type like ©cmt or (c)cmt to box type
+func ParseUserDataType(s string) (box.BoxType, error) {
+	s = strings.ReplaceAll(s, "(c)", "©")
+	b := make([]byte, 0, 4)
+	for _, r := range s {
+		if r > 0xff {
+			return 0, fmt.Errorf("invalid user data type: %s", s)
+		}
+		b = append(b, byte(r))
+	}
+	if len(b) != 4 {
+		return 0, fmt.Errorf("invalid user data type: %s", s)
+	}
+	return box.BoxType(binary.BigEndian.Uint32(b)), nil
+}
@@ -0,0 +1,208 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/fukco/media-metadata/internal/box"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/meta"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testBox(boxType string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(data)))
+	return append(append(b, boxType...), data...)
+}
+
+func testFullBox(boxType string, payload ...[]byte) []byte {
+	return testBox(boxType, append([][]byte{{0, 0, 0, 0}}, payload...)...)
+}
+
+// testChunks media data of the test file, each chunk is 4 distinct bytes
+var testChunks = [][]byte{[]byte("AAAA"), []byte("BBBB"), []byte("CCCC")}
+
+// testFile builds ftyp, moov, an optional free box and mdat, chunk offsets of stco or co64 point to testChunks.
+// With value the moov has an mdta item of the key
+func testFile(t *testing.T, large bool, freeSize int, key, value string) []byte {
+	t.Helper()
+	ftyp := testBox("ftyp", []byte("qt  \x00\x00\x02\x00qt  "))
+	var metaBox []byte
+	if value != "" {
+		metaBox = testBox("meta",
+			testFullBox("hdlr", make([]byte, 4), []byte("mdta"), make([]byte, 13)),
+			testFullBox("keys", []byte{0, 0, 0, 1}, testBox("mdta", []byte(key))),
+			testBox("ilst", testBox("\x00\x00\x00\x01", testBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(value)))))
+	}
+	moov := func(offsets []uint64) []byte {
+		table := binary.BigEndian.AppendUint32(nil, uint32(len(offsets)))
+		for _, offset := range offsets {
+			if large {
+				table = binary.BigEndian.AppendUint64(table, offset)
+			} else {
+				table = binary.BigEndian.AppendUint32(table, uint32(offset))
+			}
+		}
+		chunkOffset := testFullBox("stco", table)
+		if large {
+			chunkOffset = testFullBox("co64", table)
+		}
+		stbl := testBox("stbl",
+			testFullBox("stsc", []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1}),
+			testFullBox("stsz", []byte{0, 0, 0, 4, 0, 0, 0, 3}),
+			chunkOffset)
+		trak := testBox("trak", testBox("mdia",
+			testFullBox("mdhd", make([]byte, 20)),
+			testFullBox("hdlr", make([]byte, 4), []byte("vide"), make([]byte, 13)),
+			testBox("minf", stbl)))
+		return testBox("moov", testFullBox("mvhd", make([]byte, 96)), trak, metaBox)
+	}
+	var free []byte
+	if freeSize > 0 {
+		free = testBox("free", make([]byte, freeSize-8))
+	}
+	// offsets depend on the size of moov only, which does not change with their values
+	start := uint64(len(ftyp) + len(moov(make([]uint64, len(testChunks)))) + len(free) + 8)
+	offsets := make([]uint64, len(testChunks))
+	for i := range offsets {
+		offsets[i] = start + uint64(4*i)
+	}
+	return bytes.Join([][]byte{ftyp, moov(offsets), free, testBox("mdat", bytes.Join(testChunks, nil))}, nil)
+}
+
+// chunkOffsets offsets of stco or co64 of the file
+func chunkOffsets(t *testing.T, data []byte) []uint64 {
+	t.Helper()
+	r := bytes.NewReader(data)
+	infos, err := readTopLevelBoxInfos(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offsets []uint64
+	for _, info := range infos {
+		if info.Type != box.MovieBox {
+			continue
+		}
+		if _, err := info.SeekToStart(r); err != nil {
+			t.Fatal(err)
+		}
+		moov, err := box.ReadNode(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = moov.Walk(func(node *box.Node) error {
+			switch node.Type {
+			case box.ChunkOffsetBox:
+				for i := 0; i < int(binary.BigEndian.Uint32(node.Payload)); i++ {
+					offsets = append(offsets, uint64(binary.BigEndian.Uint32(node.Payload[4+4*i:])))
+				}
+			case box.ChunkLargeOffsetBox:
+				for i := 0; i < int(binary.BigEndian.Uint32(node.Payload)); i++ {
+					offsets = append(offsets, binary.BigEndian.Uint64(node.Payload[4+8*i:]))
+				}
+			}
+			return nil
+		})
+	}
+	return offsets
+}
+
+func TestApply(t *testing.T) {
+	key := common.MetadataKeyPrefix + "ReelNumber"
+	tests := []struct {
+		name     string
+		large    bool
+		freeSize int
+		// value already written, the new value of the same length keeps the size of moov
+		oldValue string
+		value    string
+		strategy Strategy
+		shifted  int
+	}{
+		{name: "in-place", oldValue: "A001", value: "B002", strategy: InPlace},
+		{name: "free padding", freeSize: 256, value: "A001", strategy: InPlace},
+		{name: "free padding co64", large: true, freeSize: 256, value: "A001", strategy: InPlace},
+		{name: "rewrite", value: "A001", strategy: Rewrite, shifted: 3},
+		{name: "rewrite co64", large: true, value: "A001", strategy: Rewrite, shifted: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "clip.mov")
+			if err := os.WriteFile(path, testFile(t, tt.large, tt.freeSize, key, tt.oldValue), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			plan, err := Apply(path, &Edits{Items: map[string]string{key: tt.value}}, "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Strategy != tt.strategy || plan.ShiftedChunkOffsets != tt.shifted {
+				t.Fatalf("got %s with %d offsets shifted, want %s with %d", plan.Strategy, plan.ShiftedChunkOffsets, tt.strategy, tt.shifted)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			offsets := chunkOffsets(t, data)
+			if len(offsets) != len(testChunks) {
+				t.Fatalf("got %d chunk offsets", len(offsets))
+			}
+			for i, offset := range offsets {
+				if offset+4 > uint64(len(data)) || !bytes.Equal(data[offset:offset+4], testChunks[i]) {
+					t.Errorf("chunk %d at %d does not point to %s", i, offset, testChunks[i])
+				}
+			}
+			m, err := meta.Read(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.MetaItemKeyValues[key]; got != tt.value {
+				t.Errorf("%s: got %v, want %s", key, got, tt.value)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil || len(entries) != 1 {
+				t.Errorf("temporary files left: %v", entries)
+			}
+		})
+	}
+}
+
+func TestShiftChunkOffsetsErrors(t *testing.T) {
+	node := func(boxType box.BoxType, payload ...[]byte) *box.Node {
+		return &box.Node{Type: box.SampleTableBox, Children: []*box.Node{
+			box.NewFullNode(box.ChunkOffsetBox, []byte{0, 0, 0, 1, 0, 0, 0, 16}),
+			box.NewFullNode(boxType, bytes.Join(payload, nil)),
+		}}
+	}
+	co64 := func(offset uint64) []byte {
+		return binary.BigEndian.AppendUint64([]byte{0, 0, 0, 1}, offset)
+	}
+	tests := []struct {
+		name  string
+		stbl  *box.Node
+		delta int64
+		err   error
+	}{
+		{name: "stco beyond 32 bits", stbl: node(box.ChunkOffsetBox, []byte{0, 0, 0, 1, 0xff, 0xff, 0xff, 0xf0}), delta: 32, err: box.ErrChunkOffsetOverflow},
+		{name: "stco negative", stbl: node(box.ChunkOffsetBox, []byte{0, 0, 0, 1, 0, 0, 0, 32}), delta: -64, err: box.ErrChunkOffsetOverflow},
+		{name: "co64 negative", stbl: node(box.ChunkLargeOffsetBox, co64(32)), delta: -64, err: box.ErrChunkOffsetOverflow},
+		{name: "co64 overflow", stbl: node(box.ChunkLargeOffsetBox, co64(1<<63-16)), delta: 32, err: box.ErrChunkOffsetOverflow},
+		{name: "stco truncated", stbl: node(box.ChunkOffsetBox, []byte{0, 0, 0, 2, 0, 0, 0, 32}), delta: 8, err: box.ErrChunkOffsetTruncated},
+		{name: "co64 truncated", stbl: node(box.ChunkLargeOffsetBox, []byte{0, 0, 0, 1, 0, 0, 0, 32}), delta: 8, err: box.ErrChunkOffsetTruncated},
+		{name: "co64 without count", stbl: node(box.ChunkLargeOffsetBox, []byte{0, 0}), delta: 8, err: box.ErrChunkOffsetTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.stbl.Bytes()
+			if _, err := tt.stbl.ShiftChunkOffsets(0, tt.delta); !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if !bytes.Equal(tt.stbl.Bytes(), before) {
+				t.Error("offsets changed on error")
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsRDF   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsXMLNS = "xmlns"
+)
+
+var ErrNoRDF = errors.New("rdf:RDF not found in xmp packet")
+
+// well-known namespaces, prefixes are fixed regardless of the declaration of packet
+var knownPrefixes = map[string]string{
+	"http://ns.adobe.com/xap/1.0/":                 "xmp",
+	"http://ns.adobe.com/xap/1.0/mm/":              "xmpMM",
+	"http://ns.adobe.com/xmp/1.0/DynamicMedia/":    "xmpDM",
+	"http://ns.adobe.com/exif/1.0/":                "exif",
+	"http://ns.adobe.com/exif/1.0/aux/":            "aux",
+	"http://cipa.jp/exif/1.0/":                     "exifEX",
+	"http://ns.adobe.com/tiff/1.0/":                "tiff",
+	"http://ns.adobe.com/camera-raw-settings/1.0/": "crs",
+	"http://purl.org/dc/elements/1.1/":             "dc",
+	"http://ns.adobe.com/photoshop/1.0/":           "photoshop",
+}
+
+// XMP properties of packets, keyed by prefix:name, fields of structure are joined by "/"
+type XMP struct {
+	Properties map[string]string
+}
+
+type node struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []*node    `xml:",any"`
+}
+
+func (n *node) attr(space, local string) (string, bool) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+type parser struct {
+	prefixes   map[string]string
+	properties map[string]string
+}
+
+// Parse parses XMP packet, the xpacket wrapper is optional
+func Parse(packet []byte) (*XMP, error) {
+	packet = bytes.TrimRight(packet, "\x00 \r\n\t")
+	root := &node{}
+	if err := xml.Unmarshal(packet, root); err != nil {
+		return nil, err
+	}
+	rdf := findRDF(root)
+	if rdf == nil {
+		return nil, ErrNoRDF
+	}
+	p := &parser{
+		prefixes:   make(map[string]string, 16),
+		properties: make(map[string]string, 32),
+	}
+	p.collectPrefixes(root)
+	for _, description := range rdf.Nodes {
+		if description.XMLName.Space == nsRDF && description.XMLName.Local == "Description" {
+			p.parseResource(description, "")
+		}
+	}
+	return &XMP{Properties: p.properties}, nil
+}
+
+// Merge adds properties of other packet which are not present
+func (x *XMP) Merge(other *XMP) {
+	if other == nil {
+		return
+	}
+	for key, value := range other.Properties {
+		if _, ok := x.Properties[key]; !ok {
+			x.Properties[key] = value
+		}
+	}
+}
+
+// Get returns property value, e.g. Get("xmpDM:scene")
+func (x *XMP) Get(name string) string {
+	if x == nil {
+		return ""
+	}
+	return x.Properties[name]
+}
+
+// Namespace returns properties with the prefix, e.g. Namespace("crs")
+func (x *XMP) Namespace(prefix string) map[string]string {
+	result := make(map[string]string, 8)
+	for key, value := range x.Properties {
+		if strings.HasPrefix(key, prefix+":") {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Keys returns sorted property names
+func (x *XMP) Keys() []string {
+	keys := make([]string, 0, len(x.Properties))
+	for key := range x.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func findRDF(n *node) *node {
+	if n.XMLName.Space == nsRDF && n.XMLName.Local == "RDF" {
+		return n
+	}
+	for _, child := range n.Nodes {
+		if result := findRDF(child); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+func (p *parser) collectPrefixes(n *node) {
+	for _, attr := range n.Attrs {
+		if attr.Name.Space == nsXMLNS {
+			if _, ok := p.prefixes[attr.Value]; !ok {
+				p.prefixes[attr.Value] = attr.Name.Local
+			}
+		}
+	}
+	for _, child := range n.Nodes {
+		p.collectPrefixes(child)
+	}
+}
+
+func (p *parser) qualifiedName(name xml.Name) string {
+	if prefix, ok := knownPrefixes[name.Space]; ok {
+		return prefix + ":" + name.Local
+	}
+	if prefix, ok := p.prefixes[name.Space]; ok {
+		return prefix + ":" + name.Local
+	}
+	return name.Local
+}
+
+func isPropertyAttr(attr xml.Attr) bool {
+	return attr.Name.Space != nsXMLNS && attr.Name.Space != nsRDF && attr.Name.Space != "" &&
+		attr.Name.Space != "http://www.w3.org/XML/1998/namespace"
+}
+
+// parseResource handles rdf:Description or struct node, properties are written with path prefix
+func (p *parser) parseResource(n *node, path string) {
+	for _, attr := range n.Attrs {
+		if isPropertyAttr(attr) {
+			p.properties[path+p.qualifiedName(attr.Name)] = attr.Value
+		}
+	}
+	for _, child := range n.Nodes {
+		p.parseProperty(child, path)
+	}
+}
+
+func (p *parser) parseProperty(n *node, path string) {
+	name := path + p.qualifiedName(n.XMLName)
+	if parseType, _ := n.attr(nsRDF, "parseType"); parseType == "Resource" {
+		p.parseResource(n, name+"/")
+		return
+	}
+	if resource, ok := n.attr(nsRDF, "resource"); ok {
+		p.properties[name] = resource
+		return
+	}
+	for _, attr := range n.Attrs {
+		if isPropertyAttr(attr) {
+			p.parseResource(n, name+"/")
+			return
+		}
+	}
+	if len(n.Nodes) == 0 {
+		p.properties[name] = strings.TrimSpace(n.Content)
+		return
+	}
+	child := n.Nodes[0]
+	if child.XMLName.Space != nsRDF {
+		p.parseResource(n, name+"/")
+		return
+	}
+	switch child.XMLName.Local {
+	case "Description":
+		p.parseResource(child, name+"/")
+	case "Alt":
+		// x-default or the first alternative
+		for i, li := range child.Nodes {
+			if lang, _ := li.attr("http://www.w3.org/XML/1998/namespace", "lang"); i == 0 || lang == "x-default" {
+				p.properties[name] = strings.TrimSpace(li.Content)
+			}
+		}
+	case "Seq", "Bag":
+		values := make([]string, 0, len(child.Nodes))
+		for i, li := range child.Nodes {
+			if len(li.Nodes) > 0 || hasPropertyAttr(li) {
+				p.parseResource(li, name+"["+strconv.Itoa(i+1)+"]/")
+				continue
+			}
+			values = append(values, strings.TrimSpace(li.Content))
+		}
+		if len(values) > 0 {
+			p.properties[name] = strings.Join(values, ", ")
+		}
+	}
+}
+
+func hasPropertyAttr(n *node) bool {
+	for _, attr := range n.Attrs {
+		if isPropertyAttr(attr) {
+			return true
+		}
+	}
+	return false
+}
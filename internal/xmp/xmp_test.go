@@ -0,0 +1,85 @@
+package xmp
+
+import (
+	"errors"
+	"testing"
+)
+
+const testPacket = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmpDM="http://ns.adobe.com/xmp/1.0/DynamicMedia/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:e="http://ns.adobe.com/exif/1.0/"
+    xmlns:cam="http://example.com/camera/1.0/"
+    xmpDM:scene="12A"
+    e:ISOSpeedRatings="800">
+   <xmpDM:shotName>Take 3</xmpDM:shotName>
+   <dc:title>
+    <rdf:Alt>
+     <rdf:li xml:lang="en-US">English</rdf:li>
+     <rdf:li xml:lang="x-default">Default</rdf:li>
+    </rdf:Alt>
+   </dc:title>
+   <dc:creator>
+    <rdf:Seq>
+     <rdf:li>A</rdf:li>
+     <rdf:li>B</rdf:li>
+    </rdf:Seq>
+   </dc:creator>
+   <xmpDM:startTimecode rdf:parseType="Resource">
+    <xmpDM:timeFormat>24Timecode</xmpDM:timeFormat>
+    <xmpDM:timeValue>01:00:00:00</xmpDM:timeValue>
+   </xmpDM:startTimecode>
+   <cam:lens cam:model="50mm"/>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>` + "\x00\x00 \n"
+
+func TestParse(t *testing.T) {
+	x, err := Parse([]byte(testPacket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"xmpDM:scene":                          "12A",
+		"exif:ISOSpeedRatings":                 "800",
+		"xmpDM:shotName":                       "Take 3",
+		"dc:title":                             "Default",
+		"dc:creator":                           "A, B",
+		"xmpDM:startTimecode/xmpDM:timeFormat": "24Timecode",
+		"xmpDM:startTimecode/xmpDM:timeValue":  "01:00:00:00",
+		"cam:lens/cam:model":                   "50mm",
+	}
+	for name, value := range want {
+		if got := x.Get(name); got != value {
+			t.Errorf("%s: got %q, want %q", name, got, value)
+		}
+	}
+	if len(x.Properties) != len(want) {
+		t.Errorf("got properties %v", x.Keys())
+	}
+	if got := len(x.Namespace("xmpDM")); got != 4 {
+		t.Errorf("xmpDM properties: got %d, want 4", got)
+	}
+}
+
+func TestParseWithoutRDF(t *testing.T) {
+	if _, err := Parse([]byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"/>`)); !errors.Is(err, ErrNoRDF) {
+		t.Fatalf("got %v, want ErrNoRDF", err)
+	}
+	if _, err := Parse([]byte("not xml")); err == nil {
+		t.Fatal("invalid packet parsed")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	x := &XMP{Properties: map[string]string{"xmpDM:scene": "first"}}
+	x.Merge(&XMP{Properties: map[string]string{"xmpDM:scene": "second", "xmpDM:shotName": "1"}})
+	x.Merge(nil)
+	if x.Get("xmpDM:scene") != "first" || x.Get("xmpDM:shotName") != "1" {
+		t.Fatalf("got %v", x.Properties)
+	}
+}
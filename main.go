@@ -43,6 +43,18 @@ struct DRMetadata
 	char *ColorSpaceNotes;
 };
 
+// DRMetadataV2 adds fields after DRMetadata, DRMetadata is returned by value and must keep its layout
+struct DRMetadataV2
+{
+	struct DRMetadata Base;
+	char *ReelNumber;
+	char *Scene;
+	char *Shot;
+	char *Take;
+	char *GoodTake;
+	char *Comments;
+};
+
 struct DRSonyNrtmd
 {
 	bool IsSupportMedia;
@@ -95,37 +107,110 @@ import (
 	"flag"
 	"fmt"
 	"github.com/fukco/media-metadata/internal"
+	"github.com/fukco/media-metadata/internal/box"
+	"github.com/fukco/media-metadata/internal/common"
 	"github.com/fukco/media-metadata/internal/manufacturer"
 	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"github.com/fukco/media-metadata/internal/output/dump"
+	"github.com/fukco/media-metadata/internal/output/location"
+	"github.com/fukco/media-metadata/internal/output/report"
 	"github.com/fukco/media-metadata/internal/output/resolve"
 	"github.com/fukco/media-metadata/internal/output/resolve/xavc"
+	"github.com/fukco/media-metadata/internal/rangeio"
+	"github.com/fukco/media-metadata/internal/writer"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
-func consoleOutput(meta *meta.Metadata) {
-	if s, err := json.Marshal(meta); err != nil {
+func consoleOutput(v any) {
+	if s, err := json.Marshal(v); err != nil {
 		fmt.Println(err)
 	} else {
 		fmt.Println(string(s))
 	}
 }
 
-func drProcessMediaFile(absPath string) *resolve.DRMetadata {
+// resolveOutput DaVinci Resolve fields of a file, Provenance is only set with -provenance
+type resolveOutput struct {
+	FilePath string
+	*resolve.DRMetadata
+	Provenance map[string]*resolve.Provenance `json:",omitempty"`
+}
+
+func output(m *meta.Metadata, resolveFields, provenance bool, profile *resolve.Profile) {
+	if resolveFields {
+		out := resolveOutput{FilePath: m.FilePath, DRMetadata: resolve.GetDRMetadataFromMeta(m, profile)}
+		if provenance {
+			out.Provenance = out.DRMetadata.Provenance
+		}
+		consoleOutput(out)
+	} else {
+		consoleOutput(m)
+	}
+}
+
+// structureCache file structures shared by DLL calls of the same clip, RTMD slices are requested many times per clip
+var structureCache = box.NewStructureCache(box.DefaultCacheSize)
+
+func readCached(f *os.File) (*meta.Metadata, error) {
+	opts := meta.Options{}
+	fileStructure, err := structureCache.Read(f, opts.StructureOptions())
+	if err != nil {
+		return nil, err
+	}
+	return meta.ReadWithStructure(f, fileStructure, opts)
+}
+
+func drProcessMediaFile(absPath string, profile *resolve.Profile) (drMetadata *resolve.DRMetadata) {
+	// never crash the host application on malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			drMetadata = nil
+		}
+	}()
 	f, err := internal.GetMediaFile(absPath)
 	defer f.Close()
 	if err != nil {
 		return nil
 	}
-	m, err := meta.Read(f)
+	m, err := readCached(f)
 	if err != nil || m == nil {
 		return nil
 	}
-	return resolve.GetDRMetadataFromMeta(m)
+	// a broken sidecar does not fail the clip
+	_ = m.ReadSidecars(absPath)
+	return resolve.GetDRMetadataFromMeta(m, profile)
 }
 
 //export DRProcessMediaFile
 func DRProcessMediaFile(absPath *C.char) C.struct_DRMetadata {
-	drMetadata := drProcessMediaFile(C.GoString(absPath))
+	return toCDRMetadata(drProcessMediaFile(C.GoString(absPath), nil))
+}
+
+// DRProcessMediaFileV2 is DRProcessMediaFile with the fields of DRMetadataV2
+//
+//export DRProcessMediaFileV2
+func DRProcessMediaFileV2(absPath *C.char) C.struct_DRMetadataV2 {
+	return toCDRMetadataV2(drProcessMediaFile(C.GoString(absPath), nil))
+}
+
+// DRProcessMediaFileWithProfile maps fields with built-in profile name or profile file path,
+// media is reported as not supported if profile is not found
+//
+//export DRProcessMediaFileWithProfile
+func DRProcessMediaFileWithProfile(absPath *C.char, profileName *C.char) C.struct_DRMetadataV2 {
+	profile, err := resolve.FindProfile(C.GoString(profileName))
+	if err != nil {
+		return toCDRMetadataV2(nil)
+	}
+	return toCDRMetadataV2(drProcessMediaFile(C.GoString(absPath), profile))
+}
+
+func toCDRMetadata(drMetadata *resolve.DRMetadata) C.struct_DRMetadata {
 	var result C.struct_DRMetadata
 	if drMetadata == nil {
 		result.IsSupportMedia = C._Bool(false)
@@ -169,7 +254,26 @@ func DRProcessMediaFile(absPath *C.char) C.struct_DRMetadata {
 	return result
 }
 
-func drSonyNrtmdDisp(absPath string) *xavc.NrtmdDisp {
+func toCDRMetadataV2(drMetadata *resolve.DRMetadata) C.struct_DRMetadataV2 {
+	var result C.struct_DRMetadataV2
+	result.Base = toCDRMetadata(drMetadata)
+	if drMetadata != nil {
+		result.ReelNumber = C.CString(drMetadata.ReelNumber)
+		result.Scene = C.CString(drMetadata.Scene)
+		result.Shot = C.CString(drMetadata.Shot)
+		result.Take = C.CString(drMetadata.Take)
+		result.GoodTake = C.CString(drMetadata.GoodTake)
+		result.Comments = C.CString(drMetadata.Comments)
+	}
+	return result
+}
+
+func drSonyNrtmdDisp(absPath string) (nrtmdDisp *xavc.NrtmdDisp) {
+	defer func() {
+		if r := recover(); r != nil {
+			nrtmdDisp = nil
+		}
+	}()
 	f, err := internal.GetMediaFile(absPath)
 	defer f.Close()
 
@@ -177,7 +281,7 @@ func drSonyNrtmdDisp(absPath string) *xavc.NrtmdDisp {
 		fmt.Println(err)
 		return nil
 	}
-	m, err := meta.Read(f)
+	m, err := readCached(f)
 	if err != nil || m == nil || m.Manufacturer != manufacturer.SONY {
 		return nil
 	}
@@ -208,7 +312,12 @@ func DRSonyNrtmdDisp(absPath *C.char) C.struct_DRSonyNrtmd {
 	return result
 }
 
-func drSonyRtmdDisp(absPath string, start int, count int) *xavc.RtmdCollection {
+func drSonyRtmdDisp(absPath string, start int, count int) (rtmdCollection *xavc.RtmdCollection) {
+	defer func() {
+		if r := recover(); r != nil {
+			rtmdCollection = nil
+		}
+	}()
 	f, err := os.Open(absPath)
 	defer func() {
 		if err := f.Close(); err != nil {
@@ -218,7 +327,11 @@ func drSonyRtmdDisp(absPath string, start int, count int) *xavc.RtmdCollection {
 	if err != nil {
 		return nil
 	} else {
-		if rtmdCollection, err := xavc.ReadRtmdSlice(f, start, count); err != nil {
+		fileStructure, err := structureCache.Read(f, meta.Options{}.StructureOptions())
+		if err != nil {
+			return nil
+		}
+		if rtmdCollection, err := xavc.ReadRtmdSliceWithStructure(f, fileStructure, start, count); err != nil {
 			return nil
 		} else {
 			return rtmdCollection
@@ -230,85 +343,377 @@ func drSonyRtmdDisp(absPath string, start int, count int) *xavc.RtmdCollection {
 func DrSonyRtmdDisp(absPath *C.char, start C.int, count C.int) C.struct_DRSonyRtmdDisp {
 	rtmdCollection := drSonyRtmdDisp(C.GoString(absPath), int(start), int(count))
 	var result C.struct_DRSonyRtmdDisp
-	for i := 0; i < len(rtmdCollection.WhiteBalanceSlice); i++ {
-		result.WhiteBalanceModeArray.array[i].Frame = C.int(rtmdCollection.WhiteBalanceSlice[i].Frame)
-		result.WhiteBalanceModeArray.array[i].Data = C.CString(rtmdCollection.WhiteBalanceSlice[i].Data)
-	}
-	result.WhiteBalanceModeArray.len = C.int(len(rtmdCollection.WhiteBalanceSlice))
-	for i := 0; i < len(rtmdCollection.ExposureModeSlice); i++ {
-		result.ExposureModeArray.array[i].Frame = C.int(rtmdCollection.ExposureModeSlice[i].Frame)
-		result.ExposureModeArray.array[i].Data = C.CString(rtmdCollection.ExposureModeSlice[i].Data)
-	}
-	result.ExposureModeArray.len = C.int(len(rtmdCollection.ExposureModeSlice))
-	for i := 0; i < len(rtmdCollection.AutoFocusSensingAreaSlice); i++ {
-		result.AutoFocusSensingAreaArray.array[i].Frame = C.int(rtmdCollection.AutoFocusSensingAreaSlice[i].Frame)
-		result.AutoFocusSensingAreaArray.array[i].Data = C.CString(rtmdCollection.AutoFocusSensingAreaSlice[i].Data)
-	}
-	result.AutoFocusSensingAreaArray.len = C.int(len(rtmdCollection.AutoFocusSensingAreaSlice))
-	for i := 0; i < len(rtmdCollection.ShutterSpeedSlice); i++ {
-		result.ShutterSpeedArray.array[i].Frame = C.int(rtmdCollection.ShutterSpeedSlice[i].Frame)
-		result.ShutterSpeedArray.array[i].Data = C.CString(rtmdCollection.ShutterSpeedSlice[i].Data)
-	}
-	result.ShutterSpeedArray.len = C.int(len(rtmdCollection.ShutterSpeedSlice))
-	for i := 0; i < len(rtmdCollection.ApertureSlice); i++ {
-		result.ApertureArray.array[i].Frame = C.int(rtmdCollection.ApertureSlice[i].Frame)
-		result.ApertureArray.array[i].Data = C.CString(rtmdCollection.ApertureSlice[i].Data)
-	}
-	result.ApertureArray.len = C.int(len(rtmdCollection.ApertureSlice))
-	for i := 0; i < len(rtmdCollection.ISOSlice); i++ {
-		result.ISOArray.array[i].Frame = C.int(rtmdCollection.ISOSlice[i].Frame)
-		result.ISOArray.array[i].Data = C.CString(rtmdCollection.ISOSlice[i].Data)
-	}
-	result.ISOArray.len = C.int(len(rtmdCollection.ISOSlice))
-	for i := 0; i < len(rtmdCollection.FocalLengthSlice); i++ {
-		result.FocalLengthArray.array[i].Frame = C.int(rtmdCollection.FocalLengthSlice[i].Frame)
-		result.FocalLengthArray.array[i].Data = C.CString(rtmdCollection.FocalLengthSlice[i].Data)
-	}
-	result.FocalLengthArray.len = C.int(len(rtmdCollection.FocalLengthSlice))
-	for i := 0; i < len(rtmdCollection.FocalLength35mmSlice); i++ {
-		result.FocalLength35mmArray.array[i].Frame = C.int(rtmdCollection.FocalLength35mmSlice[i].Frame)
-		result.FocalLength35mmArray.array[i].Data = C.CString(rtmdCollection.FocalLength35mmSlice[i].Data)
-	}
-	result.FocalLength35mmArray.len = C.int(len(rtmdCollection.FocalLength35mmSlice))
-	for i := 0; i < len(rtmdCollection.FocusPositionSlice); i++ {
-		result.FocusPositionArray.array[i].Frame = C.int(rtmdCollection.FocusPositionSlice[i].Frame)
-		result.FocusPositionArray.array[i].Data = C.CString(rtmdCollection.FocusPositionSlice[i].Data)
-	}
-	result.FocusPositionArray.len = C.int(len(rtmdCollection.FocusPositionSlice))
-	for i := 0; i < len(rtmdCollection.CaptureGammaEquationSlice); i++ {
-		result.CaptureGammaEquationArray.array[i].Frame = C.int(rtmdCollection.CaptureGammaEquationSlice[i].Frame)
-		result.CaptureGammaEquationArray.array[i].Data = C.CString(rtmdCollection.CaptureGammaEquationSlice[i].Data)
-	}
-	result.CaptureGammaEquationArray.len = C.int(len(rtmdCollection.CaptureGammaEquationSlice))
-	for i := 0; i < len(rtmdCollection.CameraMasterGainAdjustmentSlice); i++ {
-		result.CameraMasterGainAdjustmentArray.array[i].Frame = C.int(rtmdCollection.CameraMasterGainAdjustmentSlice[i].Frame)
-		result.CameraMasterGainAdjustmentArray.array[i].Data = C.CString(rtmdCollection.CameraMasterGainAdjustmentSlice[i].Data)
-	}
-	result.CameraMasterGainAdjustmentArray.len = C.int(len(rtmdCollection.CameraMasterGainAdjustmentSlice))
+	if rtmdCollection == nil {
+		return result
+	}
+	fillFrameDataArray(&result.WhiteBalanceModeArray, rtmdCollection.WhiteBalanceSlice)
+	fillFrameDataArray(&result.ExposureModeArray, rtmdCollection.ExposureModeSlice)
+	fillFrameDataArray(&result.AutoFocusSensingAreaArray, rtmdCollection.AutoFocusSensingAreaSlice)
+	fillFrameDataArray(&result.ShutterSpeedArray, rtmdCollection.ShutterSpeedSlice)
+	fillFrameDataArray(&result.ApertureArray, rtmdCollection.ApertureSlice)
+	fillFrameDataArray(&result.ISOArray, rtmdCollection.ISOSlice)
+	fillFrameDataArray(&result.FocalLengthArray, rtmdCollection.FocalLengthSlice)
+	fillFrameDataArray(&result.FocalLength35mmArray, rtmdCollection.FocalLength35mmSlice)
+	fillFrameDataArray(&result.FocusPositionArray, rtmdCollection.FocusPositionSlice)
+	fillFrameDataArray(&result.CaptureGammaEquationArray, rtmdCollection.CaptureGammaEquationSlice)
+	fillFrameDataArray(&result.CameraMasterGainAdjustmentArray, rtmdCollection.CameraMasterGainAdjustmentSlice)
 	return result
 }
 
+// maxFrameData capacity of DRFrameDataArray
+const maxFrameData = 1000
+
+func fillFrameDataArray(array *C.DRFrameDataArray, slice []*xavc.FrameData) {
+	n := len(slice)
+	if n > maxFrameData {
+		n = maxFrameData
+	}
+	for i := 0; i < n; i++ {
+		array.array[i].Frame = C.int(slice[i].Frame)
+		array.array[i].Data = C.CString(slice[i].Data)
+	}
+	array.len = C.int(n)
+}
+
+func readMediaFile(path string, opts meta.Options, stats bool) (*meta.Metadata, error) {
+	f, err := internal.GetMediaFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	counter := rangeio.NewCounter(f)
+	m, err := meta.ReadWithOptions(counter, opts)
+	if stats {
+		fmt.Fprintf(os.Stderr, "%s: %d bytes read\n", path, counter.BytesRead())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ReadSidecars(path); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+	}
+	m.FileName = filepath.Base(path)
+	m.FilePath = path
+	return m, nil
+}
+
+// readRemoteFile reads metadata of file behind HTTP range or S3 compatible server
+func readRemoteFile(rawURL string, opts meta.Options, stats bool) (*meta.Metadata, error) {
+	remote, err := rangeio.NewHTTPReaderAt(nil, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	reader := rangeio.NewReader(remote, remote.Size())
+	m, err := meta.ReadAt(reader, reader.Size(), opts)
+	if stats {
+		readerStats := reader.Stats()
+		fmt.Fprintf(os.Stderr, "%s: %d of %d bytes read in %d requests\n", rawURL, readerStats.BytesRead, reader.Size(), readerStats.Requests)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		m.FileName = path.Base(u.Path)
+	}
+	m.FilePath = rawURL
+	return m, nil
+}
+
+func exportLocations(paths []string, format location.Format, outputPath string, opts meta.Options, stats bool) error {
+	placemarks := make([]*location.Placemark, 0, len(paths))
+	for _, path := range paths {
+		m, err := readMediaFile(path, opts, stats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			continue
+		}
+		if m.Location == nil {
+			fmt.Fprintf(os.Stderr, "%s: no location\n", path)
+			continue
+		}
+		placemarks = append(placemarks, &location.Placemark{
+			Name:     m.FileName,
+			FilePath: m.FilePath,
+			Location: m.Location,
+		})
+	}
+	w := os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return location.Write(w, format, placemarks)
+}
+
+// exportReport writes frame rates of clips, slow & quick and time-lapse clips are flagged to conform
+func exportReport(paths []string, outputPath string, opts meta.Options, stats bool) error {
+	clips := make([]*report.Clip, 0, len(paths))
+	for _, path := range paths {
+		m, err := readMediaFile(path, opts, stats)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			continue
+		}
+		clips = append(clips, report.NewClip(m.FilePath, normalize.Normalize(m)))
+	}
+	w := os.Stdout
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return report.WriteCSV(w, clips)
+}
+
+// printTimecodes prints timecode and camera clock of every frame and discontinuities of timecode
+func printTimecodes(paths []string) {
+	for _, path := range paths {
+		f, err := internal.GetMediaFile(path)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		track, err := xavc.ReadTimecodes(f)
+		f.Close()
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			continue
+		}
+		consoleOutput(struct {
+			FileName string
+			*xavc.TimecodeTrack
+		}{filepath.Base(path), track})
+	}
+}
+
+// setFlags repeatable -set flag
+type setFlags []string
+
+func (s *setFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *setFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseEdits converts -set values to edits, accepted forms:
+// ReelNumber=A001 DaVinci Resolve field, written as metadata item common.MetadataKeyPrefix + field name
+// com.example.key=value metadata item with full key
+// udta:(c)cmt=value QuickTime user data text atom
+func parseEdits(values []string) (*writer.Edits, error) {
+	edits := &writer.Edits{
+		Items:    make(map[string]string, len(values)),
+		UserData: make(map[box.BoxType]string, len(values)),
+	}
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid -set value: %s", v)
+		}
+		if userDataType, ok := strings.CutPrefix(key, "udta:"); ok {
+			boxType, err := writer.ParseUserDataType(userDataType)
+			if err != nil {
+				return nil, err
+			}
+			edits.UserData[boxType] = value
+		} else if strings.Contains(key, ".") {
+			edits.Items[key] = value
+		} else if resolve.IsField(key) {
+			edits.Items[common.MetadataKeyPrefix+key] = value
+		} else {
+			return nil, fmt.Errorf("unknown field: %s", key)
+		}
+	}
+	return edits, nil
+}
+
+func writeBack(paths []string, values []string, outputPath string, dryRun bool) error {
+	edits, err := parseEdits(values)
+	if err != nil {
+		return err
+	}
+	if outputPath != "" && len(paths) > 1 {
+		return fmt.Errorf("-o is only allowed for single file")
+	}
+	for _, path := range paths {
+		plan, err := writer.Apply(path, edits, outputPath, dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			continue
+		}
+		fmt.Printf("--- %s\n", path)
+		if err := plan.WriteDiff(os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dump [-json] [-hex stsd,uuid] [-hex-limit 256] /path/to/file
+func runDump(args []string) error {
+	flagSet := flag.NewFlagSet("dump", flag.ExitOnError)
+	jsonOutput := flagSet.Bool("json", false, "output in JSON")
+	hexTypes := flagSet.String("hex", "", "comma separated box types to dump payload in hex")
+	hexLimit := flagSet.Int("hex-limit", dump.DefaultHexLimit, "max bytes of each hex dump")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		return fmt.Errorf("usage: dump [-json] [-hex types] [-hex-limit n] file")
+	}
+	f, err := os.Open(flagSet.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	opts := &dump.Options{HexLimit: *hexLimit}
+	if *hexTypes != "" {
+		opts.HexTypes = strings.Split(*hexTypes, ",")
+	}
+	result, err := dump.Read(f, opts)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		return result.WriteJSON(os.Stdout)
+	}
+	return result.WriteText(os.Stdout)
+}
+
 // -file /path/to/file
+// -dir /path/to/folder
+// -location gpx|kml -o /path/to/output
+// -set ReelNumber=A001 -set Scene=12 [-dry-run] [-o /path/to/output]
+// -url https://host/path/to/file
+// -recover report incomplete files instead of failing
+// -stats print bytes read of each file
+// -raw output unknown RTMD tags and every sample of Apple timed metadata
+// -resolve output DaVinci Resolve fields
+// -provenance output the source of each Resolve field, implies -resolve
+// -profile resolve-18|/path/to/profile.yaml
+// -report -dir /path/to/card -o report.csv
+// -timecode per frame timecode of Sony clips
+// -p2 /path/to/card1,/path/to/card2
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		if err := runDump(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	filePath := flag.String("file", "", "media file full path")
+	fileURL := flag.String("url", "", "media file URL, server must support HTTP range requests")
+	dirPath := flag.String("dir", "", "media folder full path, all supported files are processed")
+	locationFormat := flag.String("location", "", "export clip locations, gpx or kml")
+	outputPath := flag.String("o", "", "output file path, stdout if not specified")
+	var sets setFlags
+	flag.Var(&sets, "set", "write metadata back to file, field=value, repeatable")
+	dryRun := flag.Bool("dry-run", false, "print changes of -set without writing")
+	recoverFile := flag.Bool("recover", false, "read what is available from clips not finalized by the camera and report the file status")
+	raw := flag.Bool("raw", false, "output tags without decoder, like unknown RTMD tags with set UL, code, length and hex, and every sample of Apple timed metadata")
+	stats := flag.Bool("stats", false, "print bytes read of each file to stderr")
+	resolveFields := flag.Bool("resolve", false, "output DaVinci Resolve fields")
+	provenance := flag.Bool("provenance", false, "output the source and discarded values of each DaVinci Resolve field, implies -resolve")
+	profileName := flag.String("profile", "", "mapping profile of -resolve, built-in "+strings.Join(resolve.BuiltinProfiles(), ", ")+" or JSON/YAML file path, implies -resolve")
+	speedReport := flag.Bool("report", false, "output CSV report of frame rates, slow & quick and time-lapse clips are flagged to conform")
+	timecodes := flag.Bool("timecode", false, "output timecode and camera clock of every frame of Sony clips, with timecode discontinuities")
+	p2Cards := flag.String("p2", "", "comma-separated P2 card folders, clips of a shot recorded across the cards are joined")
 	flag.Parse()
+	profile, err := resolve.FindProfile(*profileName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if profile != nil || *provenance {
+		*resolveFields = true
+	}
+	opts := meta.Options{Recover: *recoverFile, Raw: *raw}
 
-	if *filePath == "" {
+	if *fileURL != "" {
+		m, err := readRemoteFile(*fileURL, opts, *stats)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		output(m, *resolveFields, *provenance, profile)
+		return
+	}
+	if *p2Cards != "" {
+		clips, err := meta.ReadP2Cards(strings.Split(*p2Cards, ",")...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		for _, m := range clips {
+			output(m, *resolveFields, *provenance, profile)
+		}
+		return
+	}
+	if *filePath == "" && *dirPath == "" {
 		fmt.Println("Please input file path!")
 		os.Exit(1)
 	}
-	f, err := internal.GetMediaFile(*filePath)
-	if err != nil {
-		fmt.Println(err)
+	paths := make([]string, 0, 16)
+	if *filePath != "" {
+		paths = append(paths, *filePath)
+	}
+	if *dirPath != "" {
+		dirFiles, err := internal.ListMediaFiles(*dirPath)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		paths = append(paths, dirFiles...)
+	}
+
+	if len(sets) > 0 {
+		if err := writeBack(paths, sets, *outputPath, *dryRun); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 		return
 	}
-	defer f.Close()
-	m, err := meta.Read(f)
-	if err != nil {
-		fmt.Println(err)
+
+	if *speedReport {
+		if err := exportReport(paths, *outputPath, opts, *stats); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *timecodes {
+		printTimecodes(paths)
 		return
 	}
-	consoleOutput(m)
+
+	if *locationFormat != "" {
+		if err := exportLocations(paths, location.Format(strings.ToLower(*locationFormat)), *outputPath, opts, *stats); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	for _, path := range paths {
+		m, err := readMediaFile(path, opts, *stats)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		output(m, *resolveFields, *provenance, profile)
+	}
 
 	fmt.Println("Processing Successfully!")
 }
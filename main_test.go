@@ -0,0 +1,62 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// drMetadataFields layout of DRMetadata as released, plugins built against it reserve exactly this struct
+var drMetadataFields = []string{
+	"IsSupportMedia", "DateRecorded", "CameraType", "CameraManufacturer", "CameraSerial", "CameraId", "CameraNotes",
+	"CameraFormat", "MediaType", "TimeLapseInterval", "CameraFps", "ShutterType", "ShutterAngle", "Shutter", "ISO",
+	"WhitePoint", "WhiteBalanceTint", "CameraFirmware", "LUTUsed", "LensType", "LensNumber", "LensNotes",
+	"CameraApertureType", "CameraAperture", "FocalPoint", "Distance", "Filter", "NDFilter", "CompressionRatio",
+	"CodecBitrate", "SensorAreaCaptured", "PARNotes", "AspectRatioNotes", "GammaNotes", "ColorSpaceNotes",
+}
+
+// cStructFields field declarations like "char *Shutter" of the struct declared in the cgo preamble of main.go
+func cStructFields(t *testing.T, name string) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var preamble string
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Doc != nil &&
+			gen.Specs[0].(*ast.ImportSpec).Path.Value == `"C"` {
+			preamble = gen.Doc.Text()
+		}
+	}
+	body := regexp.MustCompile(`(?s)struct ` + name + `\s*\{(.*?)\};`).FindStringSubmatch(preamble)
+	if body == nil {
+		t.Fatalf("struct %s not found in cgo preamble", name)
+	}
+	var fields []string
+	for _, line := range strings.Split(body[1], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		fields = append(fields, strings.Join(strings.Fields(strings.TrimSuffix(line, ";")), " "))
+	}
+	return fields
+}
+
+// TestDRMetadataLayout DRMetadata is returned by value, new fields go to DRMetadataV2
+func TestDRMetadataLayout(t *testing.T) {
+	want := []string{"bool " + drMetadataFields[0]}
+	for _, name := range drMetadataFields[1:] {
+		want = append(want, "char *"+name)
+	}
+	if got := cStructFields(t, "DRMetadata"); strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("DRMetadata fields changed:\n got %v\nwant %v", got, want)
+	}
+	if got := cStructFields(t, "DRMetadataV2"); len(got) == 0 || got[0] != "struct DRMetadata Base" {
+		t.Errorf("DRMetadataV2 must start with DRMetadata Base, got %v", got)
+	}
+}
@@ -0,0 +1,172 @@
+// Package mediametadata reads camera metadata of MP4/MOV clips, normalizes it to a vendor-neutral model and maps
+// it to DaVinci Resolve fields.
+//
+// This package is the stable API of the module and follows semantic versioning, packages under internal are the
+// implementation and may change at any time. The guarantee covers functions, methods and Options of this package,
+// and the fields of Camera, ResolveMetadata and Profile. Vendor structures like SonyRTMD, PanasonicClipMain and
+// MXFHeader are type aliases of internal schemas that follow the files written by cameras, their fields may be
+// renamed or restructured in minor versions, use Camera for values that must stay stable.
+package mediametadata
+
+import (
+	"encoding/json"
+	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/normalize"
+	"github.com/fukco/media-metadata/internal/output/resolve"
+	"github.com/fukco/media-metadata/internal/output/resolve/xavc"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Version version of the public API
+const Version = "1.0.0"
+
+// Options options of reading metadata
+type Options struct {
+	// Recover reads what is available from clips not finalized by the camera instead of failing,
+	// the result is reported by Metadata.Status
+	Recover bool
+	// Raw keeps tags without decoder, like unknown RTMD local tags, see SonyRTMD().UnknownTags, and reads every
+	// sample of AppleTimedMetadata()
+	Raw bool
+}
+
+func (opts Options) meta() meta.Options {
+	return meta.Options{Recover: opts.Recover, Raw: opts.Raw}
+}
+
+// Metadata metadata of a media file
+type Metadata struct {
+	m *meta.Metadata
+}
+
+// Open reads metadata of the file with default options
+func Open(path string) (*Metadata, error) {
+	return OpenWithOptions(path, Options{})
+}
+
+func OpenWithOptions(path string, opts Options) (*Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := meta.ReadWithOptions(f, opts.meta())
+	if err != nil {
+		return nil, err
+	}
+	// a broken sidecar does not fail the clip
+	_ = m.ReadSidecars(path)
+	m.FileName = filepath.Base(path)
+	m.FilePath = path
+	return &Metadata{m}, nil
+}
+
+func Read(r io.ReadSeeker, opts Options) (*Metadata, error) {
+	m, err := meta.ReadWithOptions(r, opts.meta())
+	if err != nil {
+		return nil, err
+	}
+	return &Metadata{m}, nil
+}
+
+// ReadAt reads metadata from random access reader like remote file, only byte ranges of the needed boxes are fetched
+func ReadAt(r io.ReaderAt, size int64, opts Options) (*Metadata, error) {
+	m, err := meta.ReadAt(r, size, opts.meta())
+	if err != nil {
+		return nil, err
+	}
+	return &Metadata{m}, nil
+}
+
+// FileName file name of metadata read by Open, empty for Read
+func (m *Metadata) FileName() string {
+	return m.m.FileName
+}
+
+func (m *Metadata) FilePath() string {
+	return m.m.FilePath
+}
+
+// Manufacturer name of camera manufacturer like Sony, Unknown if not recognized
+func (m *Metadata) Manufacturer() string {
+	return m.m.Manufacturer.Name()
+}
+
+// Normalize maps metadata of all vendors to a vendor-neutral model, each value records its source
+func (m *Metadata) Normalize() *Camera {
+	return normalize.Normalize(m.m)
+}
+
+// Resolve maps metadata to DaVinci Resolve fields
+func (m *Metadata) Resolve() *ResolveMetadata {
+	return resolve.GetDRMetadataFromMeta(m.m, nil)
+}
+
+// ResolveWithProfile maps metadata to DaVinci Resolve fields with profile, nil is the default mapping
+func (m *Metadata) ResolveWithProfile(profile *Profile) *ResolveMetadata {
+	return resolve.GetDRMetadataFromMeta(m.m, profile)
+}
+
+// MarshalJSON encodes all metadata in the same form as the command line output
+func (m *Metadata) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.m)
+}
+
+// ResolveMetadata DaVinci Resolve fields
+type ResolveMetadata = resolve.DRMetadata
+
+type (
+	// Profile mapping of Resolve fields, see FindProfile
+	Profile      = resolve.Profile
+	FieldMapping = resolve.FieldMapping
+	TemplateData = resolve.TemplateData
+	// Provenance source of a Resolve field and values of other sources replaced by it
+	Provenance  = resolve.Provenance
+	Alternative = resolve.Alternative
+)
+
+// RtmdCollection per frame values of Sony RTMD, consecutive frames with the same value are merged
+type RtmdCollection = xavc.RtmdCollection
+
+type FrameData = xavc.FrameData
+
+type (
+	// SonyTimecodeTrack per frame timecode of a Sony clip, see ReadSonyTimecodes
+	SonyTimecodeTrack = xavc.TimecodeTrack
+)
+
+type (
+	// Camera vendor-neutral camera metadata, nil field means no source has the value
+	Camera   = normalize.Camera
+	Source   = normalize.Source
+	Date     = normalize.Date
+	Timecode = normalize.Timecode
+	Gamma    = normalize.Gamma
+	Gamut    = normalize.Gamut
+	ShotMark = normalize.ShotMark
+	Audio    = normalize.Audio
+	// RecordingFormat file format and encoding like XAVC HS 4K 4:2:2 10bit Long GOP
+	RecordingFormat = normalize.RecordingFormat
+)
+
+// FindProfile returns built-in profile like resolve-18 or loads JSON or YAML profile file, nil for empty name
+func FindProfile(nameOrPath string) (*Profile, error) {
+	return resolve.FindProfile(nameOrPath)
+}
+
+// BuiltinProfiles names of built-in profiles
+func BuiltinProfiles() []string {
+	return resolve.BuiltinProfiles()
+}
+
+// ReadSonyTimecodes reads timecode and camera clock of every frame recorded in Sony RTMD, with timecode discontinuities
+func ReadSonyTimecodes(r io.ReadSeeker) (*SonyTimecodeTrack, error) {
+	return xavc.ReadTimecodes(r)
+}
+
+// ReadRtmdSlice reads Sony RTMD of count frames from start
+func ReadRtmdSlice(r io.ReadSeeker, start int, count int) (*RtmdCollection, error) {
+	return xavc.ReadRtmdSlice(r, start, count)
+}
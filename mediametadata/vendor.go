@@ -0,0 +1,158 @@
+package mediametadata
+
+import (
+	"github.com/fukco/media-metadata/internal/card"
+	"github.com/fukco/media-metadata/internal/common"
+	"github.com/fukco/media-metadata/internal/exif"
+	"github.com/fukco/media-metadata/internal/manufacturer/apple"
+	"github.com/fukco/media-metadata/internal/manufacturer/nikon"
+	"github.com/fukco/media-metadata/internal/manufacturer/panasonic"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/nrtmd"
+	"github.com/fukco/media-metadata/internal/manufacturer/sony/rtmd"
+	"github.com/fukco/media-metadata/internal/meta"
+	"github.com/fukco/media-metadata/internal/mxf"
+	"github.com/fukco/media-metadata/internal/xmp"
+)
+
+// Vendor structures below are aliases of internal schemas and are not covered by the semver guarantee, see the
+// package documentation
+type (
+	// SonyRTMD real time metadata of the first frame
+	SonyRTMD = rtmd.RTMD
+	// SonyNonRealTimeMeta NonRealTimeMeta XML of moov/meta
+	SonyNonRealTimeMeta = nrtmd.NonRealTimeMeta
+	NikonNCTG           = nikon.NCTG
+	// PanasonicClipMain ClipMain XML of Panasonic meta item
+	PanasonicClipMain = panasonic.ClipMain
+	AppleQuickTime    = apple.QuickTimeMeta
+	AppleTimedSample  = apple.TimedSample
+	Exif              = exif.ExifMeta
+	XMP               = xmp.XMP
+	Location          = common.Location
+	// FileStatus completeness of file read with Options.Recover
+	FileStatus = meta.FileStatus
+	// SonyCardClip original, proxy, thumbnail and sidecar of a clip on Sony card
+	SonyCardClip     = card.SonyClip
+	SonyMediaProfile = nrtmd.MediaProfile
+	// SonyRTMDTagDefinition declares an RTMD local tag decoded into SonyRTMD().Tags
+	SonyRTMDTagDefinition = rtmd.TagDefinition
+	SonyRTMDTagType       = rtmd.TagType
+	SonyRTMDSetType       = rtmd.SetType
+	// PanasonicP2Clip video, audio, proxy and clip XML of a clip on P2 card
+	PanasonicP2Clip = card.P2Clip
+	MXFHeader       = mxf.Header
+)
+
+// RegisterSonyRTMDTag adds a decoder of RTMD local tag, register before reading files
+func RegisterSonyRTMDTag(definition SonyRTMDTagDefinition) {
+	rtmd.RegisterTag(definition)
+}
+
+// ReadSonyCard reads clips of MEDIAPRO.XML, root is the card or its PRIVATE/M4ROOT or XDROOT folder
+func ReadSonyCard(root string) ([]*SonyCardClip, error) {
+	return card.ReadSonyCard(root)
+}
+
+// SonyCard files of the clip on Sony card, nil if the clip is not on a card and has no sidecar
+func (m *Metadata) SonyCard() *SonyCardClip {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Sony == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Sony.Card
+}
+
+// SonyRTMD returns nil if not present, same for other vendor accessors
+func (m *Metadata) SonyRTMD() *SonyRTMD {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Sony == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Sony.RTMD
+}
+
+func (m *Metadata) SonyNonRealTimeMeta() *SonyNonRealTimeMeta {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Sony == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Sony.NonRealTimeMeta
+}
+
+func (m *Metadata) NikonNCTG() *NikonNCTG {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Nikon == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Nikon.NCTG
+}
+
+func (m *Metadata) PanasonicClipMain() *PanasonicClipMain {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Panasonic == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Panasonic.ClipMain
+}
+
+// ReadP2Cards reads clips of P2 cards, root is the card or its CONTENTS folder, a shot recorded across the cards is
+// one clip
+func ReadP2Cards(roots ...string) ([]*Metadata, error) {
+	clips, err := meta.ReadP2Cards(roots...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Metadata, len(clips))
+	for i, m := range clips {
+		result[i] = &Metadata{m}
+	}
+	return result, nil
+}
+
+// PanasonicP2 files of the clip on P2 card, nil if not read by ReadP2Cards
+func (m *Metadata) PanasonicP2() *PanasonicP2Clip {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Panasonic == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Panasonic.P2
+}
+
+func (m *Metadata) PanasonicMXF() *MXFHeader {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Panasonic == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Panasonic.MXF
+}
+
+func (m *Metadata) AppleQuickTime() *AppleQuickTime {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Apple == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Apple.QuickTime
+}
+
+// AppleTimedMetadata the first sample of the timed metadata track, every sample if read with Options.Raw
+func (m *Metadata) AppleTimedMetadata() []*AppleTimedSample {
+	if m.m.MakerMeta == nil || m.m.MakerMeta.Apple == nil {
+		return nil
+	}
+	return m.m.MakerMeta.Apple.TimedMetadata
+}
+
+// Exif EXIF of Canon, Fujifilm and Panasonic clips
+func (m *Metadata) Exif() *Exif {
+	return m.m.ExifMeta
+}
+
+func (m *Metadata) XMP() *XMP {
+	return m.m.XMP
+}
+
+func (m *Metadata) Location() *Location {
+	return m.m.Location
+}
+
+// MetaItems QuickTime metadata items of moov/meta keyed by the full key
+func (m *Metadata) MetaItems() map[string]any {
+	return m.m.MetaItemKeyValues
+}
+
+// Status returns nil if not read with Options.Recover
+func (m *Metadata) Status() *FileStatus {
+	return m.m.Status
+}